@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"cli-config-linter/linter"
+)
+
+// baselineFile lists known issues a run does not report, so the linter can
+// be adopted on a repo with existing findings. Entries match on path, rule
+// and message (not line, which drifts) and may carry an until date after
+// which the issue is reported again together with a SUP001 warning.
+type baselineFile struct {
+	Entries []baselineEntry `json:"entries"`
+}
+
+type baselineEntry struct {
+	Path    string `json:"path"`
+	RuleID  string `json:"ruleId,omitempty"`
+	Message string `json:"message"`
+	Until   string `json:"until,omitempty"`
+}
+
+var (
+	baseline       *baselineFile
+	baselinePath   string
+	writeBaseline  bool
+	baselineRecord []baselineEntry
+)
+
+func loadBaseline(path string) (*baselineFile, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && writeBaseline {
+		return &baselineFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var b baselineFile
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	ids := make([]string, len(b.Entries))
+	for i, e := range b.Entries {
+		ids[i] = e.RuleID
+	}
+	warnDeprecatedRules(path, ids)
+	return &b, nil
+}
+
+// applyBaseline filters issues for path through the loaded baseline. With
+// -write-baseline it records every issue instead and reports none.
+func applyBaseline(path string, issues []linter.Issue) []linter.Issue {
+	if baseline == nil {
+		return issues
+	}
+	// Suppressed issues are already accounted for in the file.
+	issues, suppressed := linter.SplitSuppressed(issues)
+	if writeBaseline {
+		for _, issue := range issues {
+			baselineRecord = append(baselineRecord, baselineEntry{Path: path, RuleID: issue.RuleID, Message: issue.Message})
+		}
+		return nil
+	}
+
+	today := time.Now().Format(time.DateOnly)
+	var kept, expired []linter.Issue
+	for _, issue := range issues {
+		entry, ok := baseline.find(path, issue)
+		switch {
+		case !ok:
+			kept = append(kept, issue)
+		case entry.Until != "" && entry.Until < today:
+			kept = append(kept, issue)
+			expired = append(expired, linter.Issue{
+				Line:         issue.Line,
+				Severity:     linter.SeverityWarning,
+				RuleID:       "SUP001",
+				Message:      fmt.Sprintf("baseline entry for %s expired on %s", issue.RuleID, entry.Until),
+				SuggestedFix: "Fix the issue or extend the until date in " + baselinePath,
+			})
+		}
+	}
+	return append(append(kept, expired...), suppressed...)
+}
+
+func (b *baselineFile) find(path string, issue linter.Issue) (baselineEntry, bool) {
+	for _, e := range b.Entries {
+		if e.Path == path && linter.CurrentRuleID(e.RuleID) == issue.RuleID && e.Message == issue.Message {
+			return e, true
+		}
+	}
+	return baselineEntry{}, false
+}
+
+// saveBaseline writes the issues recorded during a -write-baseline run,
+// keeping the until dates of entries that were already there.
+func saveBaseline() error {
+	entries := []baselineEntry{}
+	for _, e := range baselineRecord {
+		if old, ok := baseline.find(e.Path, linter.Issue{RuleID: e.RuleID, Message: e.Message}); ok {
+			e.Until = old.Until
+		}
+		entries = append(entries, e)
+	}
+	if err := writeBaselineFile(baselinePath, &baselineFile{Entries: entries}); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d baseline entries to %s\n", len(entries), baselinePath)
+	return nil
+}
+
+func writeBaselineFile(path string, b *baselineFile) error {
+	if b.Entries == nil {
+		b.Entries = []baselineEntry{}
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// warnDeprecatedRules prints one warning per renamed or removed rule that
+// source refers to.
+func warnDeprecatedRules(source string, ids []string) {
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if c, ok := linter.RuleDeprecation(id); ok {
+			fmt.Fprintf(os.Stderr, "warning: %s: %s\n", source, c.Describe())
+		}
+	}
+}
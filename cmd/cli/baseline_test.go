@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"cli-config-linter/linter"
+)
+
+func TestApplyBaseline(t *testing.T) {
+	defer func() { baseline = nil }()
+	baseline = &baselineFile{Entries: []baselineEntry{
+		{Path: "a.yaml", RuleID: "SET003", Message: "replicas"},
+		{Path: "a.yaml", RuleID: "SET004", Message: "timeout", Until: "2000-01-01"},
+	}}
+
+	issues := []linter.Issue{
+		{Line: 5, RuleID: "SET003", Message: "replicas", Severity: linter.SeverityError},
+		{Line: 4, RuleID: "SET004", Message: "timeout", Severity: linter.SeverityWarning},
+		{Line: 2, RuleID: "META004", Message: "env", Severity: linter.SeverityWarning},
+	}
+	got := applyBaseline("a.yaml", issues)
+	var rules []string
+	for _, issue := range got {
+		rules = append(rules, issue.RuleID)
+	}
+	if len(rules) != 3 || rules[0] != "SET004" || rules[1] != "META004" || rules[2] != "SUP001" {
+		t.Fatalf("expected SET004, META004 and an expiry warning, got %+v", got)
+	}
+
+	if got := applyBaseline("b.yaml", issues); len(got) != 3 {
+		t.Errorf("entries for another path must not match, got %+v", got)
+	}
+}
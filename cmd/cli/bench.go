@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"cli-config-linter/linter"
+)
+
+// benchFile is one document of the synthetic corpus.
+type benchFile struct {
+	name string
+	data []byte
+}
+
+// benchResult is what bench reports, and what -baseline reads back.
+type benchResult struct {
+	Version       string  `json:"version"`
+	Files         int     `json:"files"`
+	Bytes         int64   `json:"bytes"`
+	FilesPerSec   float64 `json:"filesPerSec"`
+	MBPerSec      float64 `json:"mbPerSec"`
+	AllocsPerFile int64   `json:"allocsPerFile"`
+	BytesPerFile  int64   `json:"allocBytesPerFile"`
+}
+
+func runBench(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	files := fs.Int("files", 100, "Number of documents in the synthetic corpus")
+	asJSON := fs.Bool("json", false, "Print the result as JSON (usable as a -baseline)")
+	baseline := fs.String("baseline", "", "Fail if slower or allocating more than this earlier -json result")
+	threshold := fs.Float64("threshold", 0.20, "Allowed regression against -baseline, as a fraction")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s bench [-files n] [-json] [-baseline file [-threshold f]]\n", os.Args[0])
+		fmt.Fprintln(fs.Output(), "Lint a bundled synthetic corpus and report throughput and allocations.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	corpus := benchCorpus(*files)
+	result := benchCorpusRun(corpus)
+
+	if *asJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("configlint %s: %d files, %.1f KiB\n", result.Version, result.Files, float64(result.Bytes)/1024)
+		fmt.Printf("  %10.0f files/s\n  %10.2f MB/s\n  %10d allocs/file\n  %10d B/file\n",
+			result.FilesPerSec, result.MBPerSec, result.AllocsPerFile, result.BytesPerFile)
+	}
+
+	if *baseline == "" {
+		return 0
+	}
+	data, err := os.ReadFile(*baseline)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	var base benchResult
+	if err := json.Unmarshal(data, &base); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", *baseline, err)
+		return 1
+	}
+	if regressions := compareBench(base, result, *threshold); len(regressions) > 0 {
+		for _, r := range regressions {
+			fmt.Fprintln(os.Stderr, "regression:", r)
+		}
+		return 2
+	}
+	return 0
+}
+
+func benchCorpusRun(corpus []benchFile) benchResult {
+	var size int64
+	for _, f := range corpus {
+		size += int64(len(f.data))
+	}
+
+	r := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(size)
+		for i := 0; i < b.N; i++ {
+			for _, f := range corpus {
+				if _, err := linter.LintNamed(f.name, f.data, linter.Options{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	perPass := float64(r.NsPerOp()) / 1e9
+	n := int64(len(corpus))
+	return benchResult{
+		Version:       linter.Version,
+		Files:         len(corpus),
+		Bytes:         size,
+		FilesPerSec:   float64(n) / perPass,
+		MBPerSec:      float64(size) / 1e6 / perPass,
+		AllocsPerFile: r.AllocsPerOp() / n,
+		BytesPerFile:  r.AllocedBytesPerOp() / n,
+	}
+}
+
+// compareBench lists the ways cur is worse than base by more than threshold.
+// Corpus size must match for the numbers to be comparable.
+func compareBench(base, cur benchResult, threshold float64) []string {
+	if base.Files != cur.Files || base.Bytes != cur.Bytes {
+		return []string{fmt.Sprintf("baseline corpus (%d files, %d bytes) differs from this run (%d files, %d bytes)", base.Files, base.Bytes, cur.Files, cur.Bytes)}
+	}
+	var out []string
+	if cur.FilesPerSec < base.FilesPerSec*(1-threshold) {
+		out = append(out, fmt.Sprintf("throughput %.0f files/s, baseline %.0f", cur.FilesPerSec, base.FilesPerSec))
+	}
+	if float64(cur.AllocsPerFile) > float64(base.AllocsPerFile)*(1+threshold) {
+		out = append(out, fmt.Sprintf("%d allocs/file, baseline %d", cur.AllocsPerFile, base.AllocsPerFile))
+	}
+	return out
+}
+
+// benchCorpus generates n documents deterministically: mostly small and
+// medium YAML configs, with JSON, tfvars and the occasional large file.
+func benchCorpus(n int) []benchFile {
+	corpus := make([]benchFile, 0, n)
+	for i := 0; i < n; i++ {
+		switch {
+		case i%25 == 24:
+			corpus = append(corpus, benchFile{fmt.Sprintf("large-%d.yaml", i), benchYAML(i, 2000)})
+		case i%5 == 3:
+			corpus = append(corpus, benchFile{fmt.Sprintf("svc-%d.json", i), benchJSON(i, 20)})
+		case i%5 == 4:
+			corpus = append(corpus, benchFile{fmt.Sprintf("env-%d.tfvars", i), benchTFVars(i)})
+		default:
+			corpus = append(corpus, benchFile{fmt.Sprintf("svc-%d.yaml", i), benchYAML(i, 10+i%40)})
+		}
+	}
+	return corpus
+}
+
+func benchYAML(seed, features int) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# service %d\nmetadata:\n  name: svc-%d\n  env: %s\nsettings:\n  replicas: %d\n", seed, seed, []string{"dev", "staging", "prod", "qa"}[seed%4], seed%3)
+	if seed%2 == 0 {
+		b.WriteString("  timeout: 30\n")
+	}
+	b.WriteString("features:\n")
+	for i := 0; i < features; i++ {
+		fmt.Fprintf(&b, "  - name: feature-%d\n    enabled: %s\n", i, []string{"true", "false", "yes"}[(seed+i)%3])
+	}
+	return []byte(b.String())
+}
+
+func benchJSON(seed, features int) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "{\n  \"metadata\": {\n    \"name\": \"svc-%d\",\n    \"env\": \"prod\"\n  },\n  \"settings\": {\n    \"replicas\": %d,\n    \"timeout\": 30\n  },\n  \"features\": [\n", seed, seed%4)
+	for i := 0; i < features; i++ {
+		sep := ","
+		if i == features-1 {
+			sep = ""
+		}
+		fmt.Fprintf(&b, "    {\n      \"name\": \"feature-%d\",\n      \"enabled\": true\n    }%s\n", i, sep)
+	}
+	b.WriteString("  ]\n}\n")
+	return []byte(b.String())
+}
+
+func benchTFVars(seed int) []byte {
+	return []byte(fmt.Sprintf("region = \"eu-west-%d\"\ninstanceCount = %d\ntags = {\n  team = \"core\"\n}\ndb_password = \"hunter%d\"\n", seed%3, seed, seed))
+}
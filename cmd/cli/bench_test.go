@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"cli-config-linter/linter"
+)
+
+func TestCompareBench(t *testing.T) {
+	base := benchResult{Files: 10, Bytes: 1000, FilesPerSec: 1000, AllocsPerFile: 100}
+
+	within := base
+	within.FilesPerSec, within.AllocsPerFile = 850, 115
+	if r := compareBench(base, within, 0.2); len(r) != 0 {
+		t.Errorf("expected no regressions within threshold, got %v", r)
+	}
+
+	worse := base
+	worse.FilesPerSec, worse.AllocsPerFile = 700, 130
+	if r := compareBench(base, worse, 0.2); len(r) != 2 {
+		t.Errorf("expected throughput and alloc regressions, got %v", r)
+	}
+
+	other := base
+	other.Files = 20
+	if r := compareBench(base, other, 0.2); len(r) != 1 {
+		t.Errorf("expected a corpus mismatch to be reported, got %v", r)
+	}
+}
+
+func TestBenchCorpusLints(t *testing.T) {
+	for _, f := range benchCorpus(25) {
+		if _, err := linter.LintNamed(f.name, f.data, linter.Options{}); err != nil {
+			t.Fatalf("%s: %v", f.name, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"cli-config-linter/linter"
+)
+
+// ciProvider describes how to annotate findings and discover changed files
+// on one CI system.
+type ciProvider struct {
+	name   string
+	format string
+	// base returns the git revision to diff against, or "" when unknown.
+	base func(getenv func(string) string) string
+}
+
+var ciProviders = []struct {
+	env      string
+	provider ciProvider
+}{
+	{"GITHUB_ACTIONS", ciProvider{name: "github", format: "github", base: func(getenv func(string) string) string {
+		if ref := getenv("GITHUB_BASE_REF"); ref != "" {
+			return "origin/" + ref
+		}
+		return ""
+	}}},
+	{"GITLAB_CI", ciProvider{name: "gitlab", format: "gitlab", base: func(getenv func(string) string) string {
+		if sha := getenv("CI_MERGE_REQUEST_DIFF_BASE_SHA"); sha != "" {
+			return sha
+		}
+		if sha := getenv("CI_COMMIT_BEFORE_SHA"); sha != "" && strings.Trim(sha, "0") != "" {
+			return sha
+		}
+		return ""
+	}}},
+	{"BUILDKITE", ciProvider{name: "buildkite", format: "buildkite", base: func(getenv func(string) string) string {
+		if branch := getenv("BUILDKITE_PULL_REQUEST_BASE_BRANCH"); branch != "" {
+			return "origin/" + branch
+		}
+		return ""
+	}}},
+	{"TF_BUILD", ciProvider{name: "azure", format: "azure", base: func(getenv func(string) string) string {
+		if branch := getenv("SYSTEM_PULLREQUEST_TARGETBRANCH"); branch != "" {
+			return "origin/" + strings.TrimPrefix(branch, "refs/heads/")
+		}
+		return ""
+	}}},
+}
+
+// resolveCI maps the -ci flag to a provider. "auto" inspects the
+// environment; any other value names a provider explicitly.
+func resolveCI(mode string, getenv func(string) string) (ciProvider, bool, error) {
+	for _, c := range ciProviders {
+		if mode == c.provider.name || (mode == "auto" && getenv(c.env) != "") {
+			return c.provider, true, nil
+		}
+	}
+	if mode == "auto" {
+		return ciProvider{}, false, nil
+	}
+	return ciProvider{}, false, fmt.Errorf("unknown CI provider %q", mode)
+}
+
+// changedConfigFiles lists lintable files changed since base (HEAD~1 when the
+// base is unknown, e.g. on push builds).
+func changedConfigFiles(base string) ([]string, error) {
+	if base == "" {
+		base = "HEAD~1"
+	} else {
+		base += "...HEAD"
+	}
+	out, err := exec.Command("git", "diff", "--name-only", "--diff-filter=ACMR", base).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s: %w", base, err)
+	}
+
+	var files []string
+	for _, name := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if isConfigFile(name) {
+			files = append(files, name)
+		}
+	}
+	return files, nil
+}
+
+func isConfigFile(name string) bool {
+	if strings.HasSuffix(name, ".tfvars.json") || linter.IsEnvFile(name) {
+		return true
+	}
+	switch filepath.Ext(name) {
+	case ".yaml", ".yml", ".json", ".tfvars", ".ini", ".conf", ".properties", ".xml":
+		return true
+	}
+	return false
+}
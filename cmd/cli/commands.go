@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"cli-config-linter/internal/settings"
+	"cli-config-linter/linter"
+)
+
+// subcommands maps a leading argument to its handler. Anything else runs the
+// classic flag-driven lint over the given files.
+var subcommands = map[string]func(args []string) int{
+	"schema":   runSchema,
+	"bench":    runBench,
+	"rules":    runRules,
+	"config":   runConfig,
+	"suppress": runSuppress,
+	"hash":     runHash,
+}
+
+func runSchema(args []string) int {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	output := fs.String("o", "", "Write the schema to this file instead of stdout")
+	fs.StringVar(&rcPath, "rc", "", "Path to the rc file (default $"+settings.EnvRC+", then .configlintrc.json if present)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s schema export [-rc file] [-o file]\n", os.Args[0])
+		fmt.Fprintln(fs.Output(), "Export the active rules as a JSON Schema document.")
+		fs.PrintDefaults()
+	}
+
+	if len(args) == 0 || args[0] != "export" {
+		fs.Usage()
+		return 1
+	}
+	fs.Parse(args[1:])
+
+	opts, err := loadOptions()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	data, err := json.MarshalIndent(linter.JSONSchema(opts), "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	data = append(data, '\n')
+
+	if *output == "" {
+		os.Stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// effectiveConfig is what config show-effective prints.
+type effectiveConfig struct {
+	*settings.Settings
+	RulePacks      []string `json:"rulePacks,omitempty"`
+	RuleConfigHash string   `json:"ruleConfigHash"`
+}
+
+func runConfig(args []string) int {
+	fs := flag.NewFlagSet("config", flag.ExitOnError)
+	flags := settings.Flags{}
+	fs.StringVar(&flags.RCPath, "rc", "", "Path to the rc file (default $"+settings.EnvRC+", then .configlintrc.json if present)")
+	fs.StringVar(&flags.Profiles, "profile", "", "Comma-separated built-in profiles to enable")
+	fs.BoolVar(&flags.Style, "style", false, "Also report style findings")
+	fs.BoolVar(&flags.Redact, "redact", false, "Replace config values in issue messages with <redacted>")
+	fs.BoolVar(&flags.NoPlugins, "no-plugins", false, "Skip every rule pack and plugin")
+	fs.StringVar(&flags.PluginDir, "plugins", "", "Load the Go (*.so) and WebAssembly (*.wasm) rule plugins in this directory")
+	fs.StringVar(&flags.DisabledRules, "disable-rules", "", "Comma-separated rule IDs not to report")
+	fs.StringVar(&flags.EnabledRules, "enable-rules", "", "Comma-separated rule IDs to report; every other rule is off")
+	fs.StringVar(&flags.Environments, "environments", "", "Comma-separated values metadata.env may have")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s config show-effective [-rc file] [-profile list] [-style] [-redact] [-no-plugins] [-plugins dir] [-disable-rules ids] [-enable-rules ids] [-environments list]\n", os.Args[0])
+		fmt.Fprintln(fs.Output(), "Print the resolved options as JSON, with the layer (default, rc file, rule pack, env or flag) each setting came from.")
+		fs.PrintDefaults()
+	}
+
+	if len(args) == 0 || args[0] != "show-effective" {
+		fs.Usage()
+		return 1
+	}
+	fs.Parse(args[1:])
+
+	s, err := settings.Resolve(context.Background(), flags, os.Getenv)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	out := effectiveConfig{Settings: s, RuleConfigHash: s.Options.Hash()}
+	for _, pack := range s.Packs {
+		out.RulePacks = append(out.RulePacks, pack.Name+"@"+pack.Version)
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	os.Stdout.Write(append(data, '\n'))
+	return 0
+}
+
+// runHash prints linter.Hash of each file, like sha256sum, or of stdin when
+// no file is given.
+func runHash(args []string) int {
+	fs := flag.NewFlagSet("hash", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s hash [file]...\n", os.Args[0])
+		fmt.Fprintln(fs.Output(), "Print a hash of each config's content that ignores comments, key order and formatting, so only real changes change it. Reads stdin when no file is given.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		sum, err := linter.Hash(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "<stdin>: %v\n", err)
+			return 1
+		}
+		fmt.Println(sum)
+		return 0
+	}
+	code := 0
+	for _, path := range fs.Args() {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			var sum string
+			if sum, err = linter.Hash(data); err == nil {
+				fmt.Printf("%s  %s\n", sum, path)
+				continue
+			}
+			err = fmt.Errorf("%s: %w", path, err)
+		}
+		fmt.Fprintln(os.Stderr, err)
+		code = 1
+	}
+	return code
+}
+
+func runRules(args []string) int {
+	if len(args) > 0 && args[0] == "scaffold" {
+		return runScaffold(args[1:])
+	}
+	if len(args) != 1 || args[0] != "changelog" {
+		fmt.Fprintf(os.Stderr, "Usage: %s rules changelog\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Print the renamed and removed rules as JSON.")
+		fmt.Fprintf(os.Stderr, "       %s rules scaffold [-dir linter] <rule-id>\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Generate a rule stub with test fixtures.")
+		return 1
+	}
+	data, err := json.MarshalIndent(linter.RuleChangelog(), "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	os.Stdout.Write(append(data, '\n'))
+	return 0
+}
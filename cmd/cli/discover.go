@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cli-config-linter/linter"
+)
+
+// sniffBytes is how much of each candidate file discovery reads; the
+// sections it looks for sit at the top of a config.
+const sniffBytes = 64 * 1024
+
+// skipDirs are never descended into during discovery.
+var skipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"testdata":     true,
+	"dist":         true,
+	"build":        true,
+}
+
+// discoverConfigs walks roots and returns the files that look like configs
+// this linter understands, writing one line per match with the reason to w.
+func discoverConfigs(roots []string, w io.Writer) ([]string, error) {
+	var files []string
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				name := d.Name()
+				if path != root && (skipDirs[name] || strings.HasPrefix(name, ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() || !isConfigFile(path) {
+				return nil
+			}
+
+			data, err := readPrefix(path, sniffBytes)
+			if err != nil {
+				return err
+			}
+			if ok, reason := linter.Detect(path, data); ok {
+				fmt.Fprintf(w, "found %s: %s\n", path, reason)
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func readPrefix(path string, n int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, n))
+}
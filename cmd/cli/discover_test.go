@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverConfigs(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cli := "metadata:\n  name: svc\nsettings:\n  replicas: 1\n"
+	write("deploy/config.yaml", cli)
+	write("deploy/app.json", "{\n  \"metadata\": {\n    \"name\": \"svc\"\n  },\n  \"settings\": {\n    \"replicas\": 1\n  }\n}\n")
+	write("prod.tfvars", "region = \"eu-west-1\"\n")
+	write("k8s/deployment.yaml", "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n")
+	write("package.json", `{"name": "web"}`)
+	write("node_modules/pkg/config.yaml", cli)
+	write(".github/config.yaml", cli)
+	write("ini/service.ini", "[metadata]\nname = svc\n[settings]\nreplicas = 1\n")
+	write("props/app.properties", "metadata.name=svc\nsettings.replicas=1\n")
+	write("xml/config.xml", "<config>\n  <metadata><name>svc</name></metadata>\n  <settings><replicas>1</replicas></settings>\n</config>\n")
+	write("xml/pom.xml", "<project>\n  <modelVersion>4.0.0</modelVersion>\n</project>\n")
+	write("nginx.conf", "server {\n  listen 80;\n}\n")
+	write("stage.env", "PORT=8080\n")
+
+	var log strings.Builder
+	files, err := discoverConfigs([]string{dir}, &log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range files {
+		files[i], _ = filepath.Rel(dir, files[i])
+	}
+	want := []string{"deploy/app.json", "deploy/config.yaml", "ini/service.ini", "prod.tfvars", "props/app.properties", "stage.env", "xml/config.xml"}
+	if !reflect.DeepEqual(files, want) {
+		t.Fatalf("discovered %v, want %v", files, want)
+	}
+	if !strings.Contains(log.String(), "config.yaml: YAML with metadata, settings; well-known name") {
+		t.Errorf("missing reason in discovery log:\n%s", log.String())
+	}
+	for _, reason := range []string{"service.ini: INI with metadata, settings", "app.properties: Properties with metadata, settings", "config.xml: XML with metadata, settings; well-known name", "stage.env: dotenv file"} {
+		if !strings.Contains(log.String(), reason) {
+			t.Errorf("missing %q in discovery log:\n%s", reason, log.String())
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"cli-config-linter/linter"
+)
+
+// gateReport is what -gate-report writes: the outcome of a run in a form CI
+// gate scripts can read without parsing the full report.
+type gateReport struct {
+	Fatal          bool `json:"fatal"`
+	ErrorCount     int  `json:"errorCount"`
+	WarningCount   int  `json:"warningCount"`
+	BudgetExceeded bool `json:"budgetExceeded"`
+}
+
+// countErrors returns the number of SeverityError issues.
+func countErrors(issues []linter.Issue) int {
+	n := 0
+	for _, issue := range issues {
+		if issue.Severity == linter.SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// writeGateReport writes the gate report for a run ending with exitCode.
+func writeGateReport(path string, exitCode int) error {
+	data, err := json.MarshalIndent(gateReport{
+		Fatal:          exitCode != 0,
+		ErrorCount:     errorCount,
+		WarningCount:   warnings,
+		BudgetExceeded: lintOptions.OverWarningBudget(warnings),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// exitWith finishes the run and exits with the code finish returns.
+func exitWith(code int) {
+	os.Exit(finish(code))
+}
+
+// finish prints the -verbose summary and writes the -gate-report file, if
+// they were asked for, and returns the exit code. A gate report that cannot
+// be written fails an otherwise passing run.
+func finish(code int) int {
+	if lintOptions.Stats != nil {
+		printRuleStats(humanOut, lintOptions.Stats.Snapshot())
+	}
+	if gateReportPath != "" {
+		if err := writeGateReport(gateReportPath, code); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = max(code, 1)
+		}
+	}
+	return code
+}
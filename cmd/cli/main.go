@@ -1,23 +1,127 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
 
+	"cli-config-linter/internal/settings"
 	"cli-config-linter/linter"
+	"cli-config-linter/rcfile"
+	"cli-config-linter/rulepack"
+	"cli-config-linter/source"
+	"cli-config-linter/vault"
 )
 
 var (
 	strict         bool
+	failOn         string
 	fixSuggestions bool
+	consulAddr     string
+	etcdAddr       string
+	kvPrefix       string
+	watch          bool
+	vaultVerify    bool
+	rcPath         string
+	format         string
+	profileList    string
+	tfVariables    string
+	ciMode         string
+	fromStdin      bool
+	applyFixes     bool
+	toStdout       bool
+	style          bool
+	autoDiscover   bool
+	overlayList    string
+	overlayMerge   string
+	templateVars   string
+	consumedKeys   string
+	schemaPath     string
+	cuePath        string
+	cueDefinition  string
+	sectionsPath   string
+	redact         bool
+	noPlugins      bool
+	pluginDir      string
+	gateReportPath string
+	verbose        bool
+	dotenv         bool
+	jsonlStderr    bool
+	fileTimeout    time.Duration
+	disabledRules  string
+	enabledRules   string
+	environments   string
+
+	lintOptions     linter.Options
+	rulePackVersion string
+	// warnings and errorCount count the issues reported in this run.
+	warnings   int
+	errorCount int
+	out        reporter
 )
 
 func init() {
 	flag.BoolVar(&strict, "strict", false, "Treat warnings as fatal")
+	flag.StringVar(&failOn, "fail-on", "", "Least severe issue that fails the run: error (default), warn, info or hint")
 	flag.BoolVar(&fixSuggestions, "fix-suggestions", false, "Show fix suggestions for each issue")
+	flag.BoolVar(&style, "style", false, "Also report style findings (section/key order, needless quoting); never fatal")
+	flag.BoolVar(&noPlugins, "no-plugins", false, "Skip every rule pack, listed in the rc file or discovered under "+rcfile.PackDir+", and every plugin")
+	flag.StringVar(&pluginDir, "plugins", "", "Load the Go (*.so) and WebAssembly (*.wasm) rule plugins in this directory (default $"+settings.EnvPlugins+")")
+	flag.BoolVar(&redact, "redact", false, "Replace config values in issue messages with <redacted>, keeping key paths")
+	flag.BoolVar(&fromStdin, "stdin", false, "Read a single config from stdin")
+	flag.BoolVar(&applyFixes, "fix", false, "Apply safe fixes (files are rewritten in place unless -stdout is set)")
+	flag.BoolVar(&toStdout, "stdout", false, "Write the (fixed) config to stdout and all issues to stderr")
+	flag.BoolVar(&jsonlStderr, "jsonl-stderr", false, "Also write each issue to stderr as a line of JSON; the text report moves to stdout")
+	flag.DurationVar(&fileTimeout, "file-timeout", 0, "Give up on a file that takes longer than this to lint (e.g. 10s); 0 means no limit")
+	flag.StringVar(&consulAddr, "consul", "", "Lint documents from the Consul KV store at this address")
+	flag.StringVar(&etcdAddr, "etcd", "", "Lint documents from the etcd v3 gateway at this address")
+	flag.StringVar(&kvPrefix, "kv-prefix", "", "Key prefix to scan in Consul or etcd")
+	flag.BoolVar(&watch, "watch", false, "Keep watching the KV prefix and re-lint on change")
+	flag.StringVar(&format, "format", "text", "Output format: text, compact, json, sarif, github, gitlab, azure, buildkite")
+	flag.StringVar(&ciMode, "ci", "", "CI integration: auto, github, gitlab, buildkite or azure (picks the annotation format and lints changed files when none are given)")
+	flag.StringVar(&profileList, "profile", "", "Comma-separated built-in profiles to enable ("+strings.Join(linter.Profiles(), ", ")+")")
+	flag.StringVar(&disabledRules, "disable-rules", "", "Comma-separated rule IDs not to report (e.g. SET005,FEAT003)")
+	flag.StringVar(&enabledRules, "enable-rules", "", "Comma-separated rule IDs to report; every other rule is off")
+	flag.StringVar(&environments, "environments", "", "Comma-separated values metadata.env may have")
+	flag.StringVar(&tfVariables, "tf-variables", "", "variables.tf (or a list of names) used to flag undeclared .tfvars values")
+	flag.StringVar(&templateVars, "template-vars", "", "Variables file (KEY=VALUE, key: value or JSON) to check ${var} and {{var}} placeholders against")
+	flag.StringVar(&consumedKeys, "consumed-keys", "", "Manifest of dotted key paths the application reads; flags unread and missing keys")
+	flag.StringVar(&schemaPath, "schema", "", "JSON Schema (JSON or YAML) each config must match; violations are reported as SCHEMA001")
+	flag.StringVar(&sectionsPath, "sections", "", "Section schemas (YAML) declaring the required keys, types, ranges and allowed values of each section")
+	flag.StringVar(&cuePath, "cue", "", "CUE schema each config must satisfy, as cue vet checks it; violations are reported as CUE001")
+	flag.StringVar(&cueDefinition, "cue-definition", "", "Definition in the -cue schema to check against (e.g. #Config); default the whole schema")
+	flag.StringVar(&rcPath, "rc", "", "Path to the rc file (default $"+settings.EnvRC+", then "+rcfile.DefaultName+" if present)")
+	flag.BoolVar(&autoDiscover, "auto", false, "Walk the given directories (default .) and lint every file that looks like a config")
+	flag.StringVar(&overlayList, "overlay", "", "Comma-separated overlays merged onto each config before linting (e.g. prod.yaml)")
+	flag.StringVar(&overlayMerge, "overlay-merge", "", "How overlays are merged: deep (default) or replace")
+	flag.StringVar(&baselinePath, "baseline", "", "Do not report issues listed in this baseline file")
+	flag.BoolVar(&dotenv, "env", false, "Lint the given files as dotenv files (.env, .env.* and *.env are detected by name)")
+	flag.BoolVar(&verbose, "verbose", false, "Print per-check timings and issue counts to stderr after the run")
+	flag.StringVar(&gateReportPath, "gate-report", "", "Write {fatal, errorCount, warningCount, budgetExceeded} as JSON to this file for CI gates")
+	flag.BoolVar(&writeBaseline, "write-baseline", false, "Record every issue into the -baseline file instead of reporting it")
+	flag.BoolVar(&telemetryFlag, "telemetry", false, "Send anonymous usage counts (version, duration, rule hits) to -telemetry-endpoint; off by default")
+	flag.StringVar(&telemetryEndpoint, "telemetry-endpoint", "", "Telemetry endpoint (default $"+telemetryEndpointEnv+")")
+	flag.BoolVar(&vaultVerify, "vault-verify", false, "Check that vault: references exist (uses VAULT_ADDR and VAULT_TOKEN)")
 	flag.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <config-file>...\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s [flags] -consul <addr>|-etcd <addr> -kv-prefix <prefix>\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s -stdin [-fix] [-stdout] < config.yaml\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s lint -auto [dir]...\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s schema export [-o file]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s bench [-json] [-baseline file]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s rules changelog\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s rules scaffold [-dir linter] <rule-id>\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s config show-effective\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s suppress add|remove|list [-rule ids] [-path glob] [-baseline file] [file|dir]...\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s hash [file]...\n", os.Args[0])
 		fmt.Fprintln(flag.CommandLine.Output(), "Lint YAML or JSON configs, reporting structural or semantic issues.")
 		fmt.Fprintln(flag.CommandLine.Output(), "Flags:")
 		flag.PrintDefaults()
@@ -25,50 +129,509 @@ func init() {
 }
 
 func main() {
+	// "lint" is accepted as an explicit name for the default command.
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		os.Args = append(os.Args[:1:1], os.Args[2:]...)
+	}
+	if len(os.Args) > 1 {
+		if cmd, ok := subcommands[os.Args[1]]; ok {
+			os.Exit(cmd(os.Args[2:]))
+		}
+	}
+
 	flag.Parse()
-	if flag.NArg() == 0 {
+
+	opts, err := loadOptions()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	// Compile the options once for every file linted.
+	if lintOptions, err = linter.NewLinter().Options(opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	lintOptions.ReportSuppressed = true
+
+	files := flag.Args()
+	inCI := false
+	if ciMode != "" {
+		provider, ok, err := resolveCI(ciMode, os.Getenv)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if ok {
+			inCI = true
+			if !flagSet("format") {
+				format = provider.format
+			}
+			if len(files) == 0 {
+				files, err = changedConfigFiles(provider.base(os.Getenv))
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(1)
+				}
+				if len(files) == 0 {
+					fmt.Fprintln(os.Stderr, "no changed config files")
+				}
+			}
+		}
+	}
+
+	if autoDiscover {
+		roots := files
+		if len(roots) == 0 {
+			roots = []string{"."}
+		}
+		files, err = discoverConfigs(roots, os.Stderr)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if len(files) == 0 {
+			fmt.Fprintln(os.Stderr, "no config files found")
+			os.Exit(0)
+		}
+	}
+
+	if writeBaseline && baselinePath == "" {
+		fmt.Fprintln(os.Stderr, "-write-baseline requires -baseline")
+		os.Exit(1)
+	}
+	if baselinePath != "" {
+		baseline, err = loadBaseline(baselinePath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	if toStdout {
+		reportOut = os.Stderr
+	}
+	if jsonlStderr {
+		if toStdout {
+			fmt.Fprintln(os.Stderr, "-jsonl-stderr cannot be combined with -stdout")
+			os.Exit(1)
+		}
+		humanOut, jsonlOut = os.Stdout, os.Stderr
+	}
+	out, err = newReporter(format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if jsonlStderr {
+		out = &jsonlReporter{reporter: out}
+	}
+	out = withTelemetry(out)
+
+	if fromStdin {
+		code := lintStdin()
+		sendTelemetry(out)
+		exitWith(code)
+	}
+
+	if consulAddr != "" || etcdAddr != "" {
+		code := lintKV()
+		sendTelemetry(out)
+		exitWith(code)
+	}
+
+	if len(files) == 0 && !inCI {
 		flag.Usage()
 		os.Exit(1)
 	}
 
 	exitCode := 0
-	for _, path := range flag.Args() {
+	for _, path := range files {
 		fatal, err := lintOne(path)
 		if err != nil {
 			exitCode = 2
-			fmt.Fprintln(os.Stderr, err)
+			reportFailure(path, err)
 			continue
 		}
 		if fatal {
 			exitCode = 2
 		}
 	}
+	if overWarningBudget() {
+		exitCode = 2
+	}
 
-	os.Exit(exitCode)
+	reportGenerated()
+	if err := out.finish(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		exitCode = 1
+	}
+	summarizeFailures()
+	if writeBaseline {
+		if err := saveBaseline(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 1
+		}
+	}
+	sendTelemetry(out)
+	exitWith(exitCode)
 }
 
 func lintOne(path string) (fatal bool, err error) {
-	issues, err := linter.LintConfig(path)
+	if overlayList != "" {
+		return lintOverlays(path, strings.Split(overlayList, ","))
+	}
+	if !applyFixes && !toStdout && !vaultVerify && isYAMLOrJSON(path) {
+		return lintStream(path)
+	}
+
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return true, fmt.Errorf("%s: %w", path, err)
 	}
 
-	if len(issues) == 0 {
-		fmt.Fprintf(os.Stdout, "%s: OK\n", path)
-		return false, nil
+	fixed, fatal, err := lintData(path, data)
+	if err != nil {
+		return true, err
+	}
+	if toStdout {
+		_, err = os.Stdout.Write(fixed)
+	} else if applyFixes && !bytes.Equal(fixed, data) {
+		err = os.WriteFile(path, fixed, 0o644)
+	}
+	return fatal, err
+}
+
+// isYAMLOrJSON reports whether path is read as a YAML or JSON config rather
+// than by one of the other frontends (tfvars, INI, properties, dotenv),
+// which neither stream nor fix.
+func isYAMLOrJSON(path string) bool {
+	switch {
+	case dotenv, linter.IsEnvFile(path), strings.Contains(path, ".tfvars"), strings.HasSuffix(path, ".ini"), strings.HasSuffix(path, ".conf"), strings.HasSuffix(path, ".properties"):
+		return false
+	}
+	return true
+}
+
+// lintStream lints a config without loading it into memory, so very large
+// generated files work too.
+func lintStream(path string) (fatal bool, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return true, fmt.Errorf("%s: %w", path, err)
 	}
+	defer f.Close()
 
-	fmt.Fprintf(os.Stderr, "%s:\n", path)
+	ctx, cancel := fileContext()
+	defer cancel()
+	issues, err := linter.LintIncludes(ctx, path, f, linter.LoadInclude, lintOptions)
+	if err != nil {
+		return true, fileError(path, err)
+	}
+	return reportByFile([]string{path}, issues), nil
+}
+
+// reportByFile reports issues under the file each was found in: names in
+// order, then any other file (an include) in first-seen order. It returns
+// whether any of them is fatal.
+func reportByFile(names []string, issues []linter.Issue) (fatal bool) {
 	for _, issue := range issues {
-		fmt.Fprintf(os.Stderr, "  %s:%d [%s] %s\n", path, issue.Line, issue.Severity, issue.Message)
-		if fixSuggestions && issue.SuggestedFix != "" {
-			fmt.Fprintf(os.Stderr, "    Fix suggestion: %s\n", issue.SuggestedFix)
+		if !slices.Contains(names, issue.File) {
+			names = append(names, issue.File)
+		}
+	}
+	for _, name := range names {
+		var own []linter.Issue
+		for _, issue := range issues {
+			if issue.File == name {
+				own = append(own, issue)
+			}
+		}
+		own = applyBaseline(name, own)
+		fatal = emit(name, own) || fatal
+	}
+	return fatal
+}
+
+// lintOverlays lints path merged with overlays, reporting each issue under
+// the file it was found in.
+func lintOverlays(path string, overlays []string) (fatal bool, err error) {
+	var sources []linter.Source
+	for _, name := range append([]string{path}, overlays...) {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return true, fmt.Errorf("%s: %w", name, err)
+		}
+		sources = append(sources, linter.Source{Name: name, Data: data})
+	}
+
+	issues, err := linter.LintOverlays(sources[0], sources[1:], lintOptions)
+	if err != nil {
+		return true, err
+	}
+	return reportByFile(append([]string{path}, overlays...), issues), nil
+}
+
+// lintStdin is the editor pipeline: config in on stdin, (fixed) config out
+// on stdout, issues on stderr. It exits 0 whenever the input could be
+// processed so format-on-save integrations always accept the output.
+func lintStdin() int {
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fixed, fatal, err := lintData("<stdin>", data)
+	if err != nil {
+		reportFailure("<stdin>", err)
+	}
+	reportGenerated()
+	if err := out.finish(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if failures > 0 {
+		return 1
+	}
+	if toStdout {
+		if _, err := os.Stdout.Write(fixed); err != nil {
+			return 1
+		}
+		return 0
+	}
+	if fatal || overWarningBudget() {
+		return 2
+	}
+	return 0
+}
+
+// lintData lints one document and returns it with safe fixes applied when
+// -fix is set (unchanged otherwise).
+func lintData(path string, data []byte) (fixed []byte, fatal bool, err error) {
+	fixed = data
+	var issues []linter.Issue
+	switch {
+	case dotenv:
+		issues, err = linter.LintEnvFile(data, lintOptions)
+	case applyFixes && isYAMLOrJSON(path):
+		fixed, issues, err = linter.Fix(data, lintOptions)
+	case fileTimeout > 0 && isYAMLOrJSON(path):
+		ctx, cancel := fileContext()
+		issues, err = linter.LintContext(ctx, data, lintOptions)
+		cancel()
+	default:
+		issues, err = linter.LintNamed(path, data, lintOptions)
+	}
+	if err != nil {
+		return nil, true, fileError(path, err)
+	}
+
+	if vaultVerify {
+		client := vault.NewClient(os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN"))
+		dangling, err := linter.CheckVaultRefs(context.Background(), fixed, client)
+		if err != nil {
+			return nil, true, fmt.Errorf("%s: %w", path, err)
+		}
+		issues = append(issues, dangling...)
+	}
+
+	issues = applyBaseline(path, issues)
+	return fixed, emit(path, issues), nil
+}
+
+// fileContext bounds the linting of one file by -file-timeout.
+func fileContext() (context.Context, context.CancelFunc) {
+	if fileTimeout <= 0 {
+		return context.Background(), func() {}
+	}
+	return context.WithTimeout(context.Background(), fileTimeout)
+}
+
+// fileError prefixes err with path, naming -file-timeout when it expired.
+func fileError(path string, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%s: not linted within -file-timeout of %s", path, fileTimeout)
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
+
+// emit reports issues for path and returns whether they are fatal. Warnings
+// are tallied against the rc file's warning budget. Issues in generated
+// files are downgraded, or held for reportGenerated.
+func emit(path string, issues []linter.Issue) (fatal bool) {
+	issues, suppressed := linter.SplitSuppressed(issues)
+	defer func() {
+		if s, ok := unwrapReporter(out).(suppressedSection); ok && len(suppressed) > 0 {
+			s.reportSuppressed(path, suppressed)
+		}
+	}()
+	if lintOptions.Generated.Matches(path) {
+		if lintOptions.Generated.Mode == linter.GeneratedSeparate {
+			generatedReports = append(generatedReports, heldReport{path, issues})
+			return false
 		}
+		issues = linter.Downgrade(issues)
+	}
+	out.report(path, issues)
+	warnings += linter.CountWarnings(issues)
+	errorCount += countErrors(issues)
+	return isFatal(issues)
+}
+
+// overWarningBudget reports, once per run, whether the warnings reported so
+// far exceed maxWarnings.
+func overWarningBudget() bool {
+	if !lintOptions.OverWarningBudget(warnings) {
+		return false
+	}
+	fmt.Fprintf(humanOut, "%d warnings exceed the warning budget of %d\n", warnings, *lintOptions.MaxWarnings)
+	return true
+}
 
-		if issue.Severity == linter.SeverityError || (strict && issue.Severity == linter.SeverityWarning) {
-			fatal = true
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
 		}
+	})
+	return set
+}
+
+// loadOptions resolves the rc file and its rule packs, then applies flag
+// overrides. A missing default rc file is not an error.
+func loadOptions() (linter.Options, error) {
+	s, err := settings.Resolve(context.Background(), settings.Flags{RCPath: rcPath, Profiles: profileList, Style: style, Redact: redact, NoPlugins: noPlugins, PluginDir: pluginDir, DisabledRules: disabledRules, EnabledRules: enabledRules, Environments: environments}, os.Getenv)
+	if err != nil {
+		return linter.Options{}, err
 	}
+	rulePackVersion = rulepack.Version(s.Packs)
+	opts := s.Options
 
-	return fatal, nil
+	if verbose {
+		opts.Stats = &linter.RuleStats{}
+	}
+	if overlayMerge != "" {
+		opts.OverlayMerge = overlayMerge
+	}
+	if failOn != "" {
+		opts.FailOn = linter.Severity(failOn)
+	}
+	if tfVariables != "" {
+		data, err := os.ReadFile(tfVariables)
+		if err != nil {
+			return linter.Options{}, err
+		}
+		opts.TerraformVariables = linter.TerraformVariableNames(data)
+	}
+	if consumedKeys != "" {
+		data, err := os.ReadFile(consumedKeys)
+		if err != nil {
+			return linter.Options{}, err
+		}
+		opts.ConsumedKeys, err = linter.ConsumedKeysFrom(data)
+		if err != nil {
+			return linter.Options{}, fmt.Errorf("%s: %w", consumedKeys, err)
+		}
+	}
+	if templateVars != "" {
+		data, err := os.ReadFile(templateVars)
+		if err != nil {
+			return linter.Options{}, err
+		}
+		opts.TemplateVariables, err = linter.TemplateVariablesFrom(data)
+		if err != nil {
+			return linter.Options{}, fmt.Errorf("%s: %w", templateVars, err)
+		}
+	}
+	if schemaPath != "" {
+		data, err := os.ReadFile(schemaPath)
+		if err != nil {
+			return linter.Options{}, err
+		}
+		opts.Schema, err = linter.SchemaFrom(data)
+		if err != nil {
+			return linter.Options{}, fmt.Errorf("%s: %w", schemaPath, err)
+		}
+	}
+	if sectionsPath != "" {
+		data, err := os.ReadFile(sectionsPath)
+		if err != nil {
+			return linter.Options{}, err
+		}
+		sections, err := linter.SectionSchemasFrom(data)
+		if err != nil {
+			return linter.Options{}, fmt.Errorf("%s: %w", sectionsPath, err)
+		}
+		opts = opts.Merge(linter.Options{Sections: sections})
+	}
+	if cuePath != "" {
+		data, err := os.ReadFile(cuePath)
+		if err != nil {
+			return linter.Options{}, err
+		}
+		opts.CUESchema, opts.CUEDefinition = string(data), cueDefinition
+	} else if cueDefinition != "" {
+		opts.CUEDefinition = cueDefinition
+	}
+	return opts, opts.Validate()
+}
+
+// lintKV lints every document under -kv-prefix, reporting each key path as
+// the file name. With -watch it keeps running until interrupted.
+func lintKV() int {
+	var kv source.KV
+	if consulAddr != "" {
+		kv = source.NewConsul(consulAddr, os.Getenv("CONSUL_HTTP_TOKEN"))
+	} else {
+		kv = source.NewEtcd(etcdAddr)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	lintDocs := func(docs []source.Document) int {
+		exitCode := 0
+		for _, doc := range docs {
+			_, fatal, err := lintData(doc.Name, doc.Data)
+			if err != nil {
+				reportFailure(doc.Name, err)
+			}
+			if fatal {
+				exitCode = 2
+			}
+		}
+		return exitCode
+	}
+
+	if !watch {
+		docs, _, err := kv.Fetch(ctx, kvPrefix, 0)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		exitCode := lintDocs(docs)
+		if overWarningBudget() {
+			exitCode = 2
+		}
+		reportGenerated()
+		if err := out.finish(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		summarizeFailures()
+		return exitCode
+	}
+
+	err := source.Watch(ctx, kv, kvPrefix, func(docs []source.Document) error {
+		lintDocs(docs)
+		reportGenerated()
+		return out.finish()
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
 }
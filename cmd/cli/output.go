@@ -0,0 +1,398 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"cli-config-linter/linter"
+)
+
+// reporter renders lint results. report is called once per linted file and
+// finish once after the last file.
+type reporter interface {
+	report(path string, issues []linter.Issue)
+	finish() error
+}
+
+// errorReporter is implemented by reporters that record files which could
+// not be read or parsed, so one bad file shows up in the report instead of
+// only on stderr.
+type errorReporter interface {
+	reportError(path string, err error)
+}
+
+// failures counts the files reportFailure recorded in this run.
+var failures int
+
+// reportFailure records a file that could not be read or linted. Reporters
+// that keep failures in their report (JSON, SARIF) get it there, so a batch
+// with broken files still produces one parseable document; for the others
+// the error goes to humanOut. With -jsonl-stderr it is also a JSON line.
+func reportFailure(path string, err error) {
+	failures++
+	if jsonlOut != nil {
+		writeJSONLine(jsonlOut, jsonlFailure{File: path, Error: err.Error()})
+	}
+	if er, ok := unwrapReporter(out).(errorReporter); ok {
+		er.reportError(path, err)
+		return
+	}
+	fmt.Fprintln(humanOut, err)
+}
+
+// summarizeFailures tells a person watching stderr that failures went into
+// the report rather than past them.
+func summarizeFailures() {
+	if _, ok := unwrapReporter(out).(errorReporter); ok && failures > 0 && jsonlOut == nil {
+		fmt.Fprintf(os.Stderr, "%d files could not be linted; see the %s report\n", failures, format)
+	}
+}
+
+// unwrapReporter returns the reporter at the bottom of a chain of wrappers
+// (telemetry, -jsonl-stderr), which decides how failures and generated
+// files are shown.
+func unwrapReporter(r reporter) reporter {
+	for {
+		w, ok := r.(interface{ unwrap() reporter })
+		if !ok {
+			return r
+		}
+		r = w.unwrap()
+	}
+}
+
+// generatedSection is implemented by reporters that set the issues of
+// generated files apart (see linter.Generated); others report them like any
+// other file.
+type generatedSection interface {
+	startGenerated()
+}
+
+// suppressedSection is implemented by reporters that list the issues inline
+// suppressions cover apart from the reported ones; others leave them out.
+type suppressedSection interface {
+	reportSuppressed(path string, issues []linter.Issue)
+}
+
+// heldReport is a generated file's issues, reported after the other files.
+type heldReport struct {
+	path   string
+	issues []linter.Issue
+}
+
+var generatedReports []heldReport
+
+// reportGenerated reports the held generated files, in their own section.
+func reportGenerated() {
+	if len(generatedReports) == 0 {
+		return
+	}
+	if s, ok := unwrapReporter(out).(generatedSection); ok {
+		s.startGenerated()
+	}
+	for _, r := range generatedReports {
+		out.report(r.path, r.issues)
+	}
+	generatedReports = nil
+}
+
+// reportOut receives machine-readable reports and OK lines. It is switched
+// to stderr when stdout carries the config itself (-stdout).
+var reportOut io.Writer = os.Stdout
+
+// humanOut receives the text report's issues and errors. -jsonl-stderr
+// switches it to stdout, leaving stderr to the JSON lines in jsonlOut.
+var (
+	humanOut io.Writer = os.Stderr
+	jsonlOut io.Writer
+)
+
+// jsonlReporter writes every issue as a JSON line to jsonlOut and passes
+// the report on to the reporter it wraps.
+type jsonlReporter struct {
+	reporter
+}
+
+// jsonlFailure is the JSON line for a file that could not be linted.
+type jsonlFailure struct {
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+func (r *jsonlReporter) report(path string, issues []linter.Issue) {
+	for _, issue := range issues {
+		if issue.File == "" {
+			issue.File = path
+		}
+		writeJSONLine(jsonlOut, issue)
+	}
+	r.reporter.report(path, issues)
+}
+
+func (r *jsonlReporter) unwrap() reporter { return r.reporter }
+
+func writeJSONLine(w io.Writer, v any) {
+	data, _ := json.Marshal(v)
+	w.Write(append(data, '\n'))
+}
+
+func newReporter(format string) (reporter, error) {
+	switch format {
+	case "", "text":
+		return textReporter{}, nil
+	case "json":
+		return &jsonReporter{w: reportOut, run: newRunMetadata(os.Args[1:])}, nil
+	case "sarif":
+		return &sarifReporter{w: reportOut, run: newRunMetadata(os.Args[1:])}, nil
+	case "compact":
+		return compactReporter{w: reportOut}, nil
+	case "github":
+		return githubReporter{w: reportOut}, nil
+	case "gitlab":
+		return &gitlabReporter{w: reportOut}, nil
+	case "azure":
+		return azureReporter{w: reportOut}, nil
+	case "buildkite":
+		return &buildkiteReporter{w: reportOut}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+type textReporter struct{}
+
+func (textReporter) report(path string, issues []linter.Issue) {
+	if len(issues) == 0 {
+		fmt.Fprintf(reportOut, "%s: OK\n", path)
+		return
+	}
+
+	fmt.Fprintf(humanOut, "%s:\n", path)
+	for _, issue := range issues {
+		fmt.Fprintf(humanOut, "  %s:%d [%s] %s\n", path, issue.Line, issue.Severity, issue.Message)
+		if fixSuggestions && issue.SuggestedFix != "" {
+			fmt.Fprintf(humanOut, "    Fix suggestion: %s\n", issue.SuggestedFix)
+		}
+	}
+}
+
+func (textReporter) finish() error { return nil }
+
+func (textReporter) reportSuppressed(path string, issues []linter.Issue) {
+	var ids []string
+	for _, issue := range issues {
+		if !slices.Contains(ids, issue.RuleID) {
+			ids = append(ids, issue.RuleID)
+		}
+	}
+	fmt.Fprintf(humanOut, "  %s: %d suppressed (%s)\n", path, len(issues), strings.Join(ids, ", "))
+}
+
+func (textReporter) startGenerated() {
+	fmt.Fprintln(humanOut, "Generated files (fix the generator, not the file):")
+}
+
+// compactReporter prints one `path:line:col: severity rule message` line per
+// issue, the shape editor problem matchers (VS Code tasks, vim errorformat)
+// expect out of the box.
+type compactReporter struct {
+	w io.Writer
+}
+
+func (r compactReporter) report(path string, issues []linter.Issue) {
+	for _, issue := range issues {
+		col := issue.Column
+		if col == 0 {
+			col = 1
+		}
+		severity := "warning"
+		switch issue.Severity {
+		case linter.SeverityError:
+			severity = "error"
+		case linter.SeverityStyle, linter.SeverityInfo, linter.SeverityHint:
+			severity = "info"
+		}
+		rule := issue.RuleID
+		if rule == "" {
+			rule = "-"
+		}
+		fmt.Fprintf(r.w, "%s:%d:%d: %s %s %s\n", path, issue.Line, col, severity, rule, issue.Message)
+	}
+}
+
+func (compactReporter) finish() error { return nil }
+
+// githubReporter emits GitHub Actions ::error/::warning workflow commands.
+type githubReporter struct {
+	w io.Writer
+}
+
+var (
+	githubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+	githubMessageEscaper  = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+)
+
+func (r githubReporter) report(path string, issues []linter.Issue) {
+	for _, issue := range issues {
+		kind := "warning"
+		switch issue.Severity {
+		case linter.SeverityError:
+			kind = "error"
+		case linter.SeverityStyle, linter.SeverityInfo, linter.SeverityHint:
+			kind = "notice"
+		}
+		props := fmt.Sprintf("file=%s,line=%d", githubPropertyEscaper.Replace(path), issue.Line)
+		if issue.Column > 0 {
+			props += fmt.Sprintf(",col=%d", issue.Column)
+		}
+		if issue.RuleID != "" {
+			props += ",title=" + githubPropertyEscaper.Replace(issue.RuleID)
+		}
+		fmt.Fprintf(r.w, "::%s %s::%s\n", kind, props, githubMessageEscaper.Replace(issue.Message))
+	}
+}
+
+func (githubReporter) finish() error { return nil }
+
+// gitlabReporter writes a GitLab Code Quality report (save it as the
+// codequality artifact).
+type gitlabReporter struct {
+	w       io.Writer
+	entries []gitlabEntry
+}
+
+type gitlabEntry struct {
+	Description string `json:"description"`
+	CheckName   string `json:"check_name"`
+	Fingerprint string `json:"fingerprint"`
+	Severity    string `json:"severity"`
+	Location    struct {
+		Path  string `json:"path"`
+		Lines struct {
+			Begin int `json:"begin"`
+		} `json:"lines"`
+	} `json:"location"`
+}
+
+func (r *gitlabReporter) report(path string, issues []linter.Issue) {
+	for _, issue := range issues {
+		e := gitlabEntry{
+			Description: issue.Message,
+			CheckName:   issue.RuleID,
+			Severity:    "minor",
+		}
+		switch issue.Severity {
+		case linter.SeverityError:
+			e.Severity = "major"
+		case linter.SeverityStyle, linter.SeverityInfo, linter.SeverityHint:
+			e.Severity = "info"
+		}
+		e.Location.Path = path
+		e.Location.Lines.Begin = issue.Line
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%s:%s", path, issue.Line, issue.RuleID, issue.Message)))
+		e.Fingerprint = hex.EncodeToString(sum[:16])
+		r.entries = append(r.entries, e)
+	}
+}
+
+func (r *gitlabReporter) finish() error {
+	defer func() { r.entries = nil }()
+
+	entries := r.entries
+	if entries == nil {
+		entries = []gitlabEntry{}
+	}
+	return json.NewEncoder(r.w).Encode(entries)
+}
+
+// azureReporter emits Azure DevOps ##vso[task.logissue] logging commands.
+// Azure has no issue type below warning, so style, info and hint findings
+// are logged as warnings with their severity in the message.
+type azureReporter struct {
+	w io.Writer
+}
+
+var (
+	azurePropertyEscaper = strings.NewReplacer("%", "%AZP25", "\r", "%0D", "\n", "%0A", ";", "%3B", "]", "%5D")
+	azureMessageEscaper  = strings.NewReplacer("%", "%AZP25", "\r", "%0D", "\n", "%0A")
+)
+
+func (r azureReporter) report(path string, issues []linter.Issue) {
+	for _, issue := range issues {
+		kind, message := "warning", issue.Message
+		switch issue.Severity {
+		case linter.SeverityError:
+			kind = "error"
+		case linter.SeverityStyle, linter.SeverityInfo, linter.SeverityHint:
+			message = fmt.Sprintf("[%s] %s", issue.Severity, message)
+		}
+		if fixSuggestions && issue.SuggestedFix != "" {
+			message += " (fix: " + issue.SuggestedFix + ")"
+		}
+		fmt.Fprintf(r.w, "##vso[task.logissue type=%s;sourcepath=%s;linenumber=%d;]%s\n",
+			kind, azurePropertyEscaper.Replace(path), issue.Line, azureMessageEscaper.Replace(message))
+	}
+}
+
+func (azureReporter) finish() error { return nil }
+
+// buildkiteReporter collects every issue into one Markdown annotation body,
+// meant to be piped into `buildkite-agent annotate --context configlint`.
+type buildkiteReporter struct {
+	w        io.Writer
+	rows     []string
+	errors   int
+	warnings int
+	notes    int
+}
+
+var markdownCellEscaper = strings.NewReplacer("|", `\|`, "\n", " ", "<", "&lt;", ">", "&gt;")
+
+func (r *buildkiteReporter) report(path string, issues []linter.Issue) {
+	for _, issue := range issues {
+		switch issue.Severity {
+		case linter.SeverityError:
+			r.errors++
+		case linter.SeverityStyle, linter.SeverityInfo, linter.SeverityHint:
+			r.notes++
+		default:
+			r.warnings++
+		}
+		row := fmt.Sprintf("| `%s:%d` | %s | %s |", markdownCellEscaper.Replace(path), issue.Line, issue.Severity, markdownCellEscaper.Replace(issue.Message))
+		if fixSuggestions {
+			row += " " + markdownCellEscaper.Replace(issue.SuggestedFix) + " |"
+		}
+		r.rows = append(r.rows, row)
+	}
+}
+
+func (r *buildkiteReporter) finish() error {
+	defer func() { r.rows, r.errors, r.warnings, r.notes = nil, 0, 0, 0 }()
+
+	if len(r.rows) == 0 {
+		_, err := fmt.Fprintln(r.w, "#### Config lint passed")
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "#### Config lint: %d error(s), %d warning(s)", r.errors, r.warnings)
+	if r.notes > 0 {
+		fmt.Fprintf(&b, ", %d note(s)", r.notes)
+	}
+	b.WriteString("\n\n")
+	if fixSuggestions {
+		b.WriteString("| Location | Severity | Message | Fix |\n|---|---|---|---|\n")
+	} else {
+		b.WriteString("| Location | Severity | Message |\n|---|---|---|\n")
+	}
+	for _, row := range r.rows {
+		b.WriteString(row + "\n")
+	}
+	_, err := io.WriteString(r.w, b.String())
+	return err
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cli-config-linter/linter"
+)
+
+func TestAzureReporterEscapes(t *testing.T) {
+	var buf bytes.Buffer
+	r := azureReporter{w: &buf}
+	r.report("conf;dir]/app.yaml", []linter.Issue{
+		{Line: 3, Severity: linter.SeverityError, Message: "100% broken\nreally"},
+		{Line: 5, Severity: linter.SeverityWarning, Message: "soft"},
+		{Line: 7, Severity: linter.SeverityHint, Message: "quote it"},
+	})
+
+	want := "##vso[task.logissue type=error;sourcepath=conf%3Bdir%5D/app.yaml;linenumber=3;]100%AZP25 broken%0Areally\n" +
+		"##vso[task.logissue type=warning;sourcepath=conf%3Bdir%5D/app.yaml;linenumber=5;]soft\n" +
+		"##vso[task.logissue type=warning;sourcepath=conf%3Bdir%5D/app.yaml;linenumber=7;][hint] quote it\n"
+	if buf.String() != want {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestBuildkiteReporterSummary(t *testing.T) {
+	var buf bytes.Buffer
+	r := &buildkiteReporter{w: &buf}
+	r.report("app.yaml", []linter.Issue{
+		{Line: 3, Severity: linter.SeverityError, Message: "a | b"},
+		{Line: 5, Severity: linter.SeverityWarning, Message: "soft"},
+		{Line: 7, Severity: linter.SeverityInfo, Message: "defaults to 30"},
+		{Line: 9, Severity: linter.SeverityHint, Message: "quote it"},
+	})
+	if err := r.finish(); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "#### Config lint: 1 error(s), 1 warning(s), 2 note(s)\n") {
+		t.Errorf("unexpected heading:\n%s", out)
+	}
+	if !strings.Contains(out, "| `app.yaml:3` | error | a \\| b |") {
+		t.Errorf("expected escaped row:\n%s", out)
+	}
+}
+
+func TestCompactReporter(t *testing.T) {
+	var buf bytes.Buffer
+	r := compactReporter{w: &buf}
+	r.report("app.yaml", []linter.Issue{
+		{Line: 3, Column: 3, Severity: linter.SeverityError, RuleID: "SET003", Message: "settings.replicas must be a positive integer"},
+		{Line: 1, Severity: linter.SeverityWarning, Message: "no rule"},
+	})
+
+	want := "app.yaml:3:3: error SET003 settings.replicas must be a positive integer\n" +
+		"app.yaml:1:1: warning - no rule\n"
+	if buf.String() != want {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestJSONReporterIncludesRunMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	r := &jsonReporter{w: &buf, run: newRunMetadata([]string{"-format", "json", "app.yaml"})}
+	r.report("app.yaml", nil)
+	if err := r.finish(); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	var doc struct {
+		Run   runMetadata  `json:"run"`
+		Files []fileReport `json:"files"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if doc.Run.Tool != toolName || doc.Run.Version != linter.Version || !strings.HasPrefix(doc.Run.RuleConfigHash, "sha256:") {
+		t.Errorf("unexpected run metadata: %+v", doc.Run)
+	}
+	if len(doc.Files) != 1 || doc.Files[0].Issues == nil {
+		t.Errorf("expected one file with an empty issue list, got %+v", doc.Files)
+	}
+}
+
+func TestResolveCIAuto(t *testing.T) {
+	env := map[string]string{"BUILDKITE": "true", "BUILDKITE_PULL_REQUEST_BASE_BRANCH": "main"}
+	provider, ok, err := resolveCI("auto", func(k string) string { return env[k] })
+	if err != nil || !ok {
+		t.Fatalf("expected Buildkite to be detected, got ok=%v err=%v", ok, err)
+	}
+	if provider.format != "buildkite" || provider.base(func(k string) string { return env[k] }) != "origin/main" {
+		t.Errorf("unexpected provider %+v", provider)
+	}
+
+	if _, ok, _ := resolveCI("auto", func(string) string { return "" }); ok {
+		t.Error("expected no provider outside CI")
+	}
+}
+
+func TestGithubReporter(t *testing.T) {
+	var buf bytes.Buffer
+	githubReporter{w: &buf}.report("a,b.yaml", []linter.Issue{
+		{Line: 4, Column: 3, Severity: linter.SeverityError, RuleID: "SET003", Message: "settings.replicas must be a positive integer"},
+	})
+
+	want := "::error file=a%2Cb.yaml,line=4,col=3,title=SET003::settings.replicas must be a positive integer\n"
+	if buf.String() != want {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestJSONReporterRecordsFileErrors(t *testing.T) {
+	var buf bytes.Buffer
+	r := &jsonReporter{w: &buf, run: newRunMetadata(nil)}
+	r.reportError("missing.yaml", errors.New("missing.yaml: no such file or directory"))
+	r.report("app.yaml", []linter.Issue{{Line: 1, Severity: linter.SeverityWarning, Message: "w"}})
+	if err := r.finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Files []fileReport `json:"files"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(doc.Files) != 2 {
+		t.Fatalf("expected both files in the report, got %+v", doc.Files)
+	}
+	if f := doc.Files[0]; !f.Fatal || f.Error == "" || f.Issues == nil {
+		t.Errorf("expected a fatal entry with error details, got %+v", f)
+	}
+	if f := doc.Files[1]; f.Error != "" || len(f.Issues) != 1 {
+		t.Errorf("expected the second file linted normally, got %+v", f)
+	}
+}
+
+func TestWriteGateReport(t *testing.T) {
+	defer func(o linter.Options, w, e int) { lintOptions, warnings, errorCount = o, w, e }(lintOptions, warnings, errorCount)
+	budget := 1
+	lintOptions = linter.Options{MaxWarnings: &budget}
+	warnings, errorCount = 2, 0
+
+	path := filepath.Join(t.TempDir(), "gate.json")
+	if err := writeGateReport(path, 2); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got gateReport
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := gateReport{Fatal: true, WarningCount: 2, BudgetExceeded: true}
+	if got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestGeneratedFilesAreReportedApart(t *testing.T) {
+	defer func(opts linter.Options, prev reporter) { lintOptions, out, warnings, errorCount = opts, prev, 0, 0 }(lintOptions, out)
+	var buf bytes.Buffer
+	r := &jsonReporter{w: &buf}
+	out = r
+	issues := []linter.Issue{{Line: 3, Severity: linter.SeverityError, RuleID: "SET003", Message: "replicas"}}
+
+	lintOptions = linter.Options{Generated: linter.Generated{Paths: []string{"gen/**/*.yaml"}}}
+	if emit("gen/a/app.yaml", issues) || r.files[0].Issues[0].Severity != linter.SeverityWarning {
+		t.Fatalf("expected the error downgraded to a warning, got %+v", r.files)
+	}
+
+	lintOptions.Generated.Mode = linter.GeneratedSeparate
+	if emit("gen/app.yaml", issues) || !emit("app.yaml", issues) {
+		t.Fatal("expected only the hand-written file to be fatal")
+	}
+	reportGenerated()
+	if len(r.files) != 3 || r.files[1].Path != "app.yaml" || !r.files[2].Generated || r.files[2].Fatal {
+		t.Errorf("expected the generated file last, in its own section, got %+v", r.files)
+	}
+}
+
+func TestReportFailureGoesIntoTheReport(t *testing.T) {
+	defer func(prev reporter) { out, failures = prev, 0 }(out)
+	var buf bytes.Buffer
+	r := &sarifReporter{w: &buf, run: newRunMetadata(nil)}
+	out = &countingReporter{reporter: r, hits: make(map[string]int)}
+
+	out.report("ok.yaml", nil)
+	reportFailure("bad.yaml", errors.New("bad.yaml: unexpected EOF"))
+	reportFailure("gone.yaml", errors.New("gone.yaml: no such file or directory"))
+	if err := out.finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Runs []struct {
+			Invocations []struct {
+				Successful    bool `json:"executionSuccessful"`
+				Notifications []struct {
+					Message sarifMessage `json:"message"`
+				} `json:"toolExecutionNotifications"`
+			} `json:"invocations"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v", err)
+	}
+	inv := log.Runs[0].Invocations[0]
+	if inv.Successful || len(inv.Notifications) != 2 || inv.Notifications[1].Message.Text != "gone.yaml: no such file or directory" {
+		t.Errorf("expected both failures as notifications, got %+v", inv)
+	}
+	if failures != 2 {
+		t.Errorf("expected 2 failures counted, got %d", failures)
+	}
+}
+
+func TestJSONLReporter(t *testing.T) {
+	defer func(prev reporter, human, jsonl io.Writer) {
+		out, humanOut, jsonlOut, failures = prev, human, jsonl, 0
+	}(out, humanOut, jsonlOut)
+	var human, lines bytes.Buffer
+	humanOut, jsonlOut = &human, &lines
+	out = &jsonlReporter{reporter: textReporter{}}
+
+	out.report("app.yaml", []linter.Issue{
+		{Line: 4, Severity: linter.SeverityError, RuleID: "SET003", Message: "settings.replicas must be a positive integer", Path: "settings.replicas"},
+		{Line: 9, Severity: linter.SeverityWarning, RuleID: "FEAT003", Message: "feature enabled should be true or false"},
+	})
+	reportFailure("gone.yaml", errors.New("gone.yaml: no such file or directory"))
+
+	var got []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(lines.String()), "\n") {
+		var v map[string]any
+		if err := json.Unmarshal([]byte(line), &v); err != nil {
+			t.Fatalf("line %q is not JSON: %v", line, err)
+		}
+		got = append(got, v)
+	}
+	if len(got) != 3 || got[0]["file"] != "app.yaml" || got[0]["ruleId"] != "SET003" || got[1]["line"] != 9.0 || got[2]["error"] == nil {
+		t.Errorf("unexpected JSON lines %v", got)
+	}
+	if !strings.Contains(human.String(), "app.yaml:4 [error] settings.replicas") || !strings.Contains(human.String(), "gone.yaml: no such file") {
+		t.Errorf("expected the text report alongside, got %q", human.String())
+	}
+}
+
+func TestJSONLStderrKeepsHumanOutputOffStderr(t *testing.T) {
+	defer func(prev reporter, human, jsonl io.Writer, opts linter.Options) {
+		out, humanOut, jsonlOut, lintOptions, generatedReports = prev, human, jsonl, opts, nil
+	}(out, humanOut, jsonlOut, lintOptions)
+	var human, lines bytes.Buffer
+	humanOut, jsonlOut = &human, &lines
+	out = &jsonlReporter{reporter: textReporter{}}
+	lintOptions.Stats = &linter.RuleStats{}
+
+	generatedReports = []heldReport{{"gen.yaml", []linter.Issue{{Line: 2, Severity: linter.SeverityWarning, RuleID: "FEAT003", Message: "feature enabled should be true or false"}}}}
+	reportGenerated()
+	if code := finish(0); code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+
+	if !strings.Contains(human.String(), "Generated files") || !strings.Contains(human.String(), "Rule timings:") {
+		t.Errorf("expected the generated section and -verbose summary in the text output, got %q", human.String())
+	}
+	for _, line := range strings.Split(strings.TrimSpace(lines.String()), "\n") {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("expected only JSON lines on the JSON stream, got %q", line)
+		}
+	}
+}
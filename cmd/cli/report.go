@@ -0,0 +1,270 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"cli-config-linter/linter"
+)
+
+const toolName = "configlint"
+
+// runMetadata records what produced a machine-readable report, so audits can
+// tie findings to an exact tool version and rule configuration.
+type runMetadata struct {
+	Tool           string     `json:"tool"`
+	Version        string     `json:"version"`
+	RuleConfigHash string     `json:"ruleConfigHash"`
+	StartedAt      time.Time  `json:"startedAt"`
+	Invocation     invocation `json:"invocation"`
+	// EngineVersion and RulePackVersion change whenever stored results
+	// need to be recomputed.
+	EngineVersion   string `json:"engineVersion"`
+	RulePackVersion string `json:"rulePackVersion"`
+}
+
+type invocation struct {
+	Args   []string `json:"args"`
+	Strict bool     `json:"strict"`
+	Format string   `json:"format"`
+	RC     string   `json:"rc,omitempty"`
+}
+
+func newRunMetadata(args []string) runMetadata {
+	return runMetadata{
+		Tool:           toolName,
+		Version:        linter.Version,
+		RuleConfigHash: lintOptions.Hash(),
+		StartedAt:      time.Now().UTC(),
+		Invocation: invocation{
+			Args:   args,
+			Strict: strict,
+			Format: format,
+			RC:     rcPath,
+		},
+		EngineVersion:   linter.Version,
+		RulePackVersion: rulePackVersion,
+	}
+}
+
+type fileReport struct {
+	Path   string         `json:"path"`
+	Fatal  bool           `json:"fatal"`
+	Issues []linter.Issue `json:"issues"`
+	// Error is set when the file failed to load or parse; Issues then holds
+	// whatever was found before the failure.
+	Error string `json:"error,omitempty"`
+	// Generated is set for generated files reported in their own section;
+	// their issues never make the run fail.
+	Generated bool `json:"generated,omitempty"`
+	// Suppressed lists the issues inline suppressions cover; they never
+	// make the run fail.
+	Suppressed []linter.Issue `json:"suppressed,omitempty"`
+}
+
+// jsonReporter writes a single JSON document with run metadata and every
+// file's issues.
+type jsonReporter struct {
+	w         io.Writer
+	run       runMetadata
+	files     []fileReport
+	generated bool
+}
+
+func (r *jsonReporter) report(path string, issues []linter.Issue) {
+	if issues == nil {
+		issues = []linter.Issue{}
+	}
+	r.files = append(r.files, fileReport{Path: path, Fatal: isFatal(issues) && !r.generated, Issues: issues, Generated: r.generated})
+}
+
+func (r *jsonReporter) startGenerated() { r.generated = true }
+
+// reportSuppressed follows report for the same file.
+func (r *jsonReporter) reportSuppressed(path string, issues []linter.Issue) {
+	if n := len(r.files); n > 0 && r.files[n-1].Path == path {
+		r.files[n-1].Suppressed = issues
+	}
+}
+
+func (r *jsonReporter) reportError(path string, err error) {
+	if n := len(r.files); n > 0 && r.files[n-1].Path == path {
+		r.files[n-1].Fatal, r.files[n-1].Error = true, err.Error()
+		return
+	}
+	r.files = append(r.files, fileReport{Path: path, Fatal: true, Issues: []linter.Issue{}, Error: err.Error()})
+}
+
+func (r *jsonReporter) finish() error {
+	defer func() { r.files, r.generated = nil, false }()
+
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Run   runMetadata  `json:"run"`
+		Files []fileReport `json:"files"`
+	}{r.run, r.files})
+}
+
+// sarifReporter writes a SARIF 2.1.0 log for code-scanning dashboards.
+type sarifReporter struct {
+	w       io.Writer
+	run     runMetadata
+	results []sarifResult
+	rules   map[string]bool
+	// failures become toolExecutionNotifications on the invocation.
+	failures []any
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+	// Suppressions marks results suppressed in the source, which code
+	// scanning dashboards show as dismissed.
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind string `json:"kind"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region struct {
+			StartLine   int `json:"startLine"`
+			StartColumn int `json:"startColumn,omitempty"`
+		} `json:"region"`
+	} `json:"physicalLocation"`
+}
+
+func (r *sarifReporter) report(path string, issues []linter.Issue) {
+	if r.rules == nil {
+		r.rules = make(map[string]bool)
+	}
+	for _, issue := range issues {
+		level := "warning"
+		switch issue.Severity {
+		case linter.SeverityError:
+			level = "error"
+		case linter.SeverityStyle, linter.SeverityInfo, linter.SeverityHint:
+			level = "note"
+		}
+		var loc sarifLocation
+		loc.PhysicalLocation.ArtifactLocation.URI = path
+		loc.PhysicalLocation.Region.StartLine = issue.Line
+		loc.PhysicalLocation.Region.StartColumn = issue.Column
+
+		r.results = append(r.results, sarifResult{
+			RuleID:    issue.RuleID,
+			Level:     level,
+			Message:   sarifMessage{Text: issue.Message},
+			Locations: []sarifLocation{loc},
+		})
+		if issue.RuleID != "" {
+			r.rules[issue.RuleID] = true
+		}
+	}
+}
+
+func (r *sarifReporter) reportSuppressed(path string, issues []linter.Issue) {
+	n := len(r.results)
+	r.report(path, issues)
+	for i := n; i < len(r.results); i++ {
+		r.results[i].Suppressions = []sarifSuppression{{Kind: "inSource"}}
+	}
+}
+
+func (r *sarifReporter) reportError(path string, err error) {
+	var loc sarifLocation
+	loc.PhysicalLocation.ArtifactLocation.URI = path
+	r.failures = append(r.failures, map[string]any{
+		"level":     "error",
+		"message":   sarifMessage{Text: err.Error()},
+		"locations": []sarifLocation{loc},
+	})
+}
+
+func (r *sarifReporter) finish() error {
+	defer func() { r.results, r.rules, r.failures = nil, nil, nil }()
+
+	type rule struct {
+		ID string `json:"id"`
+	}
+	rules := []rule{}
+	for id := range r.rules {
+		rules = append(rules, rule{ID: id})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	results := r.results
+	if results == nil {
+		results = []sarifResult{}
+	}
+	invocation := map[string]any{
+		"commandLine":         strings.Join(append([]string{os.Args[0]}, r.run.Invocation.Args...), " "),
+		"arguments":           r.run.Invocation.Args,
+		"startTimeUtc":        r.run.StartedAt,
+		"executionSuccessful": len(r.failures) == 0,
+	}
+	if len(r.failures) > 0 {
+		invocation["toolExecutionNotifications"] = r.failures
+	}
+
+	log := map[string]any{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []any{map[string]any{
+			"tool": map[string]any{"driver": map[string]any{
+				"name":    r.run.Tool,
+				"version": r.run.Version,
+				"rules":   rules,
+			}},
+			"invocations": []any{invocation},
+			"properties":  map[string]any{"configlint": r.run},
+			"results":     results,
+		}},
+	}
+
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+// isFatal reports whether issues fail the run: any issue at least as severe
+// as -fail-on (or the rc file's failOn), with -strict lowering it to warn.
+func isFatal(issues []linter.Issue) bool {
+	return linter.Fails(issues, lintOptions.FailThreshold(strict))
+}
+
+// printRuleStats writes the -verbose summary: each check's total time, how
+// often it ran and the issues it reported by rule ID, slowest first.
+func printRuleStats(w io.Writer, stats []linter.RuleStat) {
+	fmt.Fprintln(w, "Rule timings:")
+	for _, st := range stats {
+		ids := make([]string, 0, len(st.Issues))
+		for id := range st.Issues {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		hits := make([]string, len(ids))
+		for i, id := range ids {
+			hits[i] = fmt.Sprintf("%s×%d", id, st.Issues[id])
+		}
+		line := fmt.Sprintf("  %-14s %10s  %4d runs  %s", st.Check, st.Duration.Round(time.Microsecond), st.Runs, strings.Join(hits, " "))
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"cli-config-linter/linter"
+)
+
+// ruleIDPattern is the shape of rule IDs: an upper-case family and a
+// three-digit number, like SET002.
+var ruleIDPattern = regexp.MustCompile(`^[A-Z]+[0-9]{3}$`)
+
+// scaffoldFiles are the files `rules scaffold` writes, relative to the
+// linter package directory. Names and contents are templates over the rule.
+var scaffoldFiles = []struct{ name, text string }{
+	{"{{.File}}.go", `package linter
+
+import "fmt"
+
+// validate{{.ID}} reports {{.ID}} issues. Add it to rulesFor and to
+// Document.Issues once it checks something real.
+func validate{{.ID}}(cfg parsedConfig, _ Options, issues *[]Issue) {
+	// Example check: settings with an empty value. Replace it with the rule.
+	for _, v := range fieldsByLine("settings", cfg.Settings) {
+		if v.value.Value != "" {
+			continue
+		}
+		*issues = append(*issues, Issue{
+			Line:         v.value.Line,
+			Column:       v.value.Col,
+			Severity:     SeverityWarning,
+			RuleID:       "{{.ID}}",
+			Message:      fmt.Sprintf("%s is empty", v.field),
+			SuggestedFix: "Set a value or remove the key",
+		})
+	}
+}
+`},
+	{"{{.File}}_test.go", `package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test{{.ID}}(t *testing.T) {
+	tests := []struct {
+		file string
+		want []int // lines reported
+	}{
+		{"valid.yaml", nil},
+		{"invalid.yaml", []int{6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", "{{.File}}", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := parseConfig(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var issues []Issue
+			validate{{.ID}}(cfg, Options{}, &issues)
+			var got []int
+			for _, issue := range issues {
+				if issue.RuleID != "{{.ID}}" {
+					t.Errorf("unexpected rule %s", issue.RuleID)
+				}
+				got = append(got, issue.Line)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected issues on lines %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("expected issues on lines %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+`},
+	{"testdata/{{.File}}/valid.yaml", `metadata:
+  name: example
+  env: dev
+settings:
+  replicas: 1
+  timeout: 30
+`},
+	{"testdata/{{.File}}/invalid.yaml", `metadata:
+  name: example
+  env: dev
+settings:
+  replicas: 1
+  timeout: ""
+`},
+}
+
+// scaffoldRule writes a rule stub, its table-driven test and sample configs
+// for id into dir, returning the paths written. Existing files are never
+// overwritten.
+func scaffoldRule(dir, id string) ([]string, error) {
+	if !ruleIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("rule ID %q must be upper-case letters and three digits, like SET006", id)
+	}
+	if c, ok := linter.RuleDeprecation(id); ok {
+		return nil, fmt.Errorf("rule ID %s cannot be reused: %s", id, c.Describe())
+	}
+	data := struct{ ID, File string }{id, strings.ToLower(id)}
+
+	type file struct {
+		path string
+		body []byte
+	}
+	var files []file
+	for _, f := range scaffoldFiles {
+		var name, body bytes.Buffer
+		template.Must(template.New("name").Parse(f.name)).Execute(&name, data)
+		template.Must(template.New("body").Parse(f.text)).Execute(&body, data)
+		path := filepath.Join(dir, filepath.FromSlash(name.String()))
+		if _, err := os.Stat(path); err == nil {
+			return nil, fmt.Errorf("%s already exists", path)
+		}
+		files = append(files, file{path, body.Bytes()})
+	}
+
+	var written []string
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+			return written, err
+		}
+		if err := os.WriteFile(f.path, f.body, 0o644); err != nil {
+			return written, err
+		}
+		written = append(written, f.path)
+	}
+	return written, nil
+}
+
+func runScaffold(args []string) int {
+	fs := flag.NewFlagSet("rules scaffold", flag.ExitOnError)
+	dir := fs.String("dir", "linter", "Directory of the linter package")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s rules scaffold [-dir linter] <rule-id>\n", os.Args[0])
+		fmt.Fprintln(fs.Output(), "Generate a rule stub, a table-driven test and valid/invalid sample configs.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 1
+	}
+
+	written, err := scaffoldRule(*dir, fs.Arg(0))
+	for _, path := range written {
+		fmt.Println(path)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
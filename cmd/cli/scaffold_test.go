@@ -0,0 +1,45 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScaffoldRule(t *testing.T) {
+	dir := t.TempDir()
+	written, err := scaffoldRule(dir, "NET001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 4 {
+		t.Fatalf("expected 4 files, got %v", written)
+	}
+	for _, path := range written {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasSuffix(path, ".go") {
+			if _, err := parser.ParseFile(token.NewFileSet(), path, data, 0); err != nil {
+				t.Errorf("generated %s does not parse: %v", path, err)
+			}
+			if !strings.Contains(string(data), "validateNET001") {
+				t.Errorf("expected %s to use validateNET001", path)
+			}
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "testdata", "net001", "invalid.yaml")); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := scaffoldRule(dir, "NET001"); err == nil {
+		t.Error("expected existing files not to be overwritten")
+	}
+	if _, err := scaffoldRule(dir, "net1"); err == nil {
+		t.Error("expected a malformed rule ID to be rejected")
+	}
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"cli-config-linter/internal/settings"
+	"cli-config-linter/linter"
+)
+
+// suppressFilter selects what suppress add, remove and list act on.
+type suppressFilter struct {
+	rules []string // current rule IDs; empty matches every rule
+	glob  string   // slash-separated path pattern; empty matches every path
+}
+
+func (f suppressFilter) rule(id string) bool {
+	return len(f.rules) == 0 || slices.Contains(f.rules, linter.CurrentRuleID(id))
+}
+
+func (f suppressFilter) path(name string) bool {
+	return f.glob == "" || linter.MatchPath(f.glob, filepath.ToSlash(name))
+}
+
+func runSuppress(args []string) int {
+	fs := flag.NewFlagSet("suppress", flag.ExitOnError)
+	rules := fs.String("rule", "", "Comma-separated rule IDs to act on (default every rule)")
+	glob := fs.String("path", "", "Only act on files matching this glob; ** matches any number of directories")
+	file := fs.String("baseline", "", "Manage entries in this baseline file instead of inline comments")
+	until := fs.String("until", "", "Expiry date (YYYY-MM-DD) for added suppressions")
+	dryRun := fs.Bool("dry-run", false, "Report what would change without writing")
+	fs.StringVar(&rcPath, "rc", "", "Path to the rc file (default $"+settings.EnvRC+", then .configlintrc.json if present)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s suppress add|remove|list [-rule ids] [-path glob] [-baseline file] [-until date] [-dry-run] [file|dir]...\n", os.Args[0])
+		fmt.Fprintln(fs.Output(), "Add suppressions for the current issues, remove suppressions, or list them, as inline comments or baseline entries. Directories are searched like -auto (default .).")
+		fs.PrintDefaults()
+	}
+
+	if len(args) == 0 || (args[0] != "add" && args[0] != "remove" && args[0] != "list") {
+		fs.Usage()
+		return 1
+	}
+	action := args[0]
+	fs.Parse(args[1:])
+	if *until != "" {
+		if _, err := time.Parse(time.DateOnly, *until); err != nil {
+			fmt.Fprintf(os.Stderr, "-until: write the date as YYYY-MM-DD\n")
+			return 1
+		}
+	}
+
+	filter := suppressFilter{glob: *glob}
+	for _, id := range strings.Split(*rules, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			filter.rules = append(filter.rules, linter.CurrentRuleID(id))
+		}
+	}
+	var files []string
+	if action == "add" || *file == "" || fs.NArg() > 0 {
+		var err error
+		if files, err = suppressTargets(fs.Args(), filter); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	var err error
+	switch {
+	case *file != "":
+		err = suppressBaseline(action, *file, files, filter, *until, *dryRun, os.Stdout)
+	default:
+		err = suppressInline(action, files, filter, *until, *dryRun, os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// suppressTargets expands args into the config files that match filter.
+func suppressTargets(args []string, filter suppressFilter) ([]string, error) {
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		found := []string{arg}
+		if info.IsDir() {
+			if found, err = discoverConfigs([]string{arg}, io.Discard); err != nil {
+				return nil, err
+			}
+		}
+		for _, name := range found {
+			if filter.path(name) {
+				files = append(files, name)
+			}
+		}
+	}
+	return files, nil
+}
+
+// lintForSuppress lints path and keeps the issues filter selects.
+func lintForSuppress(path string, data []byte, opts linter.Options, filter suppressFilter) ([]linter.Issue, error) {
+	issues, err := linter.LintNamed(path, data, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var out []linter.Issue
+	for _, issue := range issues {
+		if filter.rule(issue.RuleID) {
+			out = append(out, issue)
+		}
+	}
+	return out, nil
+}
+
+// suppressInline manages configlint-disable-line comments in files.
+func suppressInline(action string, files []string, filter suppressFilter, until string, dryRun bool, w io.Writer) error {
+	var opts linter.Options
+	if action == "add" {
+		var err error
+		if opts, err = loadOptions(); err != nil {
+			return err
+		}
+	}
+	count, changed := 0, 0
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		out := data
+		switch action {
+		case "list":
+			for _, s := range linter.Suppressions(data) {
+				if len(s.Rules) == 0 && len(filter.rules) == 0 || slices.ContainsFunc(s.Rules, filter.rule) {
+					fmt.Fprintf(w, "%s:%d: %s%s\n", path, s.Line, ruleList(s.Rules), untilSuffix(s.Until))
+					count++
+				}
+			}
+		case "add":
+			issues, err := lintForSuppress(path, data, opts, filter)
+			if err != nil {
+				return err
+			}
+			var added, skipped []linter.Issue
+			out, added, skipped = linter.AddSuppressions(data, issues, until)
+			for _, issue := range added {
+				fmt.Fprintf(w, "%s:%d: suppressed %s: %s\n", path, issue.Line, issue.RuleID, issue.Message)
+			}
+			for _, issue := range skipped {
+				fmt.Fprintf(w, "%s:%d: cannot suppress %s inline; use -baseline\n", path, issue.Line, issue.RuleID)
+			}
+			count += len(added)
+		case "remove":
+			var removed []linter.Suppression
+			out, removed = linter.RemoveSuppressions(data, filter.rules)
+			for _, s := range removed {
+				fmt.Fprintf(w, "%s:%d: removed suppression of %s\n", path, s.Comment, ruleList(s.Rules))
+			}
+			count += len(removed)
+		}
+		if string(out) == string(data) {
+			continue
+		}
+		changed++
+		if !dryRun {
+			if err := os.WriteFile(path, out, 0o644); err != nil {
+				return err
+			}
+		}
+	}
+	fmt.Fprintln(os.Stderr, suppressSummary(action, count, changed, dryRun && changed > 0))
+	return nil
+}
+
+// suppressBaseline manages the entries of a baseline file. Entries are
+// matched to files by path, so add lints files and list and remove only
+// filter by them when given.
+func suppressBaseline(action, file string, files []string, filter suppressFilter, until string, dryRun bool, w io.Writer) error {
+	b := &baselineFile{}
+	data, err := os.ReadFile(file)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, b); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	case !os.IsNotExist(err) || action != "add":
+		return err
+	}
+	selected := func(e baselineEntry) bool {
+		return filter.rule(e.RuleID) && filter.path(e.Path) && (files == nil || slices.Contains(files, e.Path))
+	}
+
+	count := 0
+	switch action {
+	case "list":
+		for _, e := range b.Entries {
+			if selected(e) {
+				fmt.Fprintf(w, "%s: %s: %s%s\n", e.Path, e.RuleID, e.Message, untilSuffix(e.Until))
+				count++
+			}
+		}
+		fmt.Fprintln(os.Stderr, suppressSummary(action, count, -1, false))
+		return nil
+	case "add":
+		opts, err := loadOptions()
+		if err != nil {
+			return err
+		}
+		for _, path := range files {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			issues, err := lintForSuppress(path, data, opts, filter)
+			if err != nil {
+				return err
+			}
+			for _, issue := range issues {
+				if _, ok := b.find(path, issue); ok {
+					continue
+				}
+				b.Entries = append(b.Entries, baselineEntry{Path: path, RuleID: issue.RuleID, Message: issue.Message, Until: until})
+				fmt.Fprintf(w, "%s:%d: suppressed %s: %s\n", path, issue.Line, issue.RuleID, issue.Message)
+				count++
+			}
+		}
+	case "remove":
+		kept := b.Entries[:0]
+		for _, e := range b.Entries {
+			if !selected(e) {
+				kept = append(kept, e)
+				continue
+			}
+			fmt.Fprintf(w, "%s: removed %s: %s\n", e.Path, e.RuleID, e.Message)
+			count++
+		}
+		b.Entries = kept
+	}
+	if count > 0 && !dryRun {
+		if err := writeBaselineFile(file, b); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(os.Stderr, suppressSummary(action, count, -1, dryRun && count > 0))
+	return nil
+}
+
+// suppressSummary is the last line of a suppress run; files < 0 leaves out
+// the file count.
+func suppressSummary(action string, count, files int, dryRun bool) string {
+	var s string
+	switch action {
+	case "list":
+		return fmt.Sprintf("%d suppressions", count)
+	case "add":
+		s = fmt.Sprintf("added %d suppressions", count)
+	default:
+		s = fmt.Sprintf("removed %d suppressions", count)
+	}
+	if files >= 0 {
+		s += fmt.Sprintf(" in %d files", files)
+	}
+	if dryRun {
+		s += " (dry run, nothing written)"
+	}
+	return s
+}
+
+func ruleList(rules []string) string {
+	if len(rules) == 0 {
+		return "all rules"
+	}
+	return strings.Join(rules, ",")
+}
+
+func untilSuffix(until string) string {
+	if until == "" {
+		return ""
+	}
+	return " (until " + until + ")"
+}
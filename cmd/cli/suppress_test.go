@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSuppressBaseline(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "a.json")
+	if err := os.WriteFile(config, []byte(`{"metadata": {"name": "a", "env": "qa"}, "settings": {"replicas": 0, "timeout": 5}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "baseline.json")
+	var out strings.Builder
+
+	if err := suppressBaseline("add", file, []string{config}, suppressFilter{rules: []string{"SET003"}}, "", false, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "suppressed SET003") || strings.Contains(out.String(), "META004") {
+		t.Fatalf("expected only SET003 to be added, got %q", out.String())
+	}
+	b, err := loadBaseline(file)
+	if err != nil || len(b.Entries) != 1 {
+		t.Fatalf("expected one entry, got %+v, %v", b, err)
+	}
+
+	out.Reset()
+	if err := suppressBaseline("remove", file, nil, suppressFilter{glob: "**/*.json"}, "", true, &out); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := loadBaseline(file); len(b.Entries) != 1 || !strings.Contains(out.String(), "removed SET003") {
+		t.Errorf("expected a dry run to report the entry and keep it, got %q", out.String())
+	}
+}
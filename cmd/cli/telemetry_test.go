@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cli-config-linter/linter"
+)
+
+func TestTelemetryIsOptIn(t *testing.T) {
+	env := map[string]string{}
+	getenv := func(k string) string { return env[k] }
+	if telemetryEnabled(getenv) {
+		t.Fatal("telemetry must be off by default")
+	}
+	env[telemetryEnv] = "1"
+	if !telemetryEnabled(getenv) {
+		t.Fatal("expected the environment variable to enable telemetry")
+	}
+	env["DO_NOT_TRACK"] = "1"
+	if telemetryEnabled(getenv) {
+		t.Fatal("expected DO_NOT_TRACK to win over the environment variable")
+	}
+}
+
+func TestTelemetrySendsCountsOnly(t *testing.T) {
+	var got telemetryReport
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	prev := telemetryEndpoint
+	telemetryEndpoint = srv.URL
+	defer func() { telemetryEndpoint = prev }()
+
+	counts := &countingReporter{reporter: compactReporter{w: io.Discard}, hits: make(map[string]int)}
+	counts.report("secret/path.yaml", []linter.Issue{{RuleID: "SET003", Message: "x"}, {RuleID: "SET003"}, {RuleID: "META004"}})
+	sendTelemetry(counts)
+
+	if got.Files != 1 || got.RuleHits["SET003"] != 2 || got.RuleHits["META004"] != 1 || got.Version != linter.Version {
+		t.Errorf("unexpected report %+v", got)
+	}
+}
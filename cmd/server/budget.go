@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultInFlightBytes caps the request bodies being linted at once. Linting
+// holds several times the body size in memory, so this keeps a small pod
+// well clear of its limit.
+const defaultInFlightBytes = 64 << 20
+
+// budgetQueueWait is how long a request waits for budget before it is turned
+// away with 503.
+const budgetQueueWait = 3 * time.Second
+
+// byteBudget is a weighted semaphore over request body bytes.
+type byteBudget struct {
+	mu       sync.Mutex
+	limit    int64
+	used     int64
+	released chan struct{}
+}
+
+func newByteBudget(limit int64) *byteBudget {
+	return &byteBudget{limit: limit, released: make(chan struct{})}
+}
+
+// acquire blocks until n bytes fit in the budget or ctx is done.
+func (b *byteBudget) acquire(ctx context.Context, n int64) error {
+	for {
+		b.mu.Lock()
+		if b.used+n <= b.limit {
+			b.used += n
+			b.mu.Unlock()
+			return nil
+		}
+		wait := b.released
+		b.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (b *byteBudget) release(n int64) {
+	b.mu.Lock()
+	b.used -= n
+	close(b.released)
+	b.released = make(chan struct{})
+	b.mu.Unlock()
+}
+
+// withByteBudget charges each request's Content-Length against budget for
+// as long as it is being handled; a body of unknown length, such as a
+// chunked one, is charged the most the rules let a config be. Requests
+// queue briefly when the budget is spent and get 503 if it does not free
+// up; a body larger than the whole budget can never run and gets 413.
+func withByteBudget(budget *byteBudget, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := r.ContentLength
+		if n < 0 {
+			n = min(activeRules.Load().Options.Limits.Effective().MaxBytes, budget.limit)
+		}
+		if n > budget.limit {
+			writeJSON(w, http.StatusRequestEntityTooLarge, ErrorResponse{Error: "Request body exceeds the server's in-flight limit"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), budgetQueueWait)
+		err := budget.acquire(ctx, n)
+		cancel()
+		if err != nil {
+			w.Header().Set("Retry-After", strconv.Itoa(int(budgetQueueWait.Seconds())))
+			writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{Error: "Server is busy, retry later"})
+			return
+		}
+		defer budget.release(n)
+
+		r.Body = http.MaxBytesReader(w, r.Body, n)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+
+	"cli-config-linter/linter"
+)
+
+type CompleteResponse struct {
+	Path        string              `json:"path"`
+	Completions []linter.Completion `json:"completions"`
+	// RuleConfigHash identifies the rules the completions come from, so
+	// clients can cache them until it changes.
+	RuleConfigHash string `json:"ruleConfigHash"`
+}
+
+// handleComplete lists the keys the active rules allow at ?path=, for
+// editor and dashboard autocompletion.
+func handleComplete(w http.ResponseWriter, r *http.Request) {
+	rules := activeRules.Load()
+	schema, err := engine.Schema(rules.Options)
+	if err != nil {
+		slog.Error("linter_internal_error", "error", err)
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Internal linter error"})
+		return
+	}
+	path := r.URL.Query().Get("path")
+	writeJSON(w, http.StatusOK, CompleteResponse{
+		Path:           path,
+		Completions:    linter.Complete(schema, path),
+		RuleConfigHash: rules.Hash,
+	})
+}
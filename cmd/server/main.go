@@ -1,15 +1,27 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log/slog"
+	"mime"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
 	"runtime/debug"
+	"slices"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
+	"cli-config-linter/internal/settings"
 	"cli-config-linter/linter"
 )
 
@@ -19,6 +31,21 @@ type Config struct {
 	Port      string
 	APIKeys   map[string]struct{}
 	StaticDir string
+	RCPath    string
+	// AdminKey guards the admin endpoints; they are disabled when empty.
+	AdminKey string
+
+	SlackSigningSecret string
+	// MaxInFlightBytes caps the total size of request bodies being linted
+	// concurrently.
+	MaxInFlightBytes int64
+	// ReadOnly starts the server in maintenance mode.
+	ReadOnly           bool
+	MaintenanceMessage string
+	// ProxyUpstream is the config store the validating proxy forwards to;
+	// the proxy is off when it is empty. ProxyRoutes is its allowlist.
+	ProxyUpstream string
+	ProxyRoutes   string
 }
 
 func loadConfig() Config {
@@ -44,10 +71,29 @@ func loadConfig() Config {
 		staticDir = "./static"
 	}
 
+	maxInFlight := int64(defaultInFlightBytes)
+	if raw := os.Getenv("CONFIG_LINTER_MAX_INFLIGHT_BYTES"); raw != "" {
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil && n > 0 {
+			maxInFlight = n
+		}
+	}
+
+	readOnly, _ := strconv.ParseBool(os.Getenv("CONFIG_LINTER_READ_ONLY"))
+	rcPath, _ := settings.RCPath("", os.Getenv)
+
 	return Config{
 		Port:      port,
 		APIKeys:   keys,
 		StaticDir: staticDir,
+		RCPath:    rcPath,
+		AdminKey:  os.Getenv("CONFIG_LINTER_ADMIN_KEY"),
+
+		SlackSigningSecret: os.Getenv("SLACK_SIGNING_SECRET"),
+		MaxInFlightBytes:   maxInFlight,
+		ReadOnly:           readOnly,
+		MaintenanceMessage: os.Getenv("CONFIG_LINTER_MAINTENANCE_MESSAGE"),
+		ProxyUpstream:      os.Getenv("CONFIG_LINTER_PROXY_UPSTREAM"),
+		ProxyRoutes:        os.Getenv("CONFIG_LINTER_PROXY_ROUTES"),
 	}
 }
 
@@ -57,6 +103,20 @@ type LintRequest struct {
 	Config         string `json:"config"`
 	Strict         bool   `json:"strict"`
 	FixSuggestions bool   `json:"fixSuggestions"`
+	// Style opts in to SeverityStyle findings.
+	Style bool `json:"style"`
+	// Redact hides config values in issue messages. The rc file can force
+	// it for every request.
+	Redact bool `json:"redact"`
+	// Debug adds per-check timings and issue counts to the response.
+	Debug bool `json:"debug"`
+	// DisabledRules and EnabledRules turn rules off for this request, on
+	// top of the rules the server's rc file turns off.
+	DisabledRules []string `json:"disabledRules,omitempty"`
+	EnabledRules  []string `json:"enabledRules,omitempty"`
+	// FailOn replaces the rc file's failOn for this request: the least
+	// severe issue (error, warn, info or hint) that makes the result fatal.
+	FailOn linter.Severity `json:"failOn,omitempty"`
 }
 
 type LintResponse struct {
@@ -64,12 +124,30 @@ type LintResponse struct {
 	Strict      bool           `json:"strict"`
 	Fatal       bool           `json:"fatal"`
 	GeneratedAt time.Time      `json:"generatedAt"`
+	// FailOn is the least severe issue that made the result fatal.
+	FailOn linter.Severity `json:"failOn"`
+	// RuleConfigHash and RulePacks identify the rules that produced the
+	// result; they change when the server reloads its rule packs or a
+	// request changes which rules run.
+	RuleConfigHash string   `json:"ruleConfigHash"`
+	RulePacks      []string `json:"rulePacks,omitempty"`
+	// EngineVersion and RulePackVersion let callers that cache or store
+	// results invalidate them when the linter or its packs change.
+	EngineVersion   string `json:"engineVersion"`
+	RulePackVersion string `json:"rulePackVersion"`
+	// RuleStats is set for debug requests, slowest check first.
+	RuleStats []linter.RuleStat `json:"ruleStats,omitempty"`
+	// Suppressed lists the issues inline suppressions cover; they never
+	// make the result fatal.
+	Suppressed []linter.Issue `json:"suppressed,omitempty"`
 }
 
 type HealthResponse struct {
 	Status  string `json:"status"`
 	Version string `json:"version"`
 	Uptime  string `json:"uptime"`
+	// Maintenance is set while the server is read-only.
+	Maintenance *maintenance `json:"maintenance,omitempty"`
 }
 
 type ErrorResponse struct {
@@ -86,9 +164,20 @@ type FetchResponse struct {
 
 // -- Main --
 
-var startTime time.Time
+var (
+	startTime time.Time
+	// engine caches per-options rule state across requests.
+	engine = linter.NewLinter()
+)
 
 func main() {
+	healthcheck := flag.Bool("healthcheck", false, "Probe /livez on the local server and exit 0 if it is alive (for Docker HEALTHCHECK)")
+	flag.Parse()
+
+	if *healthcheck {
+		os.Exit(probeLiveness(loadConfig().Port))
+	}
+
 	startTime = time.Now()
 	
 	// 1. Logging Setup (Structured JSON Logger)
@@ -103,21 +192,87 @@ func main() {
 		logger.Warn("security_alert: no API keys configured. service is unprotected.")
 	}
 
+	if cfg.RCPath != "" {
+		rules, err := loadRuleSet(context.Background(), cfg.RCPath)
+		if err != nil {
+			logger.Error("rules_load_failed", "path", cfg.RCPath, "error", err)
+			os.Exit(1)
+		}
+		activeRules.Store(rules)
+		logger.Info("rules_loaded", "rule_packs", rules.Packs, "hash", rules.Hash)
+
+		// SIGHUP re-reads the rc file and its packs without a restart.
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for range hup {
+				rules, err := reloadRules(context.Background(), cfg.RCPath)
+				if err != nil {
+					logger.Error("rules_reload_failed", "error", err)
+					continue
+				}
+				logger.Info("rules_reloaded", "rule_packs", rules.Packs, "hash", rules.Hash)
+			}
+		}()
+	}
+
 	// 2. Router Setup
 	mux := http.NewServeMux()
 
 	// 2a. Public Endpoints
 	mux.HandleFunc("GET /health", handleHealth)
+	mux.HandleFunc("GET /livez", handleLivez)
+	mux.HandleFunc("GET /metrics", handleMetrics)
+	mux.HandleFunc("GET /v1/selftest", handleSelftest)
 
 	// 2b. Private Endpoints (Secured)
 	// We handle auth manually in the chain for granular control
-	secured := withAPIKeyAuth(cfg.APIKeys, http.HandlerFunc(handleLint))
+	budget := newByteBudget(cfg.MaxInFlightBytes)
+	secured := withAPIKeyAuth(cfg.APIKeys, withByteBudget(budget, withRecording(http.HandlerFunc(handleLint))))
 	fetchSecured := withAPIKeyAuth(cfg.APIKeys, http.HandlerFunc(handleFetch))
 	
 	mux.Handle("POST /lint", secured)
 	mux.Handle("POST /fetch", fetchSecured)
+	mux.Handle("POST /v1/policy/eval", withAPIKeyAuth(cfg.APIKeys, withByteBudget(budget, http.HandlerFunc(handlePolicyEval))))
+	mux.Handle("GET /v1/complete", withAPIKeyAuth(cfg.APIKeys, http.HandlerFunc(handleComplete)))
 
-	// 2c. Static Assets
+	if cfg.ReadOnly {
+		m := setMaintenance(true, cfg.MaintenanceMessage)
+		logger.Warn("maintenance_mode", "enabled", true, "message", m.Message)
+	}
+
+	if cfg.AdminKey != "" {
+		adminKeys := map[string]struct{}{cfg.AdminKey: {}}
+		mux.Handle("POST /admin/maintenance", withAPIKeyAuth(adminKeys, http.HandlerFunc(handleMaintenance)))
+		mux.Handle("GET /v1/admin/recordings", withAPIKeyAuth(adminKeys, http.HandlerFunc(handleRecordings)))
+		mux.Handle("POST /v1/admin/recordings", withAPIKeyAuth(adminKeys, http.HandlerFunc(handleRecordings)))
+		if cfg.RCPath != "" {
+			mux.Handle("POST /admin/reload", withAPIKeyAuth(adminKeys, withWritable(handleReload(cfg.RCPath))))
+		}
+	}
+
+	// 2c. Integrations (authenticated by request signature, not API key)
+	if cfg.SlackSigningSecret != "" {
+		mux.Handle("POST /v1/integrations/slack", withByteBudget(budget, handleSlack(cfg.SlackSigningSecret)))
+	}
+
+	// 2d. Validating proxy in front of a config store
+	if cfg.ProxyUpstream != "" {
+		upstream, err := url.Parse(cfg.ProxyUpstream)
+		if err == nil && (upstream.Scheme == "" || upstream.Host == "") {
+			err = fmt.Errorf("%q is not an absolute URL", cfg.ProxyUpstream)
+		}
+		routes, routesErr := parseProxyRoutes(cfg.ProxyRoutes)
+		if err = errors.Join(err, routesErr); err != nil {
+			logger.Error("proxy_config_invalid", "error", err)
+			os.Exit(1)
+		}
+		// Every proxied request is a write to the store.
+		mux.Handle(proxyPrefix+"/", withAPIKeyAuth(cfg.APIKeys, withWritable(withByteBudget(budget, handleProxy(upstream, routes)))))
+		logger.Info("proxy_enabled", "upstream", upstream.Redacted(), "routes", cfg.ProxyRoutes)
+	}
+
+	// 2e. Static Assets
 	if info, err := os.Stat(cfg.StaticDir); err == nil && info.IsDir() {
 		logger.Info("static_files_enabled", "directory", cfg.StaticDir)
 		// Serve static files (HTML/JS/CSS)
@@ -141,11 +296,47 @@ func main() {
 		IdleTimeout:  120 * time.Second,
 	}
 
+	// 5. Graceful Shutdown
+	// As PID 1 in a container we only get SIGTERM if we ask for it, so
+	// handle it explicitly and drain in-flight requests before exiting.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		<-ctx.Done()
+		logger.Info("server_stopping")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error("server_shutdown_failed", "error", err)
+		}
+	}()
+
 	logger.Info("server_starting", "port", cfg.Port, "env", "production")
 	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		logger.Error("server_failed", "error", err)
 		os.Exit(1)
 	}
+	<-drained
+	logger.Info("server_stopped")
+}
+
+// probeLiveness is the client side of -healthcheck.
+func probeLiveness(port string) int {
+	client := &http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Get("http://127.0.0.1:" + port + "/livez")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "healthcheck:", err)
+		return 1
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintln(os.Stderr, "healthcheck: status", resp.StatusCode)
+		return 1
+	}
+	return 0
 }
 
 // -- Handlers --
@@ -153,12 +344,30 @@ func main() {
 func handleHealth(w http.ResponseWriter, r *http.Request) {
 	resp := HealthResponse{
 		Status:  "ok",
-		Version: "1.0.0",
+		Version: linter.Version,
 		Uptime:  time.Since(startTime).String(),
 	}
+	// The server still answers lint requests in maintenance, so status
+	// stays "ok" and monitors keep routing to it.
+	resp.Maintenance = maintenanceMode.Load()
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// handleMetrics exposes the rule cache counters in the Prometheus text format.
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	stats := engine.Stats()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintf(w, "# TYPE configlint_rule_cache_entries gauge\nconfiglint_rule_cache_entries %d\n", stats.Entries)
+	fmt.Fprintf(w, "# TYPE configlint_rule_cache_hits_total counter\nconfiglint_rule_cache_hits_total %d\n", stats.Hits)
+	fmt.Fprintf(w, "# TYPE configlint_rule_cache_misses_total counter\nconfiglint_rule_cache_misses_total %d\n", stats.Misses)
+}
+
+// handleLivez is a dependency-free liveness probe for container runtimes.
+func handleLivez(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("ok\n"))
+}
+
 func handleFetch(w http.ResponseWriter, r *http.Request) {
 	var req FetchRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -171,68 +380,153 @@ func handleFetch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	content, err := fetchConfig(r.Context(), req.URL)
+	if err != nil {
+		var upstream upstreamStatusError
+		switch {
+		case errors.Is(err, errFetchForbidden):
+			writeJSON(w, http.StatusForbidden, ErrorResponse{Error: "Internal network access forbidden"})
+		case errors.Is(err, errFetchTooLarge):
+			writeJSON(w, http.StatusRequestEntityTooLarge, ErrorResponse{Error: "File too large (max 1MB)"})
+		case errors.As(err, &upstream):
+			writeJSON(w, http.StatusBadGateway, ErrorResponse{Error: fmt.Sprintf("Upstream returned %d", int(upstream))})
+		default:
+			slog.Error("fetch_failed", "url", req.URL, "error", err)
+			writeJSON(w, http.StatusBadGateway, ErrorResponse{Error: "Failed to fetch URL"})
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, FetchResponse{Content: content})
+}
+
+var (
+	errFetchForbidden = errors.New("internal network access forbidden")
+	errFetchTooLarge  = errors.New("file too large (max 1MB)")
+)
+
+type upstreamStatusError int
+
+func (e upstreamStatusError) Error() string {
+	return fmt.Sprintf("upstream returned %d", int(e))
+}
+
+// fetchConfig downloads a remote config for linting, shared by /fetch and
+// the chat integrations.
+func fetchConfig(ctx context.Context, url string) (string, error) {
 	// Security: Basic check to prevent SSRF to local network (simple check)
-	lower := strings.ToLower(req.URL)
+	lower := strings.ToLower(url)
 	if strings.Contains(lower, "localhost") || strings.Contains(lower, "127.0.0.1") || strings.Contains(lower, "192.168.") {
-		writeJSON(w, http.StatusForbidden, ErrorResponse{Error: "Internal network access forbidden"})
-		return
+		return "", errFetchForbidden
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
 	}
-	
 	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(req.URL)
+	resp, err := client.Do(req)
 	if err != nil {
-		slog.Error("fetch_failed", "url", req.URL, "error", err)
-		writeJSON(w, http.StatusBadGateway, ErrorResponse{Error: "Failed to fetch URL"})
-		return
+		return "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		writeJSON(w, http.StatusBadGateway, ErrorResponse{Error: fmt.Sprintf("Upstream returned %d", resp.StatusCode)})
-		return
+		return "", upstreamStatusError(resp.StatusCode)
 	}
 
 	// Limit size to 1MB
-	// safe read
 	const MaxSize = 1024 * 1024
-	body := make([]byte, 0, MaxSize)
-	buf := make([]byte, 1024)
-	total := 0
-	
-	for {
-		n, err := resp.Body.Read(buf)
-		if n > 0 {
-			total += n
-			if total > MaxSize {
-				writeJSON(w, http.StatusRequestEntityTooLarge, ErrorResponse{Error: "File too large (max 1MB)"})
-				return
-			}
-			body = append(body, buf[:n]...)
-		}
-		if err != nil {
-			break
-		}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxSize+1))
+	if err != nil {
+		return "", err
 	}
-
-	writeJSON(w, http.StatusOK, FetchResponse{Content: string(body)})
+	if len(body) > MaxSize {
+		return "", errFetchTooLarge
+	}
+	return string(body), nil
 }
 
+// lintTimeout bounds the linting of one request, leaving time to write the
+// response within the server's WriteTimeout.
+const lintTimeout = 8 * time.Second
+
 func handleLint(w http.ResponseWriter, r *http.Request) {
-	// 1. Decode
+	// 1. Decode (JSON escaping can double the config, hence the slack)
+	rules := activeRules.Load()
+	r.Body = http.MaxBytesReader(w, r.Body, 2*rules.Options.Limits.Effective().MaxBytes)
 	var req LintRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.Warn("bad_request", "error", err)
-		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON body"})
-		return
+	var config io.Reader
+	if isRawConfig(r) {
+		// The body is the config: stream it into the linter unbuffered.
+		req = rawLintRequest(r)
+		body := bufio.NewReader(r.Body)
+		if _, err := body.Peek(1); err == io.EOF {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Config content cannot be empty"})
+			return
+		}
+		config = body
+	} else {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.Warn("bad_request", "error", err)
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON body"})
+			return
+		}
+		if strings.TrimSpace(req.Config) == "" {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Config content cannot be empty"})
+			return
+		}
+		config = strings.NewReader(req.Config)
 	}
 
-	if strings.TrimSpace(req.Config) == "" {
-		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Config content cannot be empty"})
+	// 2. Logic (Core Linter)
+	opts := rules.Options
+	opts.Style = opts.Style || req.Style
+	opts.Redact = opts.Redact || req.Redact
+	opts.ReportSuppressed = true
+	if req.Debug {
+		opts.Stats = &linter.RuleStats{}
+	}
+	if len(req.DisabledRules) > 0 || len(req.EnabledRules) > 0 {
+		var err error
+		if opts, err = requestRules(opts, req); err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+	if req.FailOn != "" {
+		if err := (linter.Options{FailOn: req.FailOn}).Validate(); err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		opts.FailOn = req.FailOn
+	}
+	// Linting stops when the client disconnects or takes too long.
+	ctx, cancel := context.WithTimeout(r.Context(), lintTimeout)
+	defer cancel()
+	issues, err := engine.LintReaderContext(ctx, config, opts)
+	if errors.Is(err, context.Canceled) {
+		slog.Info("lint_cancelled", "error", err)
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{Error: "Linting took too long"})
+		return
+	}
+	var limitErr *linter.LimitError
+	if errors.As(err, &limitErr) {
+		writeJSON(w, http.StatusRequestEntityTooLarge, ErrorResponse{Error: limitErr.Error()})
+		return
+	}
+	var bodyErr *http.MaxBytesError
+	if errors.As(err, &bodyErr) {
+		writeJSON(w, http.StatusRequestEntityTooLarge, ErrorResponse{Error: "Request body too large"})
+		return
+	}
+	if errors.Is(err, linter.ErrNotText) {
+		writeJSON(w, http.StatusUnprocessableEntity, ErrorResponse{Error: err.Error()})
 		return
 	}
-
-	// 2. Logic (Core Linter)
-	issues, err := linter.LintBytes([]byte(req.Config))
 	if err != nil {
 		slog.Error("linter_internal_error", "error", err)
 		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Internal linter error"})
@@ -240,13 +534,8 @@ func handleLint(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 3. Process Results
-	fatal := false
-	for _, issue := range issues {
-		if issue.Severity == linter.SeverityError || (req.Strict && issue.Severity == linter.SeverityWarning) {
-			fatal = true
-			break
-		}
-	}
+	issues, suppressed := linter.SplitSuppressed(issues)
+	fatal := isFatal(issues, req.Strict, opts)
 
 	// 4. Respond
 	resp := LintResponse{
@@ -254,10 +543,85 @@ func handleLint(w http.ResponseWriter, r *http.Request) {
 		Strict:      req.Strict,
 		Fatal:       fatal,
 		GeneratedAt: time.Now().UTC(),
+		FailOn:      opts.FailThreshold(req.Strict),
+
+		RuleConfigHash:  opts.Hash(),
+		RulePacks:       rules.Packs,
+		EngineVersion:   linter.Version,
+		RulePackVersion: rules.PackVersion,
+		Suppressed:      suppressed,
+	}
+	if opts.Stats != nil {
+		resp.RuleStats = opts.Stats.Snapshot()
 	}
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// isFatal reports whether issues fail a request: any issue at least as
+// severe as opts.FailOn (warn in strict mode), or more warnings than the rc
+// file's warning budget.
+func isFatal(issues []linter.Issue, strict bool, opts linter.Options) bool {
+	return linter.Fails(issues, opts.FailThreshold(strict)) || opts.OverWarningBudget(linter.CountWarnings(issues))
+}
+
+// isRawConfig reports whether a /lint body is the config itself, by its
+// Content-Type, rather than a JSON LintRequest.
+func isRawConfig(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml", "application/xml", "text/xml", "text/plain":
+		return true
+	}
+	return false
+}
+
+// rawLintRequest reads the options of a raw /lint body from the query
+// string (?strict=true&redact=true); Config stays empty.
+func rawLintRequest(r *http.Request) LintRequest {
+	q := r.URL.Query()
+	flag := func(name string) bool {
+		v, _ := strconv.ParseBool(q.Get(name))
+		return v
+	}
+	list := func(name string) []string {
+		if v := q.Get(name); v != "" {
+			return strings.Split(v, ",")
+		}
+		return nil
+	}
+	return LintRequest{
+		Strict:         flag("strict"),
+		FixSuggestions: flag("fixSuggestions"),
+		Style:          flag("style"),
+		Redact:         flag("redact"),
+		Debug:          flag("debug"),
+		DisabledRules:  list("disabledRules"),
+		EnabledRules:   list("enabledRules"),
+		FailOn:         linter.Severity(q.Get("failOn")),
+	}
+}
+
+// requestRules turns off the rules req turns off, on top of those opts
+// turns off: a request can narrow the server's rules but not widen them.
+func requestRules(opts linter.Options, req LintRequest) (linter.Options, error) {
+	enabled := opts.EnabledRules
+	if len(req.EnabledRules) > 0 {
+		enabled = req.EnabledRules
+		if len(opts.EnabledRules) > 0 {
+			enabled = slices.DeleteFunc(slices.Clone(enabled), func(id string) bool { return !slices.Contains(opts.EnabledRules, id) })
+		}
+	}
+	if len(enabled) > 0 {
+		enabled = slices.DeleteFunc(slices.Clone(enabled), func(id string) bool { return slices.Contains(req.DisabledRules, id) })
+		if len(enabled) == 0 {
+			return opts, errors.New("the request turns off every rule enabled on this server")
+		}
+	}
+	opts.EnabledRules = enabled
+	opts.DisabledRules = append(slices.Clone(opts.DisabledRules), req.DisabledRules...)
+	return opts, opts.Validate()
+}
+
 // -- Middleware --
 
 // withRecovery handles panics gracefully
@@ -2,11 +2,24 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
+
+	"cli-config-linter/linter"
 )
 
 // We need to export/refactor handler logic to test it easily,
@@ -95,4 +108,564 @@ func TestLintHandler_Logic(t *testing.T) {
 	if len(result.Issues) > 0 {
 		t.Errorf("expected 0 issues for valid config, got %d", len(result.Issues))
 	}
+	if result.EngineVersion != linter.Version {
+		t.Errorf("expected engineVersion %q, got %q", linter.Version, result.EngineVersion)
+	}
+}
+
+func TestLintHandlerRawBody(t *testing.T) {
+	config := "metadata:\n  name: unit-test\n  env: dev\nsettings:\n  replicas: 1\n  timeout: 10\nfeatures:\n  - name: f1\n    enabled: maybe\n"
+	req := httptest.NewRequest("POST", "/lint?strict=true", strings.NewReader(config))
+	req.Header.Set("Content-Type", "application/yaml; charset=utf-8")
+	w := httptest.NewRecorder()
+	handleLint(w, req)
+
+	var result LintResponse
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if w.Code != http.StatusOK || !result.Strict || !result.Fatal || len(result.Issues) != 1 || result.Issues[0].RuleID != "FEAT003" {
+		t.Errorf("expected the raw config linted in strict mode, got %d %+v", w.Code, result)
+	}
+
+	req = httptest.NewRequest("POST", "/lint", strings.NewReader(""))
+	req.Header.Set("Content-Type", "text/yaml")
+	w = httptest.NewRecorder()
+	handleLint(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an empty body, got %d", w.Code)
+	}
+}
+
+func TestLintHandlerRuleSelection(t *testing.T) {
+	config := "metadata:\n  name: unit-test\n  env: dev\nsettings:\n  replicas: 0\n  timeout: -1\n"
+	lint := func(body string) (int, []string) {
+		w := httptest.NewRecorder()
+		handleLint(w, httptest.NewRequest("POST", "/lint", strings.NewReader(body)))
+		var result LintResponse
+		json.NewDecoder(w.Body).Decode(&result)
+		var ids []string
+		for _, issue := range result.Issues {
+			ids = append(ids, issue.RuleID)
+		}
+		return w.Code, ids
+	}
+	body, _ := json.Marshal(LintRequest{Config: config, DisabledRules: []string{"SET005"}})
+	if code, ids := lint(string(body)); code != http.StatusOK || !slices.Equal(ids, []string{"SET003"}) {
+		t.Errorf("expected SET005 turned off, got %d %v", code, ids)
+	}
+	body, _ = json.Marshal(LintRequest{Config: config, EnabledRules: []string{"SET005"}})
+	if code, ids := lint(string(body)); code != http.StatusOK || !slices.Equal(ids, []string{"SET005"}) {
+		t.Errorf("expected only SET005, got %d %v", code, ids)
+	}
+	hash := func(req LintRequest) string {
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		handleLint(w, httptest.NewRequest("POST", "/lint", bytes.NewReader(body)))
+		var result LintResponse
+		json.NewDecoder(w.Body).Decode(&result)
+		return result.RuleConfigHash
+	}
+	if base, narrowed := hash(LintRequest{Config: config}), hash(LintRequest{Config: config, DisabledRules: []string{"SET005"}}); base != activeRules.Load().Hash || narrowed == base {
+		t.Errorf("expected a request that turns off rules to report its own rule hash, got %s and %s", base, narrowed)
+	}
+
+	// A request cannot turn on what the server's rc file turns off.
+	rules := *activeRules.Load()
+	saved := activeRules.Load()
+	defer activeRules.Store(saved)
+	rules.Options.EnabledRules = []string{"SET003"}
+	activeRules.Store(&rules)
+	body, _ = json.Marshal(LintRequest{Config: config, EnabledRules: []string{"SET003", "SET005"}})
+	if code, ids := lint(string(body)); code != http.StatusOK || !slices.Equal(ids, []string{"SET003"}) {
+		t.Errorf("expected the server's enabled rules to bound the request, got %d %v", code, ids)
+	}
+	body, _ = json.Marshal(LintRequest{Config: config, DisabledRules: []string{"SET003"}})
+	if code, _ := lint(string(body)); code != http.StatusBadRequest {
+		t.Errorf("expected 400 when a request turns off every enabled rule, got %d", code)
+	}
+}
+
+func TestValidatingProxy(t *testing.T) {
+	var stored []string
+	store := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		stored = append(stored, r.Method+" "+r.URL.Path+" "+r.Header.Get("X-API-Key")+" "+string(body))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer store.Close()
+	upstream, _ := url.Parse(store.URL)
+	routes, err := parseProxyRoutes("PUT /v1/kv/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := handleProxy(upstream, routes)
+
+	valid := "metadata:\n  name: unit-test\n  env: dev\nsettings:\n  replicas: 1\n  timeout: 10\n"
+	req := httptest.NewRequest("PUT", "/proxy/v1/kv/app", strings.NewReader(valid))
+	req.Header.Set("X-API-Key", "secret")
+	w := httptest.NewRecorder()
+	proxy.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated || len(stored) != 1 || stored[0] != "PUT /v1/kv/app  "+valid {
+		t.Errorf("expected the valid config forwarded without the API key, got %d %q", w.Code, stored)
+	}
+
+	w = httptest.NewRecorder()
+	proxy.ServeHTTP(w, httptest.NewRequest("PUT", "/proxy/v1/kv/app", strings.NewReader("settings:\n  replicas: 1\n")))
+	var result LintResponse
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if w.Code != http.StatusUnprocessableEntity || !result.Fatal || len(result.Issues) == 0 || len(stored) != 1 {
+		t.Errorf("expected the invalid config rejected with its issues, got %d %+v", w.Code, result)
+	}
+
+	for _, target := range []string{"/proxy/v1/kv/../admin", "/proxy/v1/other"} {
+		w = httptest.NewRecorder()
+		proxy.ServeHTTP(w, httptest.NewRequest("PUT", target, strings.NewReader(valid)))
+		if w.Code != http.StatusForbidden {
+			t.Errorf("expected PUT %s to be refused, got %d", target, w.Code)
+		}
+	}
+	w = httptest.NewRecorder()
+	proxy.ServeHTTP(w, httptest.NewRequest("DELETE", "/proxy/v1/kv/app", nil))
+	if w.Code != http.StatusForbidden || len(stored) != 1 {
+		t.Errorf("expected DELETE to be refused, got %d", w.Code)
+	}
+
+	// Read-only mode stops writes before they reach the store.
+	t.Cleanup(func() { maintenanceMode.Store(nil) })
+	setMaintenance(true, "migrating")
+	w = httptest.NewRecorder()
+	withWritable(proxy).ServeHTTP(w, httptest.NewRequest("PUT", "/proxy/v1/kv/app", strings.NewReader(valid)))
+	if w.Code != http.StatusServiceUnavailable || len(stored) != 1 {
+		t.Errorf("expected 503 in read-only mode with nothing stored, got %d %q", w.Code, stored)
+	}
+
+	if _, err := parseProxyRoutes("PUT"); err == nil {
+		t.Error("expected a route without a path to be rejected")
+	}
+}
+
+func signSlack(secret, ts, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + ts + ":" + body))
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestSlackHandler(t *testing.T) {
+	handler := handleSlack("shh")
+	config := "```metadata:\n  name: slack\n  env: prod\nsettings:\n  replicas: 0\n  timeout: 5```"
+	body := url.Values{"command": {"/configlint"}, "text": {config}}.Encode()
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req := httptest.NewRequest("POST", "/v1/integrations/slack", strings.NewReader(body))
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", signSlack("wrong", ts, body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for bad signature, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/v1/integrations/slack", strings.NewReader(body))
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", signSlack("shh", ts, body))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", w.Code)
+	}
+
+	var resp SlackResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if !strings.Contains(resp.Text, "1 error(s)") || !strings.Contains(resp.Text, "settings.replicas must be a positive integer") {
+		t.Errorf("unexpected summary: %q", resp.Text)
+	}
+}
+
+func TestSlackSummaryCounts(t *testing.T) {
+	got := formatSlackSummary([]linter.Issue{
+		{Line: 3, Severity: linter.SeverityWarning, Message: "soft"},
+		{Line: 5, Severity: linter.SeverityInfo, Message: "defaults to 30"},
+		{Line: 7, Severity: linter.SeverityHint, Message: "quote it"},
+	})
+	if !strings.HasPrefix(got, ":warning: 0 error(s), 1 warning(s), 2 note(s)\n") {
+		t.Errorf("expected info and hint counted apart from warnings, got %q", got)
+	}
+	got = formatSlackSummary([]linter.Issue{{Line: 5, Severity: linter.SeverityInfo, Message: "defaults to 30"}})
+	if !strings.HasPrefix(got, ":information_source: 0 error(s), 0 warning(s), 1 note(s)\n") {
+		t.Errorf("expected an info-only summary without a warning icon, got %q", got)
+	}
+}
+
+func TestLivezHandler(t *testing.T) {
+	req := httptest.NewRequest("GET", "/livez", nil)
+	w := httptest.NewRecorder()
+
+	handleLivez(w, req)
+
+	if w.Code != http.StatusOK || w.Body.String() != "ok\n" {
+		t.Errorf("expected 200 ok, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestSelftestHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	handleSelftest(w, httptest.NewRequest("GET", "/v1/selftest", nil))
+
+	var result SelftestResponse
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if w.Code != http.StatusOK || result.Status != "pass" || len(result.Cases) != len(selftestCases) {
+		t.Errorf("expected every fixture to pass, got %d %+v", w.Code, result)
+	}
+
+	// A fixture that no longer lints as expected fails the self-test.
+	saved := selftestCases[0].want
+	selftestCases[0].want = []string{"META001"}
+	defer func() { selftestCases[0].want = saved }()
+	w = httptest.NewRecorder()
+	handleSelftest(w, httptest.NewRequest("GET", "/v1/selftest", nil))
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), `"status":"fail"`) {
+		t.Errorf("expected 503 when a fixture fails, got %d %s", w.Code, w.Body)
+	}
+}
+
+func TestByteBudget(t *testing.T) {
+	budget := newByteBudget(100)
+	handler := withByteBudget(budget, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest("POST", "/lint", strings.NewReader(strings.Repeat("x", 60)))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected request within budget to pass, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/lint", strings.NewReader(strings.Repeat("x", 101)))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 for a body larger than the budget, got %d", w.Code)
+	}
+
+	// With most of the budget held elsewhere the request cannot start; a
+	// cancelled context stands in for the queue timeout.
+	if err := budget.acquire(context.Background(), 50); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req = httptest.NewRequest("POST", "/lint", strings.NewReader(strings.Repeat("x", 60))).WithContext(ctx)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Errorf("expected 503 with Retry-After while the budget is spent, got %d", w.Code)
+	}
+
+	budget.release(50)
+	req = httptest.NewRequest("POST", "/lint", strings.NewReader(strings.Repeat("x", 60)))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("expected request to pass once the budget is released, got %d", w.Code)
+	}
+
+	// A body of unknown length is charged the most it may be: here, the
+	// whole budget.
+	req = httptest.NewRequest("POST", "/lint", strings.NewReader(strings.Repeat("x", 60)))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("expected a body of unknown length to pass, got %d", w.Code)
+	}
+	if err := budget.acquire(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	req = httptest.NewRequest("POST", "/lint", strings.NewReader(strings.Repeat("x", 60))).WithContext(ctx)
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected a body of unknown length to wait for the whole budget, got %d", w.Code)
+	}
+	budget.release(1)
+}
+
+func TestReloadSwapsRules(t *testing.T) {
+	prev := activeRules.Load()
+	t.Cleanup(func() { activeRules.Store(prev) })
+
+	path := filepath.Join(t.TempDir(), ".configlintrc.json")
+	if err := os.WriteFile(path, []byte(`{"environments": ["qa"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	handleReload(path)(w, httptest.NewRequest("POST", "/admin/reload", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 from reload, got %d: %s", w.Code, w.Body.String())
+	}
+
+	body, _ := json.Marshal(LintRequest{Config: "metadata:\n  name: a\n  env: qa\nsettings:\n  replicas: 1\n  timeout: 5\n"})
+	w = httptest.NewRecorder()
+	handleLint(w, httptest.NewRequest("POST", "/lint", bytes.NewReader(body)))
+	var resp LintResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if len(resp.Issues) != 0 || resp.RuleConfigHash != activeRules.Load().Hash || resp.RuleConfigHash == prev.Hash {
+		t.Fatalf("expected the reloaded rules to apply, got %+v", resp)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"profiles": ["nope"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	handleReload(path)(w, httptest.NewRequest("POST", "/admin/reload", nil))
+	if w.Code != http.StatusBadGateway || activeRules.Load().Hash != resp.RuleConfigHash {
+		t.Errorf("expected a failed reload to keep the active rules, got %d", w.Code)
+	}
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	t.Cleanup(func() { maintenanceMode.Store(nil) })
+
+	w := httptest.NewRecorder()
+	handleMaintenance(w, httptest.NewRequest("POST", "/admin/maintenance", strings.NewReader(`{"enabled": true, "message": "migrating"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	reached := false
+	write := withWritable(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { reached = true }))
+	w = httptest.NewRecorder()
+	write.ServeHTTP(w, httptest.NewRequest("POST", "/admin/reload", nil))
+	if reached || w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "migrating") {
+		t.Errorf("expected 503 with the maintenance message, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	handleHealth(w, httptest.NewRequest("GET", "/health", nil))
+	var health HealthResponse
+	if err := json.NewDecoder(w.Body).Decode(&health); err != nil {
+		t.Fatal(err)
+	}
+	if health.Status != "ok" || health.Maintenance == nil || health.Maintenance.Message != "migrating" {
+		t.Errorf("expected the health banner, got %+v", health)
+	}
+
+	body, _ := json.Marshal(LintRequest{Config: "metadata:\n  name: a\n"})
+	w = httptest.NewRecorder()
+	handleLint(w, httptest.NewRequest("POST", "/lint", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected /lint to keep working, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handleMaintenance(w, httptest.NewRequest("POST", "/admin/maintenance", strings.NewReader(`{"enabled": false}`)))
+	reached = false
+	write.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/admin/reload", nil))
+	if !reached {
+		t.Error("expected writes to pass once maintenance is off")
+	}
+}
+
+func TestRecordingsAreRedacted(t *testing.T) {
+	t.Cleanup(func() { recordings.Store(nil) })
+
+	w := httptest.NewRecorder()
+	handleRecordings(w, httptest.NewRequest("POST", "/v1/admin/recordings", strings.NewReader(`{"enabled": true, "size": 2}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	lint := withRecording(http.HandlerFunc(handleLint))
+	for _, env := range []string{"db.internal.acme.net", "first", "second"} {
+		body, _ := json.Marshal(LintRequest{Config: "metadata:\n  name: billing\n  env: " + env + "\n"})
+		w = httptest.NewRecorder()
+		lint.ServeHTTP(w, httptest.NewRequest("POST", "/lint", bytes.NewReader(body)))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), env) {
+			t.Fatalf("expected the unredacted result, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	w = httptest.NewRecorder()
+	handleRecordings(w, httptest.NewRequest("GET", "/v1/admin/recordings", nil))
+	for _, value := range []string{"billing", "db.internal.acme.net", "first", "second"} {
+		if strings.Contains(w.Body.String(), value) {
+			t.Errorf("expected %q to be redacted, got %s", value, w.Body.String())
+		}
+	}
+	var resp RecordingsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Enabled || len(resp.Recordings) != 2 {
+		t.Fatalf("expected the last 2 recordings, got %+v", resp)
+	}
+	got := resp.Recordings[0]
+	if got.Request == nil || got.Request.Config != "metadata:\n  name: <redacted>\n  env: <redacted>\n" {
+		t.Errorf("expected the config with its values redacted, got %+v", got.Request)
+	}
+	if got.Response == nil || len(got.Response.Issues) == 0 || got.Response.Issues[0].Line == 0 {
+		t.Errorf("expected the issues with their positions, got %+v", got.Response)
+	}
+}
+
+func TestPolicyEvalReturnsDelta(t *testing.T) {
+	// Only qa is allowed under the candidate rules, so the dev env is a
+	// new finding; nothing existing goes away.
+	body, _ := json.Marshal(PolicyEvalRequest{
+		Config:  "metadata:\n  name: a\n  env: dev\nsettings:\n  replicas: 1\n  timeout: 5\n",
+		Options: linter.Options{Environments: []string{"qa"}},
+	})
+	w := httptest.NewRecorder()
+	handlePolicyEval(w, httptest.NewRequest("POST", "/v1/policy/eval", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp PolicyEvalResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Added) != 1 || resp.Added[0].Line != 3 || len(resp.Removed) != 0 {
+		t.Errorf("unexpected delta: %+v", resp)
+	}
+	if resp.CandidateRuleConfigHash == resp.RuleConfigHash {
+		t.Errorf("expected the candidate hash to differ, got %s", resp.CandidateRuleConfigHash)
+	}
+
+	body, _ = json.Marshal(PolicyEvalRequest{Config: "metadata:\n  name: a\n", Options: linter.Options{FailOn: "bogus"}})
+	w = httptest.NewRecorder()
+	handlePolicyEval(w, httptest.NewRequest("POST", "/v1/policy/eval", bytes.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid options, got %d", w.Code)
+	}
+}
+
+func TestPolicyEvalRejectsNonRuleOptions(t *testing.T) {
+	for _, opts := range []string{
+		`{"limits": {"maxBytes": 1}}`,
+		`{"concurrency": 64}`,
+		`{"expressionRules": [{"id": "X001", "expr": "true", "message": "x"}]}`,
+		`{"schema": {"type": "object"}}`,
+		`{"cueSchema": "a: int"}`,
+		`{"templateVariables": {"a": "${b}"}}`,
+	} {
+		body := `{"config": "metadata:\n  name: a\n", "options": ` + opts + `}`
+		w := httptest.NewRecorder()
+		handlePolicyEval(w, httptest.NewRequest("POST", "/v1/policy/eval", strings.NewReader(body)))
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "cannot be evaluated") {
+			t.Errorf("%s: expected 400, got %d: %s", opts, w.Code, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	body := `{"config": "` + strings.Repeat("a", maxPolicyEvalBytes) + `"}`
+	handlePolicyEval(w, httptest.NewRequest("POST", "/v1/policy/eval", strings.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected an oversized body to be refused, got %d", w.Code)
+	}
+}
+
+func TestWarningBudgetFailsRequest(t *testing.T) {
+	prev := activeRules.Load()
+	t.Cleanup(func() { activeRules.Store(prev) })
+
+	// The config has one warning (no timeout): over a budget of zero, within
+	// a budget of one.
+	body, _ := json.Marshal(LintRequest{Config: "metadata:\n  name: a\n  env: dev\nsettings:\n  replicas: 1\n"})
+	for budget, wantFatal := range map[int]bool{0: true, 1: false} {
+		activeRules.Store(newRuleSet(linter.Options{MaxWarnings: &budget}, nil))
+		w := httptest.NewRecorder()
+		handleLint(w, httptest.NewRequest("POST", "/lint", bytes.NewReader(body)))
+		var resp LintResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Fatal != wantFatal {
+			t.Errorf("budget %d: expected fatal=%v, got %+v", budget, wantFatal, resp)
+		}
+	}
+}
+
+func TestLintHandlerFailOn(t *testing.T) {
+	// The config's only issue is a warning (no timeout).
+	config := "metadata:\n  name: a\n  env: dev\nsettings:\n  replicas: 1\n"
+	for failOn, wantFatal := range map[linter.Severity]bool{"": false, linter.SeverityWarning: true, linter.SeverityHint: true} {
+		body, _ := json.Marshal(LintRequest{Config: config, FailOn: failOn})
+		w := httptest.NewRecorder()
+		handleLint(w, httptest.NewRequest("POST", "/lint", bytes.NewReader(body)))
+		var resp LintResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Fatal != wantFatal {
+			t.Errorf("failOn %q: expected fatal=%v, got %+v", failOn, wantFatal, resp)
+		}
+	}
+
+	req := httptest.NewRequest("POST", "/lint?failOn=style", strings.NewReader(config))
+	req.Header.Set("Content-Type", "application/yaml")
+	w := httptest.NewRecorder()
+	handleLint(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "failOn") {
+		t.Errorf("expected an unknown failOn to be rejected, got %d %s", w.Code, w.Body)
+	}
+}
+
+func TestLintRejectsBinary(t *testing.T) {
+	body, _ := json.Marshal(LintRequest{Config: "metadata:\x00\x01\x02 name: a\n"})
+	w := httptest.NewRecorder()
+	handleLint(w, httptest.NewRequest("POST", "/lint", bytes.NewReader(body)))
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "not a text config") {
+		t.Errorf("expected 422 for binary content, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestLintDebugReportsRuleStats(t *testing.T) {
+	body, _ := json.Marshal(LintRequest{Config: "metadata:\n  name: a\n  env: dev\nsettings:\n  replicas: 0\n  timeout: 5\n", Debug: true})
+	w := httptest.NewRecorder()
+	handleLint(w, httptest.NewRequest("POST", "/lint", bytes.NewReader(body)))
+	var resp LintResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, st := range resp.RuleStats {
+		if st.Check == "settings" {
+			found = st.Runs == 1 && st.Issues["SET003"] == 1
+		}
+	}
+	if !found {
+		t.Errorf("expected settings stats with one SET003 hit, got %+v", resp.RuleStats)
+	}
+}
+
+func TestCompleteListsKeys(t *testing.T) {
+	w := httptest.NewRecorder()
+	handleComplete(w, httptest.NewRequest("GET", "/v1/complete?path=metadata.", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp CompleteResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Completions) != 2 || resp.Completions[0].Key != "env" || len(resp.Completions[0].Values) == 0 {
+		t.Errorf("expected env with its values and name, got %+v", resp.Completions)
+	}
+	if resp.RuleConfigHash != activeRules.Load().Hash {
+		t.Errorf("expected the active rule hash, got %s", resp.RuleConfigHash)
+	}
 }
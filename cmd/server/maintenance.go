@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const defaultMaintenanceMessage = "The server is in read-only maintenance mode; try again later."
+
+// maintenance is the read-only mode state. While enabled, write endpoints
+// answer 503 and /health carries the message as a banner; /lint keeps
+// working.
+type maintenance struct {
+	Enabled bool      `json:"enabled"`
+	Message string    `json:"message,omitempty"`
+	Since   time.Time `json:"since"`
+}
+
+var maintenanceMode atomic.Pointer[maintenance]
+
+func setMaintenance(enabled bool, message string) *maintenance {
+	if !enabled {
+		maintenanceMode.Store(nil)
+		return &maintenance{}
+	}
+	if message == "" {
+		message = defaultMaintenanceMessage
+	}
+	m := &maintenance{Enabled: true, Message: message, Since: time.Now().UTC()}
+	maintenanceMode.Store(m)
+	return m
+}
+
+// withWritable rejects requests with 503 while the server is read-only.
+func withWritable(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m := maintenanceMode.Load(); m != nil {
+			w.Header().Set("Retry-After", "300")
+			writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{Error: m.Message})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type MaintenanceRequest struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message"`
+}
+
+// handleMaintenance is the admin endpoint that toggles read-only mode.
+func handleMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req MaintenanceRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON body"})
+		return
+	}
+	m := setMaintenance(req.Enabled, req.Message)
+	slog.Info("maintenance_mode", "enabled", m.Enabled, "message", m.Message)
+	writeJSON(w, http.StatusOK, m)
+}
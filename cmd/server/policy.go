@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"cli-config-linter/linter"
+)
+
+// PolicyEvalRequest is a dry run of a rule-config change: Options is merged
+// over the active rules the same way an rc file layers over rule packs.
+type PolicyEvalRequest struct {
+	Config  string         `json:"config"`
+	Options linter.Options `json:"options"`
+}
+
+// PolicyEvalResponse is the issue delta between the active and candidate
+// rules for one config.
+type PolicyEvalResponse struct {
+	Added     []linter.Issue `json:"added"`
+	Removed   []linter.Issue `json:"removed"`
+	Unchanged int            `json:"unchanged"`
+
+	RuleConfigHash          string `json:"ruleConfigHash"`
+	CandidateRuleConfigHash string `json:"candidateRuleConfigHash"`
+}
+
+// maxPolicyEvalBytes bounds a dry run's body, config and options together.
+const maxPolicyEvalBytes = 8 << 20
+
+// policyEvalFields are the options a dry run may change: those tuning which
+// rules report and how. The others compile code (expressions, schemas,
+// patterns, message templates) or change resource limits, and stay as the
+// active rules set them.
+var policyEvalFields = []string{
+	"environments", "defaultTimeout", "profiles", "style", "strictKeys",
+	"governance", "consumedKeys", "defaults", "maxWarnings",
+	"reviewWindowDays", "maxProdRollout", "disabledRules", "enabledRules",
+	"severities", "failOn", "enums",
+}
+
+// handlePolicyEval lints the config under the active and the candidate
+// rules and returns the findings that appear or disappear. Nothing is
+// stored; only the options in policyEvalFields may differ, so a candidate
+// compiles nothing that outlives the request.
+func handlePolicyEval(w http.ResponseWriter, r *http.Request) {
+	rules := activeRules.Load()
+	r.Body = http.MaxBytesReader(w, r.Body, min(maxPolicyEvalBytes, 2*rules.Options.Limits.Effective().MaxBytes))
+	var req PolicyEvalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON body"})
+		return
+	}
+	if strings.TrimSpace(req.Config) == "" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Config content cannot be empty"})
+		return
+	}
+
+	if fields := disallowedPolicyFields(req.Options); len(fields) > 0 {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid options: " + strings.Join(fields, ", ") + " cannot be evaluated; use " + strings.Join(policyEvalFields, ", ")})
+		return
+	}
+	candidate := rules.Options.Merge(req.Options)
+	if err := candidate.Validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid options: " + err.Error()})
+		return
+	}
+
+	before, err := engine.Lint("", []byte(req.Config), rules.Options)
+	var after []linter.Issue
+	if err == nil {
+		after, err = linter.LintWithOptions([]byte(req.Config), candidate)
+	}
+	var limitErr *linter.LimitError
+	if errors.As(err, &limitErr) {
+		writeJSON(w, http.StatusRequestEntityTooLarge, ErrorResponse{Error: limitErr.Error()})
+		return
+	}
+	if errors.Is(err, linter.ErrNotText) {
+		writeJSON(w, http.StatusUnprocessableEntity, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		slog.Error("linter_internal_error", "error", err)
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Internal linter error"})
+		return
+	}
+
+	added, removed, unchanged := issueDelta(before, after)
+	writeJSON(w, http.StatusOK, PolicyEvalResponse{
+		Added:                   added,
+		Removed:                 removed,
+		Unchanged:               unchanged,
+		RuleConfigHash:          rules.Hash,
+		CandidateRuleConfigHash: candidate.Hash(),
+	})
+}
+
+// disallowedPolicyFields lists the fields opts sets outside
+// policyEvalFields, by their JSON names.
+func disallowedPolicyFields(opts linter.Options) []string {
+	data, err := json.Marshal(opts)
+	if err != nil {
+		return []string{"options"}
+	}
+	var set map[string]json.RawMessage
+	if err := json.Unmarshal(data, &set); err != nil {
+		return []string{"options"}
+	}
+	var fields []string
+	for name, value := range set {
+		// Struct fields are encoded even when empty.
+		if string(value) != "{}" && !slices.Contains(policyEvalFields, name) {
+			fields = append(fields, name)
+		}
+	}
+	slices.Sort(fields)
+	return fields
+}
+
+// issueDelta matches issues by rule, position and message. Duplicates are
+// counted, so a finding that appears twice under the candidate rules but
+// once today shows up once in added.
+func issueDelta(before, after []linter.Issue) (added, removed []linter.Issue, unchanged int) {
+	type key struct {
+		rule, message string
+		line, column  int
+	}
+	keyOf := func(issue linter.Issue) key {
+		return key{issue.RuleID, issue.Message, issue.Line, issue.Column}
+	}
+
+	pending := make(map[key]int, len(before))
+	for _, issue := range before {
+		pending[keyOf(issue)]++
+	}
+	added = []linter.Issue{}
+	for _, issue := range after {
+		k := keyOf(issue)
+		if pending[k] > 0 {
+			pending[k]--
+			unchanged++
+			continue
+		}
+		added = append(added, issue)
+	}
+	removed = []linter.Issue{}
+	for _, issue := range before {
+		k := keyOf(issue)
+		if pending[k] > 0 {
+			pending[k]--
+			removed = append(removed, issue)
+		}
+	}
+	return added, removed, unchanged
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"cli-config-linter/linter"
+)
+
+// proxyPrefix is where the validating proxy is mounted; the rest of the path
+// is forwarded to the upstream store.
+const proxyPrefix = "/proxy"
+
+// proxyRoute allows one method on the paths under Prefix.
+type proxyRoute struct {
+	Method string
+	Prefix string
+}
+
+// parseProxyRoutes reads a comma-separated allowlist such as
+// "PUT /v1/kv/,POST /configs/".
+func parseProxyRoutes(raw string) ([]proxyRoute, error) {
+	var routes []proxyRoute
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		method, prefix, ok := strings.Cut(entry, " ")
+		prefix = strings.TrimSpace(prefix)
+		if !ok || method == "" || !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("proxy route %q: want METHOD /path-prefix", entry)
+		}
+		routes = append(routes, proxyRoute{Method: strings.ToUpper(method), Prefix: prefix})
+	}
+	if len(routes) == 0 {
+		return nil, errors.New("no proxy routes allowed")
+	}
+	return routes, nil
+}
+
+// allows reports whether method may be sent to p through the proxy.
+func (r proxyRoute) allows(method, p string) bool {
+	if method != r.Method {
+		return false
+	}
+	prefix := strings.TrimSuffix(r.Prefix, "/")
+	return p == prefix || strings.HasPrefix(p, prefix+"/")
+}
+
+// handleProxy is a validating reverse proxy for a config store that cannot
+// lint on its own: an allowed write is linted and forwarded to upstream only
+// when it is not fatal; otherwise the caller gets the issues with a 422 and
+// the store is never touched. Requests outside routes get 403.
+func handleProxy(upstream *url.URL, routes []proxyRoute) http.Handler {
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(upstream)
+			pr.SetXForwarded()
+			// Our API key is not the store's credential.
+			pr.Out.Header.Del("X-API-Key")
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			slog.Error("proxy_upstream_failed", "path", r.URL.Path, "error", err)
+			writeJSON(w, http.StatusBadGateway, ErrorResponse{Error: "Upstream store unavailable"})
+		},
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := path.Clean("/" + strings.TrimPrefix(r.URL.Path, proxyPrefix))
+		allowed := false
+		for _, route := range routes {
+			allowed = allowed || route.allows(r.Method, p)
+		}
+		if !allowed {
+			writeJSON(w, http.StatusForbidden, ErrorResponse{Error: fmt.Sprintf("%s %s is not allowed through the proxy", r.Method, p)})
+			return
+		}
+
+		rules := activeRules.Load()
+		opts := rules.Options
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, opts.Limits.Effective().MaxBytes))
+		var bodyErr *http.MaxBytesError
+		if errors.As(err, &bodyErr) {
+			writeJSON(w, http.StatusRequestEntityTooLarge, ErrorResponse{Error: "Request body too large"})
+			return
+		}
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid request body"})
+			return
+		}
+
+		// The stored key names the format (.ini, .xml, ...); keys without an
+		// extension are read as YAML or JSON.
+		issues, err := engine.Lint(path.Base(p), body, opts)
+		var limitErr *linter.LimitError
+		if errors.As(err, &limitErr) {
+			writeJSON(w, http.StatusRequestEntityTooLarge, ErrorResponse{Error: limitErr.Error()})
+			return
+		}
+		if err != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if isFatal(issues, false, opts) {
+			slog.Info("proxy_write_rejected", "method", r.Method, "path", p, "issues", len(issues))
+			writeJSON(w, http.StatusUnprocessableEntity, LintResponse{
+				Issues:      issues,
+				Fatal:       true,
+				GeneratedAt: time.Now().UTC(),
+
+				RuleConfigHash:  rules.Hash,
+				RulePacks:       rules.Packs,
+				EngineVersion:   linter.Version,
+				RulePackVersion: rules.PackVersion,
+			})
+			return
+		}
+
+		r.URL.Path = p
+		r.URL.RawPath = ""
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		w.Header().Set("X-Config-Lint-Issues", strconv.Itoa(len(issues)))
+		proxy.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"cli-config-linter/linter"
+)
+
+const (
+	defaultRecordings = 20
+	maxRecordings     = 200
+)
+
+// recording is one /lint exchange with every config value redacted, kept to
+// reproduce results that differ from the CLI's. Request and Response are
+// missing when the body was not a lint request or the answer not a result.
+type recording struct {
+	At       time.Time     `json:"at"`
+	Status   int           `json:"status"`
+	Request  *LintRequest  `json:"request,omitempty"`
+	Response *LintResponse `json:"response,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// recorder is a ring of the last size recordings.
+type recorder struct {
+	mu      sync.Mutex
+	size    int
+	since   time.Time
+	entries []recording
+	next    int
+}
+
+// recordings is nil while recording is off.
+var recordings atomic.Pointer[recorder]
+
+func (rec *recorder) add(r recording) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.entries) < rec.size {
+		rec.entries = append(rec.entries, r)
+		return
+	}
+	rec.entries[rec.next] = r
+	rec.next = (rec.next + 1) % rec.size
+}
+
+// list returns the recordings, newest first.
+func (rec *recorder) list() []recording {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	out := make([]recording, 0, len(rec.entries))
+	for i := range rec.entries {
+		j := (rec.next - 1 - i + 2*len(rec.entries)) % len(rec.entries)
+		out = append(out, rec.entries[j])
+	}
+	return out
+}
+
+// captureWriter keeps a copy of the response body.
+type captureWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *captureWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *captureWriter) Write(p []byte) (int, error) {
+	w.body.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+// withRecording records lint exchanges while recording is on. Only redacted
+// copies are kept: the config's values are replaced and issue messages
+// redacted as with "redact": true, so a recording shows keys, rules and
+// positions but none of the submitted values.
+func withRecording(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := recordings.Load()
+		if rec == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		limit := 2 * activeRules.Load().Options.Limits.Effective().MaxBytes
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+		if err != nil {
+			writeJSON(w, http.StatusRequestEntityTooLarge, ErrorResponse{Error: "Request body too large"})
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		cw := &captureWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(cw, r)
+		rec.add(sanitizeRecording(r, body, cw.status, cw.body.Bytes()))
+	})
+}
+
+func sanitizeRecording(httpReq *http.Request, body []byte, status int, respBody []byte) recording {
+	r := recording{At: time.Now().UTC(), Status: status}
+	var req LintRequest
+	if isRawConfig(httpReq) {
+		req = rawLintRequest(httpReq)
+		req.Config = string(body)
+	} else if err := json.Unmarshal(body, &req); err != nil {
+		r.Error = "request body is not a lint request"
+		return r
+	}
+	var resp LintResponse
+	if status == http.StatusOK {
+		if err := json.Unmarshal(respBody, &resp); err != nil {
+			r.Error = "response is not a lint result"
+			return r
+		}
+	} else {
+		// Error responses carry no config values.
+		var e ErrorResponse
+		_ = json.Unmarshal(respBody, &e)
+		r.Error = e.Error
+	}
+	config, issues, err := linter.RedactConfig([]byte(req.Config), resp.Issues)
+	if err != nil {
+		r.Error = "config could not be redacted: " + err.Error()
+		return r
+	}
+	req.Config = string(config)
+	r.Request = &req
+	if status == http.StatusOK {
+		resp.Issues = issues
+		r.Response = &resp
+	}
+	return r
+}
+
+type RecordingsRequest struct {
+	Enabled bool `json:"enabled"`
+	// Size is how many recordings to keep; 0 means defaultRecordings.
+	Size int `json:"size"`
+}
+
+type RecordingsResponse struct {
+	Enabled    bool        `json:"enabled"`
+	Size       int         `json:"size,omitempty"`
+	Since      *time.Time  `json:"since,omitempty"`
+	Recordings []recording `json:"recordings"`
+}
+
+// handleRecordings is the admin endpoint for debug recordings: GET lists
+// them, newest first, and POST turns recording on (dropping earlier ones)
+// or off.
+func handleRecordings(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		var req RecordingsRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON body"})
+			return
+		}
+		if req.Size < 0 || req.Size > maxRecordings {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("size must be between 0 and %d", maxRecordings)})
+			return
+		}
+		if !req.Enabled {
+			recordings.Store(nil)
+		} else {
+			size := req.Size
+			if size == 0 {
+				size = defaultRecordings
+			}
+			recordings.Store(&recorder{size: size, since: time.Now().UTC()})
+		}
+		slog.Info("recordings", "enabled", req.Enabled, "size", req.Size)
+	}
+
+	resp := RecordingsResponse{Recordings: []recording{}}
+	if rec := recordings.Load(); rec != nil {
+		resp.Enabled, resp.Size, resp.Since = true, rec.size, &rec.since
+		resp.Recordings = rec.list()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"cli-config-linter/internal/settings"
+	"cli-config-linter/linter"
+	"cli-config-linter/rulepack"
+)
+
+// ruleSet is an immutable snapshot of the active rule configuration.
+// Handlers load it once per request, so a reload never changes the rules
+// halfway through a lint.
+type ruleSet struct {
+	Options  linter.Options `json:"-"`
+	Packs    []string       `json:"rulePacks"`
+	Hash     string         `json:"ruleConfigHash"`
+	LoadedAt time.Time      `json:"loadedAt"`
+	// PackVersion is rulepack.Version of the loaded packs.
+	PackVersion string `json:"rulePackVersion"`
+}
+
+var activeRules atomic.Pointer[ruleSet]
+
+func init() {
+	activeRules.Store(newRuleSet(linter.Options{}, nil))
+}
+
+func newRuleSet(opts linter.Options, packs []*rulepack.Pack) *ruleSet {
+	names := make([]string, 0, len(packs))
+	for _, pack := range packs {
+		names = append(names, pack.Name+"@"+pack.Version)
+	}
+	return &ruleSet{Options: opts, Packs: names, Hash: opts.Hash(), LoadedAt: time.Now().UTC(), PackVersion: rulepack.Version(packs)}
+}
+
+// loadRuleSet reads the rc file, fetches its rule packs and applies the
+// CONFIG_LINTER_* environment overrides, the same way the CLI does.
+func loadRuleSet(ctx context.Context, path string) (*ruleSet, error) {
+	s, err := settings.Resolve(ctx, settings.Flags{RCPath: path}, os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	return newRuleSet(s.Options, s.Packs), nil
+}
+
+// reloadRules swaps in the rc file's current rules. On error the active
+// rules are left untouched.
+func reloadRules(ctx context.Context, path string) (*ruleSet, error) {
+	next, err := loadRuleSet(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	prev := activeRules.Swap(next)
+	if prev.Hash != next.Hash {
+		engine.Invalidate(prev.Options)
+	}
+	return next, nil
+}
+
+// handleReload is the admin endpoint behind CONFIG_LINTER_ADMIN_KEY.
+func handleReload(path string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rules, err := reloadRules(r.Context(), path)
+		if err != nil {
+			writeJSON(w, http.StatusBadGateway, ErrorResponse{Error: "Reload failed: " + err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, rules)
+	}
+}
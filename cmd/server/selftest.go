@@ -0,0 +1,81 @@
+package main
+
+import (
+	"embed"
+	"net/http"
+	"slices"
+	"time"
+
+	"cli-config-linter/linter"
+)
+
+//go:embed selftest
+var selftestFixtures embed.FS
+
+// selftestCases are the fixtures GET /v1/selftest lints and the rule IDs
+// each must produce, in report order; known-good fixtures produce none.
+var selftestCases = []struct {
+	name string
+	want []string
+}{
+	{"valid.yaml", nil},
+	{"valid.json", nil},
+	{"invalid.yaml", []string{"META002", "SET003", "FEAT003"}},
+	{"invalid.json", []string{"META003", "SET003", "SET005"}},
+}
+
+type SelftestCase struct {
+	Name     string   `json:"name"`
+	Pass     bool     `json:"pass"`
+	Want     []string `json:"want"`
+	Got      []string `json:"got"`
+	Error    string   `json:"error,omitempty"`
+	Duration string   `json:"duration"`
+}
+
+type SelftestResponse struct {
+	Status   string         `json:"status"`
+	Version  string         `json:"version"`
+	Duration string         `json:"duration"`
+	Cases    []SelftestCase `json:"cases"`
+}
+
+// handleSelftest lints the embedded fixtures with the built-in rules only,
+// so deployment pipelines can check a new image lints correctly before it
+// takes traffic whatever the rc file enables. It answers 503 when any
+// fixture does not produce exactly its expected rule IDs.
+func handleSelftest(w http.ResponseWriter, r *http.Request) {
+	resp := SelftestResponse{Status: "pass", Version: linter.Version}
+	start := time.Now()
+	for _, tc := range selftestCases {
+		c := SelftestCase{Name: tc.name, Want: tc.want, Got: []string{}}
+		if c.Want == nil {
+			c.Want = []string{}
+		}
+		caseStart := time.Now()
+		data, err := selftestFixtures.ReadFile("selftest/" + tc.name)
+		if err == nil {
+			var issues []linter.Issue
+			issues, err = linter.LintNamed(tc.name, data, linter.Options{})
+			for _, issue := range issues {
+				c.Got = append(c.Got, issue.RuleID)
+			}
+		}
+		c.Duration = time.Since(caseStart).String()
+		if err != nil {
+			c.Error = err.Error()
+		}
+		c.Pass = err == nil && slices.Equal(c.Got, c.Want)
+		if !c.Pass {
+			resp.Status = "fail"
+		}
+		resp.Cases = append(resp.Cases, c)
+	}
+	resp.Duration = time.Since(start).String()
+
+	status := http.StatusOK
+	if resp.Status != "pass" {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, resp)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"cli-config-linter/linter"
+)
+
+const (
+	slackMaxBody       = 64 * 1024
+	slackMaxSkew       = 5 * time.Minute
+	slackMaxIssueLines = 20
+)
+
+type SlackResponse struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+// handleSlack answers Slack slash commands. The command text is either a
+// config snippet (optionally wrapped in a code fence) or a URL to fetch.
+func handleSlack(signingSecret string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, slackMaxBody+1))
+		if err != nil || len(body) > slackMaxBody {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid request body"})
+			return
+		}
+
+		if !verifySlackSignature(signingSecret, r.Header, body, time.Now()) {
+			slog.Warn("slack_signature_invalid", "ip", r.RemoteAddr)
+			writeJSON(w, http.StatusUnauthorized, ErrorResponse{Error: "Invalid Slack signature"})
+			return
+		}
+
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid form payload"})
+			return
+		}
+
+		text := strings.TrimSpace(form.Get("text"))
+		if text == "" {
+			writeJSON(w, http.StatusOK, SlackResponse{
+				ResponseType: "ephemeral",
+				Text:         fmt.Sprintf("Usage: `%s <config snippet or URL>`", form.Get("command")),
+			})
+			return
+		}
+
+		config := unwrapSlackText(text)
+		if isURL(config) {
+			fetched, err := fetchConfig(r.Context(), config)
+			if err != nil {
+				writeJSON(w, http.StatusOK, SlackResponse{ResponseType: "ephemeral", Text: "Could not fetch config: " + err.Error()})
+				return
+			}
+			config = fetched
+		}
+
+		issues, err := engine.Lint("", []byte(config), activeRules.Load().Options)
+		var limitErr *linter.LimitError
+		if errors.As(err, &limitErr) {
+			writeJSON(w, http.StatusOK, SlackResponse{ResponseType: "ephemeral", Text: "Config rejected: " + limitErr.Error()})
+			return
+		}
+		if errors.Is(err, linter.ErrNotText) {
+			writeJSON(w, http.StatusOK, SlackResponse{ResponseType: "ephemeral", Text: "Config rejected: " + err.Error()})
+			return
+		}
+		if err != nil {
+			slog.Error("linter_internal_error", "error", err)
+			writeJSON(w, http.StatusOK, SlackResponse{ResponseType: "ephemeral", Text: "Internal linter error"})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, SlackResponse{ResponseType: "ephemeral", Text: formatSlackSummary(issues)})
+	}
+}
+
+// verifySlackSignature checks the v0 request signature Slack computes over
+// the timestamp and raw body, rejecting stale timestamps to stop replays.
+func verifySlackSignature(secret string, header http.Header, body []byte, now time.Time) bool {
+	ts := header.Get("X-Slack-Request-Timestamp")
+	sig := header.Get("X-Slack-Signature")
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return false
+	}
+	if skew := now.Sub(time.Unix(unix, 0)); skew > slackMaxSkew || skew < -slackMaxSkew {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", ts, body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(sig))
+}
+
+// unwrapSlackText strips code fences and Slack's <url|label> link markup.
+func unwrapSlackText(text string) string {
+	if strings.HasPrefix(text, "```") && strings.HasSuffix(text, "```") && len(text) >= 6 {
+		return strings.Trim(text[3:len(text)-3], "\n")
+	}
+	if strings.HasPrefix(text, "<") && strings.HasSuffix(text, ">") {
+		link, _, _ := strings.Cut(text[1:len(text)-1], "|")
+		return link
+	}
+	return text
+}
+
+func isURL(text string) bool {
+	return !strings.ContainsAny(text, " \n") &&
+		(strings.HasPrefix(text, "https://") || strings.HasPrefix(text, "http://"))
+}
+
+func formatSlackSummary(issues []linter.Issue) string {
+	if len(issues) == 0 {
+		return ":white_check_mark: Config looks good, no issues found."
+	}
+
+	errors, warnings, notes := 0, 0, 0
+	for _, issue := range issues {
+		switch issue.Severity {
+		case linter.SeverityError:
+			errors++
+		case linter.SeverityStyle, linter.SeverityInfo, linter.SeverityHint:
+			notes++
+		default:
+			warnings++
+		}
+	}
+
+	var b strings.Builder
+	icon := ":information_source:"
+	if errors > 0 {
+		icon = ":x:"
+	} else if warnings > 0 {
+		icon = ":warning:"
+	}
+	fmt.Fprintf(&b, "%s %d error(s), %d warning(s)", icon, errors, warnings)
+	if notes > 0 {
+		fmt.Fprintf(&b, ", %d note(s)", notes)
+	}
+	b.WriteString("\n")
+	for i, issue := range issues {
+		if i == slackMaxIssueLines {
+			fmt.Fprintf(&b, "_…and %d more_\n", len(issues)-i)
+			break
+		}
+		fmt.Fprintf(&b, "• line %d `%s` %s\n", issue.Line, issue.Severity, issue.Message)
+	}
+	return b.String()
+}
@@ -0,0 +1,83 @@
+// Package goplugin loads rules built as Go plugins, for checks that cannot
+// be published with the linter. A plugin is a main package built with
+// `go build -buildmode=plugin` against the same linter source as the
+// binary; its init functions call linter.Register. Plugins only load on
+// Linux, FreeBSD and macOS builds with cgo.
+package goplugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// opened maps the path of each plugin opened in the process to the sha256
+// of its content when it was opened.
+var opened struct {
+	mu   sync.Mutex
+	sums map[string]string
+}
+
+// Load opens the *.so files in dir in name order, running their init
+// functions, and returns their paths. pins maps file names to the sha256
+// the file must have ("sha256:" is optional); with requirePinned a file
+// without a pin is refused. Files are checked before any is opened, and a
+// plugin already open in the process is not opened again. A plugin cannot
+// be closed, so a reload that finds an open one changed or removed fails
+// until the process restarts.
+func Load(dir string, pins map[string]string, requirePinned bool) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.so"))
+	if err != nil {
+		return nil, err
+	}
+	opened.mu.Lock()
+	defer opened.mu.Unlock()
+	for path := range opened.sums {
+		if filepath.Dir(path) == filepath.Clean(dir) && !slices.Contains(paths, path) {
+			return nil, fmt.Errorf("plugin %s: removed since it was opened; restart to unload it", path)
+		}
+	}
+	sums := make([]string, len(paths))
+	for i, path := range paths {
+		if sums[i], err = verify(path, pins[filepath.Base(path)], requirePinned); err != nil {
+			return nil, err
+		}
+		if prev, ok := opened.sums[path]; ok && prev != sums[i] {
+			return nil, fmt.Errorf("plugin %s: changed since it was opened; restart to load the new version", path)
+		}
+	}
+	if opened.sums == nil {
+		opened.sums = make(map[string]string)
+	}
+	for i, path := range paths {
+		if _, err := plugin.Open(path); err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", path, err)
+		}
+		opened.sums[path] = sums[i]
+	}
+	return paths, nil
+}
+
+// verify checks path against its pin and returns the sha256 of its content.
+func verify(path, pin string, requirePinned bool) (string, error) {
+	want := strings.ToLower(strings.TrimPrefix(pin, "sha256:"))
+	if want == "" && requirePinned {
+		return "", fmt.Errorf("plugin %s: not pinned with sha256, which the rule pack policy requires", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("plugin %s: %w", path, err)
+	}
+	sum := sha256.Sum256(data)
+	got := hex.EncodeToString(sum[:])
+	if want != "" && got != want {
+		return "", fmt.Errorf("plugin %s: checksum mismatch: got sha256:%s, want sha256:%s", path, got, want)
+	}
+	return got, nil
+}
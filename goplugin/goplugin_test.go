@@ -0,0 +1,57 @@
+package goplugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadChecksPinsBeforeOpening(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "compliance.so"), []byte("not a plugin"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(dir, nil, true); err == nil || !strings.Contains(err.Error(), "not pinned") {
+		t.Errorf("expected an unpinned plugin to be refused, got %v", err)
+	}
+	wrong := map[string]string{"compliance.so": "sha256:" + strings.Repeat("0", 64)}
+	if _, err := Load(dir, wrong, false); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected a pin mismatch, got %v", err)
+	}
+	// The pin matches; the file is then opened, and is not a plugin.
+	sum := sha256.Sum256([]byte("not a plugin"))
+	right := map[string]string{"compliance.so": hex.EncodeToString(sum[:])}
+	if _, err := Load(dir, right, true); err == nil || strings.Contains(err.Error(), "checksum") {
+		t.Errorf("expected the pinned file to be opened and rejected as a plugin, got %v", err)
+	}
+
+	if paths, err := Load(t.TempDir(), nil, true); err != nil || len(paths) != 0 {
+		t.Errorf("expected an empty directory to load nothing, got %v %v", paths, err)
+	}
+}
+
+func TestReloadRefusesChangedPlugins(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "compliance.so")
+	if err := os.WriteFile(path, []byte("version 2"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// As if version 1 had been opened by an earlier load.
+	sum := sha256.Sum256([]byte("version 1"))
+	opened.sums = map[string]string{path: hex.EncodeToString(sum[:])}
+	defer func() { opened.sums = nil }()
+
+	if _, err := Load(dir, nil, false); err == nil || !strings.Contains(err.Error(), "changed since it was opened") {
+		t.Errorf("expected a changed plugin to fail the reload, got %v", err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir, nil, false); err == nil || !strings.Contains(err.Error(), "removed since it was opened") {
+		t.Errorf("expected a removed plugin to fail the reload, got %v", err)
+	}
+}
@@ -0,0 +1,238 @@
+// Package settings resolves the lint options the CLI and the server run
+// with. Each setting comes from one of four layers, lowest precedence
+// first: built-in defaults, the rc file (and its rule packs), environment
+// variables and command-line flags.
+package settings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"cli-config-linter/goplugin"
+	"cli-config-linter/linter"
+	"cli-config-linter/rcfile"
+	"cli-config-linter/rulepack"
+	"cli-config-linter/wasmrule"
+)
+
+// Environment variables read by Resolve.
+const (
+	EnvRC        = "CONFIG_LINTER_RC"
+	EnvProfiles  = "CONFIG_LINTER_PROFILES"
+	EnvStyle     = "CONFIG_LINTER_STYLE"
+	EnvRedact    = "CONFIG_LINTER_REDACT"
+	EnvNoPlugins = "CONFIG_LINTER_NO_PLUGINS"
+	// EnvPlugins is the directory Go and WebAssembly plugins are loaded
+	// from.
+	EnvPlugins = "CONFIG_LINTER_PLUGINS"
+	// EnvDisabledRules and EnvEnabledRules are comma-separated rule IDs.
+	EnvDisabledRules = "CONFIG_LINTER_DISABLED_RULES"
+	EnvEnabledRules  = "CONFIG_LINTER_ENABLED_RULES"
+	// EnvEnvironments is the comma-separated values metadata.env may have.
+	EnvEnvironments = "CONFIG_LINTER_ENVIRONMENTS"
+)
+
+// Origin names the layer a setting came from: "default", "rc file", "env
+// $NAME", "flag -name" or "rule pack name@version".
+type Origin string
+
+const Default Origin = "default"
+
+// Flags are the command-line values; zero values are unset.
+type Flags struct {
+	RCPath   string
+	Profiles string // comma-separated
+	Style    bool
+	Redact   bool
+	// NoPlugins skips every rule pack, listed or discovered, and every
+	// plugin.
+	NoPlugins bool
+	// PluginDir is the directory Go and WebAssembly plugins are loaded
+	// from.
+	PluginDir string
+	// DisabledRules and EnabledRules are comma-separated rule IDs.
+	DisabledRules string
+	EnabledRules  string
+	// Environments is the comma-separated values metadata.env may have.
+	Environments string
+}
+
+// Settings is the resolved configuration.
+type Settings struct {
+	// RCPath is the rc file read, if any.
+	RCPath  string           `json:"rcPath,omitempty"`
+	Options linter.Options   `json:"options"`
+	Packs   []*rulepack.Pack `json:"-"`
+	// Plugins are the Go and WebAssembly plugins loaded, by path.
+	Plugins []string `json:"plugins,omitempty"`
+	// Origins maps each setting that is not a default, by its rc file key
+	// ("rc" for the rc file itself), to the layer that set it.
+	Origins map[string]Origin `json:"origins"`
+}
+
+// RCPath picks the rc file: the -rc flag, then $CONFIG_LINTER_RC, then
+// .configlintrc.json in the working directory if it exists.
+func RCPath(flag string, getenv func(string) string) (string, Origin) {
+	if flag != "" {
+		return flag, "flag -rc"
+	}
+	if path := getenv(EnvRC); path != "" {
+		return path, "env $" + EnvRC
+	}
+	if _, err := os.Stat(rcfile.DefaultName); err == nil {
+		return rcfile.DefaultName, Default
+	}
+	return "", Default
+}
+
+// Resolve layers the rc file, the environment (read through getenv) and
+// flags into the options to lint with.
+func Resolve(ctx context.Context, flags Flags, getenv func(string) string) (*Settings, error) {
+	s := &Settings{Origins: make(map[string]Origin)}
+	off, err := noPlugins(flags, getenv)
+	if err != nil {
+		return nil, err
+	}
+	var origin Origin
+	var pins map[string]string
+	var requirePinned bool
+	s.RCPath, origin = RCPath(flags.RCPath, getenv)
+	if s.RCPath != "" {
+		s.Origins["rc"] = origin
+		rc, err := rcfile.Load(s.RCPath)
+		if err != nil {
+			return nil, err
+		}
+		pins, requirePinned = rc.Plugins, rc.RulePackPolicy.RequirePinned
+		if off != "" {
+			rc.RulePacks, rc.Dir = nil, ""
+			s.Origins["rulePacks"] = off
+		}
+		s.Options, s.Packs, err = rc.Resolve(ctx, rulepack.NewFetcher(rulepack.DefaultCacheDir()))
+		if err != nil {
+			return nil, err
+		}
+		for _, pack := range s.Packs {
+			for _, key := range setKeys(pack.Options) {
+				s.Origins[key] = Origin("rule pack " + pack.Name + "@" + pack.Version)
+			}
+		}
+		for _, key := range setKeys(rc.Options) {
+			s.Origins[key] = "rc file"
+		}
+	}
+
+	// Lists from the environment and from flags are layers merged over the
+	// rc file, so enabling a rule there overrides a rule pack disabling it.
+	lists := []struct {
+		key, env, flag, value string
+		field                 func(o *linter.Options) *[]string
+	}{
+		{"profiles", EnvProfiles, "profile", flags.Profiles, func(o *linter.Options) *[]string { return &o.Profiles }},
+		{"disabledRules", EnvDisabledRules, "disable-rules", flags.DisabledRules, func(o *linter.Options) *[]string { return &o.DisabledRules }},
+		{"enabledRules", EnvEnabledRules, "enable-rules", flags.EnabledRules, func(o *linter.Options) *[]string { return &o.EnabledRules }},
+		{"environments", EnvEnvironments, "environments", flags.Environments, func(o *linter.Options) *[]string { return &o.Environments }},
+	}
+	var envLayer linter.Options
+	for _, l := range lists {
+		if v := getenv(l.env); v != "" {
+			*l.field(&envLayer) = strings.Split(v, ",")
+			s.Origins[l.key] = Origin("env $" + l.env)
+		}
+	}
+	s.Options = s.Options.Merge(envLayer)
+	for _, b := range []struct {
+		key, env string
+		field    *bool
+	}{{"style", EnvStyle, &s.Options.Style}, {"redact", EnvRedact, &s.Options.Redact}} {
+		v := getenv(b.env)
+		if v == "" {
+			continue
+		}
+		on, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("$%s: %w", b.env, err)
+		}
+		*b.field = on
+		s.Origins[b.key] = Origin("env $" + b.env)
+	}
+
+	var flagLayer linter.Options
+	for _, l := range lists {
+		if l.value != "" {
+			*l.field(&flagLayer) = strings.Split(l.value, ",")
+			s.Origins[l.key] = Origin("flag -" + l.flag)
+		}
+	}
+	s.Options = s.Options.Merge(flagLayer)
+	if flags.Style {
+		s.Options.Style = true
+		s.Origins["style"] = "flag -style"
+	}
+	if flags.Redact {
+		s.Options.Redact = true
+		s.Origins["redact"] = "flag -redact"
+	}
+	if err := s.Options.Validate(); err != nil {
+		return nil, err
+	}
+
+	dir, dirOrigin := flags.PluginDir, Origin("flag -plugins")
+	if dir == "" {
+		dir, dirOrigin = getenv(EnvPlugins), Origin("env $"+EnvPlugins)
+	}
+	switch {
+	case dir != "" && off != "":
+		s.Origins["plugins"] = off
+	case dir != "":
+		if s.Plugins, err = goplugin.Load(dir, pins, requirePinned); err != nil {
+			return nil, err
+		}
+		wasm, err := wasmrule.Load(dir, pins, requirePinned)
+		if err != nil {
+			return nil, err
+		}
+		s.Plugins = append(s.Plugins, wasm...)
+		s.Origins["plugins"] = dirOrigin
+	}
+	return s, nil
+}
+
+// noPlugins returns the layer that turned rule packs off, if any.
+func noPlugins(flags Flags, getenv func(string) string) (Origin, error) {
+	if flags.NoPlugins {
+		return "flag -no-plugins", nil
+	}
+	if v := getenv(EnvNoPlugins); v != "" {
+		off, err := strconv.ParseBool(v)
+		if err != nil {
+			return "", fmt.Errorf("$%s: %w", EnvNoPlugins, err)
+		}
+		if off {
+			return Origin("env $" + EnvNoPlugins), nil
+		}
+	}
+	return "", nil
+}
+
+// setKeys lists the rc file keys opts sets to something other than their
+// zero value.
+func setKeys(opts linter.Options) []string {
+	var set, zero map[string]json.RawMessage
+	data, _ := json.Marshal(opts)
+	json.Unmarshal(data, &set)
+	data, _ = json.Marshal(linter.Options{})
+	json.Unmarshal(data, &zero)
+	var keys []string
+	for key, value := range set {
+		if !bytes.Equal(value, zero[key]) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
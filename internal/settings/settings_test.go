@@ -0,0 +1,156 @@
+package settings
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResolvePrecedence(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), "rc.json")
+	if err := os.WriteFile(rc, []byte(`{"environments": ["qa", "prod"], "style": true, "profiles": ["backstage"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{EnvRC: rc, EnvStyle: "false", EnvRedact: "true"}
+	getenv := func(key string) string { return env[key] }
+
+	s, err := Resolve(context.Background(), Flags{Redact: true}, getenv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.RCPath != rc || !reflect.DeepEqual(s.Options.Environments, []string{"qa", "prod"}) {
+		t.Errorf("expected the rc file from the environment, got %+v", s)
+	}
+	if s.Options.Style || !s.Options.Redact {
+		t.Errorf("expected style off from the environment and redact on, got %+v", s.Options)
+	}
+	want := map[string]Origin{
+		"rc":           "env $" + EnvRC,
+		"environments": "rc file",
+		"profiles":     "rc file",
+		"style":        "env $" + EnvStyle,
+		"redact":       "flag -redact",
+	}
+	if !reflect.DeepEqual(s.Origins, want) {
+		t.Errorf("expected origins %v, got %v", want, s.Origins)
+	}
+
+	s, err = Resolve(context.Background(), Flags{RCPath: rc, Profiles: "prod"}, func(string) string { return "" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Options.Style || !reflect.DeepEqual(s.Options.Profiles, []string{"prod"}) || s.Origins["rc"] != "flag -rc" {
+		t.Errorf("expected the rc file's style and the flag's profiles, got %+v", s)
+	}
+
+	env[EnvEnvironments] = "dev,qa"
+	s, err = Resolve(context.Background(), Flags{}, getenv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s.Options.Environments, []string{"dev", "qa"}) || s.Origins["environments"] != "env $"+EnvEnvironments {
+		t.Errorf("expected the environments from the environment, got %+v", s)
+	}
+	s, err = Resolve(context.Background(), Flags{Environments: "prod"}, getenv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s.Options.Environments, []string{"prod"}) || s.Origins["environments"] != "flag -environments" {
+		t.Errorf("expected the flag's environments, got %+v", s)
+	}
+
+	env[EnvStyle] = "maybe"
+	if _, err := Resolve(context.Background(), Flags{}, getenv); err == nil {
+		t.Error("expected an error for an unreadable boolean")
+	}
+}
+
+func TestResolveDiscoversRulePacks(t *testing.T) {
+	dir := t.TempDir()
+	rc := filepath.Join(dir, "rc.json")
+	if err := os.WriteFile(rc, []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	packs := filepath.Join(dir, ".configlint", "rulepacks")
+	if err := os.MkdirAll(packs, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(packs, "local.json"), []byte(`{"name": "local", "version": "1", "options": {"environments": ["qa"]}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	noEnv := func(string) string { return "" }
+
+	s, err := Resolve(context.Background(), Flags{RCPath: rc}, noEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Packs) != 1 || s.Origins["environments"] != "rule pack local@1" {
+		t.Errorf("expected the discovered pack, got %+v", s)
+	}
+
+	s, err = Resolve(context.Background(), Flags{RCPath: rc, NoPlugins: true}, noEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Packs) != 0 || s.Options.Environments != nil || s.Origins["rulePacks"] != "flag -no-plugins" {
+		t.Errorf("expected no packs with -no-plugins, got %+v", s)
+	}
+
+	if err := os.WriteFile(rc, []byte(`{"rulePackPolicy": {"requirePinned": true}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Resolve(context.Background(), Flags{RCPath: rc}, noEnv); err == nil {
+		t.Error("expected the unpinned discovered pack to be rejected")
+	}
+}
+
+func TestResolvePlugins(t *testing.T) {
+	dir := t.TempDir()
+	plugins := filepath.Join(dir, "plugins")
+	if err := os.MkdirAll(plugins, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(plugins, "compliance.so"), []byte("not a plugin"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rc := filepath.Join(dir, "rc.json")
+	if err := os.WriteFile(rc, []byte(`{"plugins": {"compliance.so": "sha256:0000"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	env := func(name string) string {
+		if name == EnvPlugins {
+			return plugins
+		}
+		return ""
+	}
+
+	if _, err := Resolve(context.Background(), Flags{RCPath: rc}, env); err == nil {
+		t.Error("expected the rc file's pin to be checked")
+	}
+	s, err := Resolve(context.Background(), Flags{RCPath: rc, NoPlugins: true}, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Plugins) != 0 || s.Origins["plugins"] != "flag -no-plugins" {
+		t.Errorf("expected no plugins with -no-plugins, got %+v", s)
+	}
+}
+
+func TestResolveEnableOverridesLowerDisable(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), "rc.json")
+	if err := os.WriteFile(rc, []byte(`{"disabledRules": ["SET004", "SET005"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Resolve(context.Background(), Flags{RCPath: rc, EnabledRules: "SET004"}, func(string) string { return "" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Options.Validate(); err != nil {
+		t.Fatalf("expected -enable-rules to override the rc file's disabledRules, got %v", err)
+	}
+	if !reflect.DeepEqual(s.Options.DisabledRules, []string{"SET005"}) || !reflect.DeepEqual(s.Options.EnabledRules, []string{"SET004"}) {
+		t.Errorf("expected SET004 enabled and SET005 still disabled, got %+v", s.Options)
+	}
+}
@@ -0,0 +1,42 @@
+package linter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// benchCorpus builds a config of roughly lines lines, mostly feature
+// entries, resembling the generated configs in large monorepos.
+func benchCorpus(lines int) []byte {
+	var b strings.Builder
+	b.WriteString("# generated\nmetadata:\n  name: payments-api\n  env: prod\nsettings:\n  replicas: 3\n  timeout: 30\nfeatures:\n")
+	for i := 0; i*4 < lines; i++ {
+		fmt.Fprintf(&b, "  - name: feature-%d\n    enabled: true\n    owner: \"team-%d\"\n\n", i, i%50)
+	}
+	return []byte(b.String())
+}
+
+func BenchmarkParseConfig(b *testing.B) {
+	data := benchCorpus(100_000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := parseConfig(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLintBytes(b *testing.B) {
+	data := benchCorpus(100_000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := LintBytes(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
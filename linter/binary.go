@@ -0,0 +1,39 @@
+package linter
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// ErrNotText is returned, wrapped with the reason, for input that is binary
+// rather than a text config. Linting such input would only produce
+// nonsense findings.
+var ErrNotText = errors.New("not a text config")
+
+// textSniff is how much of the input checkText looks at.
+const textSniff = 8 << 10
+
+// checkText reports ErrNotText when head, the start of an input, holds a NUL
+// byte or when more than a tenth of it is not valid UTF-8.
+func checkText(head []byte) error {
+	invalid, runes := 0, 0
+	for i := 0; i < len(head); {
+		if head[i] == 0 {
+			return fmt.Errorf("%w: NUL byte at offset %d", ErrNotText, i)
+		}
+		if len(head) == textSniff && !utf8.FullRune(head[i:]) {
+			break // cut off by the sniff window
+		}
+		r, size := utf8.DecodeRune(head[i:])
+		if r == utf8.RuneError {
+			invalid++
+		}
+		runes++
+		i += size
+	}
+	if invalid*10 > runes {
+		return fmt.Errorf("%w: %d of the first %d characters are not valid UTF-8", ErrNotText, invalid, runes)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package linter
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"sync"
+	"sync/atomic"
+)
+
+// maxCachedOptions bounds a Linter's cache; a server sees one entry per
+// distinct tenant configuration.
+const maxCachedOptions = 256
+
+// Linter lints with state derived from Options (validation result, variable
+// sets, JSON Schema) compiled once and cached by Options.Hash, so servers do
+// not rebuild it per request. Call Invalidate when a tenant's configuration
+// changes. Use NewLinter to create one; it is safe for concurrent use.
+type Linter struct {
+	mu     sync.Mutex
+	cache  map[string]*compiledOptions
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// CacheStats is a snapshot of a Linter's cache counters.
+type CacheStats struct {
+	Entries int   `json:"entries"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+}
+
+type compiledOptions struct {
+	err          error
+	tfVars       map[string]struct{}
+	ownerPattern *regexp.Regexp
+	patterns     map[string]*regexp.Regexp
+	exprs        map[string]*compiledExpr
+	jsonSchema   *jsonSchema
+	cue          *sync.Pool
+
+	schemaOnce sync.Once
+	schema     map[string]any
+}
+
+func NewLinter() *Linter {
+	return &Linter{cache: make(map[string]*compiledOptions)}
+}
+
+// Lint is LintNamed with opts compiled through the cache.
+func (l *Linter) Lint(name string, data []byte, opts Options) ([]Issue, error) {
+	c := l.compile(opts)
+	if c.err != nil {
+		return nil, c.err
+	}
+	opts.compiled = c
+	return LintNamed(name, data, opts)
+}
+
+// LintReader is LintReader with opts compiled through the cache.
+func (l *Linter) LintReader(r io.Reader, opts Options) ([]Issue, error) {
+	return l.LintReaderContext(context.Background(), r, opts)
+}
+
+// LintReaderContext is LintReaderContext with opts compiled through the
+// cache.
+func (l *Linter) LintReaderContext(ctx context.Context, r io.Reader, opts Options) ([]Issue, error) {
+	c := l.compile(opts)
+	if c.err != nil {
+		return nil, c.err
+	}
+	opts.compiled = c
+	return LintReaderContext(ctx, r, opts)
+}
+
+// Schema is JSONSchema for opts, built once per configuration. Callers must
+// not modify the result.
+func (l *Linter) Schema(opts Options) (map[string]any, error) {
+	c := l.compile(opts)
+	if c.err != nil {
+		return nil, c.err
+	}
+	c.schemaOnce.Do(func() { c.schema = JSONSchema(opts) })
+	return c.schema, nil
+}
+
+// Options returns opts with its compiled state attached, for the
+// package-level functions (LintIncludes, Fix, LintOverlays and the rest):
+// given the result they reuse it instead of compiling opts on every call.
+// Options changed afterwards must be passed through Options again.
+func (l *Linter) Options(opts Options) (Options, error) {
+	c := l.compile(opts)
+	if c.err != nil {
+		return opts, c.err
+	}
+	opts.compiled = c
+	return opts, nil
+}
+
+// Invalidate drops the compiled state for opts.
+func (l *Linter) Invalidate(opts Options) {
+	hash := opts.Hash()
+	l.mu.Lock()
+	delete(l.cache, hash)
+	l.mu.Unlock()
+}
+
+// Reset drops every cached configuration.
+func (l *Linter) Reset() {
+	l.mu.Lock()
+	l.cache = make(map[string]*compiledOptions)
+	l.mu.Unlock()
+}
+
+func (l *Linter) Stats() CacheStats {
+	l.mu.Lock()
+	entries := len(l.cache)
+	l.mu.Unlock()
+	return CacheStats{Entries: entries, Hits: l.hits.Load(), Misses: l.misses.Load()}
+}
+
+func (l *Linter) compile(opts Options) *compiledOptions {
+	hash := opts.Hash()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if c, ok := l.cache[hash]; ok {
+		l.hits.Add(1)
+		return c
+	}
+	l.misses.Add(1)
+
+	c := &compiledOptions{err: opts.Validate()}
+	if len(opts.TerraformVariables) > 0 {
+		c.tfVars = make(map[string]struct{}, len(opts.TerraformVariables))
+		for _, name := range opts.TerraformVariables {
+			c.tfVars[name] = struct{}{}
+		}
+	}
+
+	if c.err == nil && opts.Governance.OwnerPattern != "" {
+		c.ownerPattern = regexp.MustCompile(opts.Governance.OwnerPattern)
+	}
+	if c.err == nil && len(opts.Patterns) > 0 {
+		c.patterns, _ = compilePatterns(opts.Patterns)
+	}
+	if c.err == nil && len(opts.ExpressionRules) > 0 {
+		c.exprs = compileExpressions(opts.ExpressionRules)
+	}
+	if c.err == nil && len(opts.Schema) > 0 {
+		c.jsonSchema, _ = compileSchema(opts.Schema)
+	}
+	if c.err == nil && opts.CUESchema != "" {
+		c.cue = newCUEPool(opts)
+	}
+
+	if len(l.cache) >= maxCachedOptions {
+		for key := range l.cache {
+			delete(l.cache, key)
+			break
+		}
+	}
+	l.cache[hash] = c
+	return c
+}
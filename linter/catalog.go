@@ -0,0 +1,141 @@
+package linter
+
+import "strings"
+
+// RuleInfo describes a rule for listings and generated documentation.
+type RuleInfo struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	// Severity is the severity the rule reports with unless
+	// Options.Severities overrides it. Rules reporting several severities
+	// list the most severe.
+	Severity Severity `json:"severity"`
+	// Category groups rules by what they check: a section of the config,
+	// a file format, or a feature of the linter such as suppressions.
+	Category string `json:"category"`
+	DocsURL  string `json:"docsUrl"`
+}
+
+// RuleDocsBase is the page documenting every rule; each rule's DocsURL is
+// its section of it. Programs publishing their own documentation can
+// point it elsewhere before calling Rules.
+var RuleDocsBase = "https://github.com/clyvecute/cli-configurator/blob/main/docs/rules.md"
+
+// CategoryCustom is the category of rules added with Register.
+const CategoryCustom = "custom"
+
+// ruleCatalog lists the built-in rules by category, then ID.
+var ruleCatalog = []RuleInfo{
+	{ID: "SYN001", Severity: SeverityError, Category: "syntax", Description: "The YAML or JSON document cannot be parsed"},
+	{ID: "ENC001", Severity: SeverityInfo, Category: "syntax", Description: "The file is in a text encoding other than UTF-8 and was converted"},
+	{ID: "TYPE001", Severity: SeverityWarning, Category: "syntax", Description: "Unquoted YAML value whose type depends on the YAML version of the reader, such as NO or 012"},
+	{ID: "LOC001", Severity: SeverityWarning, Category: "syntax", Description: "Setting written as a locale-formatted number"},
+	{ID: "LOC002", Severity: SeverityWarning, Category: "syntax", Description: "Setting written as a date whose day and month order is ambiguous"},
+
+	{ID: "META001", Severity: SeverityError, Category: "metadata", Description: "The metadata section is missing"},
+	{ID: "META002", Severity: SeverityError, Category: "metadata", Description: "metadata.name is missing"},
+	{ID: "META003", Severity: SeverityError, Category: "metadata", Description: "metadata.env is missing"},
+	{ID: "META004", Severity: SeverityWarning, Category: "metadata", Description: "metadata.env is not a known environment"},
+
+	{ID: "SET001", Severity: SeverityError, Category: "settings", Description: "The settings section is missing"},
+	{ID: "SET002", Severity: SeverityError, Category: "settings", Description: "settings.replicas is missing"},
+	{ID: "SET003", Severity: SeverityError, Category: "settings", Description: "settings.replicas is not a positive integer"},
+	{ID: "SET004", Severity: SeverityWarning, Category: "settings", Description: "settings.timeout is missing and takes the default timeout"},
+	{ID: "SET005", Severity: SeverityWarning, Category: "settings", Description: "settings.timeout is not a positive integer"},
+	{ID: "DEF001", Severity: SeverityInfo, Category: "settings", Description: "Key with a declared default is not set and will take the default"},
+
+	{ID: "FEAT001", Severity: SeverityWarning, Category: "features", Description: "Feature entry is not a mapping"},
+	{ID: "FEAT002", Severity: SeverityWarning, Category: "features", Description: "Feature entry has no name"},
+	{ID: "FEAT003", Severity: SeverityWarning, Category: "features", Description: "Feature enabled is not true or false"},
+	{ID: "FEAT004", Severity: SeverityError, Category: "features", Description: "Feature requires a feature that does not exist"},
+	{ID: "FEAT005", Severity: SeverityError, Category: "features", Description: "Enabled feature requires a disabled feature"},
+	{ID: "FEAT006", Severity: SeverityError, Category: "features", Description: "Feature requirements form a cycle"},
+	{ID: "FEAT007", Severity: SeverityError, Category: "features", Description: "Feature rollout is not a percentage from 0 to 100"},
+	{ID: "FEAT008", Severity: SeverityWarning, Category: "features", Description: "Disabled feature has a rollout above 0"},
+	{ID: "FEAT009", Severity: SeverityError, Category: "features", Description: "Prod feature rollout is above the approved cap"},
+
+	{ID: "GOV001", Severity: SeverityError, Category: "governance", Description: "metadata.owner is missing or not an email address or team handle"},
+	{ID: "GOV002", Severity: SeverityError, Category: "governance", Description: "metadata.repository is missing or not an http(s) URL"},
+	{ID: "GOV003", Severity: SeverityError, Category: "governance", Description: "metadata.labels lacks a required label"},
+	{ID: "REV001", Severity: SeverityWarning, Category: "governance", Description: "metadata.lastReviewed is older than the review window"},
+	{ID: "REV002", Severity: SeverityWarning, Category: "governance", Description: "metadata.lastReviewed cannot be read or lies in the future"},
+
+	{ID: "KEY001", Severity: SeverityWarning, Category: "keys", Description: "Key the application does not read"},
+	{ID: "KEY002", Severity: SeverityWarning, Category: "keys", Description: "Key the application reads is not set"},
+	{ID: "KEY003", Severity: SeverityWarning, Category: "keys", Description: "Key outside the known schema, such as a misspelling"},
+	{ID: "SCHEMA001", Severity: SeverityError, Category: "schema", Description: "The document does not match the JSON Schema in the schema option"},
+	{ID: "CUE001", Severity: SeverityError, Category: "schema", Description: "The document does not satisfy the CUE schema in the cueSchema option"},
+	{ID: "SEC001", Severity: SeverityError, Category: "schema", Description: "A section the section schemas require is missing"},
+	{ID: "SEC002", Severity: SeverityError, Category: "schema", Description: "A key the section schemas require is missing"},
+	{ID: "SEC003", Severity: SeverityError, Category: "schema", Description: "Value is not of the type the section schemas declare"},
+	{ID: "SEC004", Severity: SeverityError, Category: "schema", Description: "Number is outside the range the section schemas declare"},
+	{ID: "SEC005", Severity: SeverityError, Category: "schema", Description: "Value is not one of those the section schemas allow"},
+	{ID: "PAT001", Severity: SeverityError, Category: "schema", Description: "Value does not match the pattern declared for its key"},
+	{ID: "ENUM001", Severity: SeverityWarning, Category: "schema", Description: "Value is not one of those declared for its key"},
+
+	{ID: "TPL001", Severity: SeverityError, Category: "templates", Description: "Placeholder for a variable that is not declared"},
+	{ID: "TPL002", Severity: SeverityWarning, Category: "templates", Description: "Declared variable no placeholder uses"},
+	{ID: "TPL003", Severity: SeverityError, Category: "templates", Description: "Placeholders nest too deep, expand too far or variables reference each other in a loop"},
+	{ID: "TPL004", Severity: SeverityWarning, Category: "templates", Description: "Placeholder is not closed"},
+
+	{ID: "VAULT001", Severity: SeverityWarning, Category: "secrets", Description: "Malformed vault: reference"},
+	{ID: "VAULT002", Severity: SeverityError, Category: "secrets", Description: "vault: reference to a secret that does not exist"},
+
+	{ID: "INC001", Severity: SeverityError, Category: "includes", Description: "Includes form a cycle"},
+	{ID: "INC002", Severity: SeverityError, Category: "includes", Description: "Includes are nested deeper than the limit"},
+	{ID: "INC003", Severity: SeverityError, Category: "includes", Description: "Included file cannot be loaded"},
+	{ID: "OVL001", Severity: SeverityWarning, Category: "overlays", Description: "Overlay sets a key the base config does not"},
+
+	{ID: "SUP001", Severity: SeverityWarning, Category: "suppressions", Description: "Suppression or baseline entry has expired"},
+	{ID: "SUP002", Severity: SeverityWarning, Category: "suppressions", Description: "Suppression has an invalid until date"},
+	{ID: "SUP003", Severity: SeverityWarning, Category: "suppressions", Description: "Suppression names a renamed or removed rule"},
+
+	{ID: "STY001", Severity: SeverityStyle, Category: "style", Description: "Top-level sections out of metadata, settings, features order"},
+	{ID: "STY002", Severity: SeverityStyle, Category: "style", Description: "Feature entry whose first key is not name"},
+	{ID: "STY003", Severity: SeverityStyle, Category: "style", Description: "YAML value quoted although it reads the same unquoted"},
+
+	{ID: "PROD001", Severity: SeverityError, Category: "profiles", Description: "Prod feature does not set enabled explicitly"},
+	{ID: "BS001", Severity: SeverityError, Category: "profiles", Description: "apiVersion is not a supported Backstage version"},
+	{ID: "BS002", Severity: SeverityError, Category: "profiles", Description: "kind is missing or not a Backstage entity kind"},
+	{ID: "BS003", Severity: SeverityError, Category: "profiles", Description: "metadata.name is missing or not a valid entity name"},
+	{ID: "BS004", Severity: SeverityError, Category: "profiles", Description: "spec or a field its kind requires is missing"},
+
+	{ID: "ENV001", Severity: SeverityError, Category: "dotenv", Description: "Variable is set twice"},
+	{ID: "ENV002", Severity: SeverityWarning, Category: "dotenv", Description: "Variable is empty"},
+	{ID: "ENV003", Severity: SeverityError, Category: "dotenv", Description: "Unquoted value contains spaces"},
+	{ID: "ENV004", Severity: SeverityError, Category: "dotenv", Description: "Value appears to be a plaintext secret"},
+	{ID: "ENV005", Severity: SeverityWarning, Category: "dotenv", Description: "Line is not a NAME=value assignment"},
+
+	{ID: "TF001", Severity: SeverityWarning, Category: "terraform", Description: "Variable name is not snake_case"},
+	{ID: "TF002", Severity: SeverityWarning, Category: "terraform", Description: "Variable is not declared and will be ignored"},
+	{ID: "TF003", Severity: SeverityError, Category: "terraform", Description: "Value appears to be a plaintext secret"},
+	{ID: "TF004", Severity: SeverityError, Category: "terraform", Description: "Variable is assigned twice"},
+
+	{ID: "INI001", Severity: SeverityError, Category: "ini", Description: "Malformed section header"},
+	{ID: "INI002", Severity: SeverityWarning, Category: "ini", Description: "Line is not a key = value pair"},
+	{ID: "PROP001", Severity: SeverityError, Category: "properties", Description: "Property key has an invalid escape"},
+	{ID: "PROP002", Severity: SeverityWarning, Category: "properties", Description: "Property key has an empty path segment"},
+	{ID: "XML001", Severity: SeverityError, Category: "xml", Description: "The XML document cannot be parsed"},
+
+	{ID: "INT001", Severity: SeverityError, Category: "internal", Description: "A check panicked; its other findings for the document are dropped"},
+}
+
+// Rules describes the built-in rules, grouped by category, followed by the
+// registered ones in registration order. Registered rules have
+// CategoryCustom and no description.
+func Rules() []RuleInfo {
+	rules := make([]RuleInfo, 0, len(ruleCatalog))
+	for _, info := range ruleCatalog {
+		info.DocsURL = ruleDocsURL(info.ID)
+		rules = append(rules, info)
+	}
+	for _, r := range RegisteredRules() {
+		rules = append(rules, RuleInfo{ID: r.ID(), Severity: r.Severity(), Category: CategoryCustom})
+	}
+	return rules
+}
+
+// ruleDocsURL links to the section of RuleDocsBase headed by the rule ID.
+func ruleDocsURL(id string) string {
+	return RuleDocsBase + "#" + strings.ToLower(id)
+}
@@ -0,0 +1,81 @@
+package linter
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+)
+
+// changelog.json records every rule that was renamed or removed, so rule IDs
+// written into suppressions and baselines keep working or get a clear
+// warning after an upgrade.
+//
+//go:embed changelog.json
+var changelogData []byte
+
+// Kinds of RuleChange.
+const (
+	RuleRenamed = "renamed"
+	RuleRemoved = "removed"
+)
+
+// RuleChange is one entry of the rule changelog.
+type RuleChange struct {
+	RuleID string `json:"ruleId"`
+	// Version is the engine version the change shipped in.
+	Version     string `json:"version"`
+	Change      string `json:"change"`
+	Replacement string `json:"replacement,omitempty"`
+	Note        string `json:"note,omitempty"`
+}
+
+// ruleChanges is the parsed changelog; tests replace it.
+var ruleChanges = mustParseChangelog(changelogData)
+
+func mustParseChangelog(data []byte) []RuleChange {
+	var changes []RuleChange
+	if err := json.Unmarshal(data, &changes); err != nil {
+		panic(fmt.Sprintf("linter: bad embedded rule changelog: %v", err))
+	}
+	return changes
+}
+
+// RuleChangelog returns the rule changelog, oldest first.
+func RuleChangelog() []RuleChange {
+	return append([]RuleChange{}, ruleChanges...)
+}
+
+// RuleDeprecation reports whether id was renamed or removed, returning the
+// latest change to it.
+func RuleDeprecation(id string) (RuleChange, bool) {
+	for i := len(ruleChanges) - 1; i >= 0; i-- {
+		if c := ruleChanges[i]; c.RuleID == id && (c.Change == RuleRenamed || c.Change == RuleRemoved) {
+			return c, true
+		}
+	}
+	return RuleChange{}, false
+}
+
+// CurrentRuleID follows renames from id to the rule's current ID.
+func CurrentRuleID(id string) string {
+	for seen := 0; seen <= len(ruleChanges); seen++ {
+		c, ok := RuleDeprecation(id)
+		if !ok || c.Change != RuleRenamed || c.Replacement == "" {
+			return id
+		}
+		id = c.Replacement
+	}
+	return id
+}
+
+// Describe phrases the change for a deprecation warning.
+func (c RuleChange) Describe() string {
+	if c.Change == RuleRenamed {
+		return fmt.Sprintf("rule %s was renamed to %s in %s", c.RuleID, c.Replacement, c.Version)
+	}
+	msg := fmt.Sprintf("rule %s was removed in %s", c.RuleID, c.Version)
+	if c.Replacement != "" {
+		msg += "; use " + c.Replacement
+	}
+	return msg
+}
@@ -0,0 +1,78 @@
+package linter
+
+import (
+	"slices"
+	"strings"
+)
+
+// Completion is a key an editor can offer at a path.
+type Completion struct {
+	Key      string `json:"key"`
+	Type     string `json:"type,omitempty"`
+	Required bool   `json:"required,omitempty"`
+	// Values are the allowed values, when the rules restrict them.
+	Values  []string `json:"values,omitempty"`
+	Default any      `json:"default,omitempty"`
+}
+
+// Complete lists the keys schema (see JSONSchema) allows at path, sorted.
+// The path is dotted up to the key being typed: "" or "se" complete
+// top-level keys, "settings." or "settings.re" the keys of settings. List
+// entries are reached through the list's key, so "features." completes the
+// keys of a feature; numeric segments ("features.0.") are skipped. A path
+// through a key the schema does not describe completes nothing.
+func Complete(schema map[string]any, path string) []Completion {
+	segments := strings.Split(path, ".")
+	prefix := segments[len(segments)-1]
+	node := schema
+	for _, segment := range segments[:len(segments)-1] {
+		node = schemaItems(node)
+		if isIndex(segment) {
+			continue
+		}
+		properties, _ := node["properties"].(map[string]any)
+		child, ok := properties[segment].(map[string]any)
+		if !ok {
+			return []Completion{}
+		}
+		node = child
+	}
+	node = schemaItems(node)
+
+	properties, _ := node["properties"].(map[string]any)
+	required, _ := node["required"].([]string)
+	out := []Completion{}
+	for key, p := range properties {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		p, _ := p.(map[string]any)
+		c := Completion{Key: key, Required: slices.Contains(required, key), Default: p["default"]}
+		c.Type, _ = p["type"].(string)
+		c.Values, _ = p["enum"].([]string)
+		if c.Type == "boolean" {
+			c.Values = []string{"true", "false"}
+		}
+		out = append(out, c)
+	}
+	// Required keys need not be described, like governance's labels.
+	for _, key := range required {
+		if _, ok := properties[key]; !ok && strings.HasPrefix(key, prefix) {
+			out = append(out, Completion{Key: key, Required: true})
+		}
+	}
+	slices.SortFunc(out, func(a, b Completion) int { return strings.Compare(a.Key, b.Key) })
+	return out
+}
+
+// schemaItems steps from an array schema to the schema of its entries.
+func schemaItems(node map[string]any) map[string]any {
+	if items, ok := node["items"].(map[string]any); ok && node["type"] == "array" {
+		return items
+	}
+	return node
+}
+
+func isIndex(segment string) bool {
+	return segment != "" && strings.Trim(segment, "0123456789") == ""
+}
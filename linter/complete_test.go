@@ -0,0 +1,42 @@
+package linter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	schema := JSONSchema(Options{Environments: []string{"qa"}, Governance: Governance{RequiredLabels: []string{"team"}}})
+	keys := func(path string) []string {
+		var out []string
+		for _, c := range Complete(schema, path) {
+			out = append(out, c.Key)
+		}
+		return out
+	}
+
+	if got := keys(""); !reflect.DeepEqual(got, []string{"features", "metadata", "settings"}) {
+		t.Errorf("expected the top-level keys, got %v", got)
+	}
+	if got := keys("settings.re"); !reflect.DeepEqual(got, []string{"replicas"}) {
+		t.Errorf("expected replicas, got %v", got)
+	}
+	if got := keys("features.0."); !reflect.DeepEqual(got, []string{"enabled", "name", "requires", "rollout"}) {
+		t.Errorf("expected the feature keys, got %v", got)
+	}
+	if got := keys("metadata.labels."); !reflect.DeepEqual(got, []string{"team"}) {
+		t.Errorf("expected the required label, got %v", got)
+	}
+	if got := keys("nope."); got != nil {
+		t.Errorf("expected nothing under an unknown key, got %v", got)
+	}
+
+	for _, c := range Complete(schema, "metadata.") {
+		if c.Key == "env" && (!c.Required || !reflect.DeepEqual(c.Values, []string{"qa"})) {
+			t.Errorf("expected env to be required with values [qa], got %+v", c)
+		}
+	}
+	if c := Complete(schema, "settings.timeout"); len(c) != 1 || c[0].Type != "integer" || c[0].Default == nil {
+		t.Errorf("expected timeout with its default, got %+v", c)
+	}
+}
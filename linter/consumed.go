@@ -0,0 +1,135 @@
+package linter
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ConsumedKeysFrom reads a consumed-keys manifest: a JSON array of dotted
+// paths or one path per line, with # comments.
+func ConsumedKeysFrom(data []byte) ([]string, error) {
+	if looksLikeJSON(data) {
+		var keys []string
+		if err := json.Unmarshal(data, &keys); err != nil {
+			return nil, err
+		}
+		return keys, nil
+	}
+	var keys []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if line = strings.TrimSpace(line); line != "" {
+			keys = append(keys, line)
+		}
+	}
+	return keys, nil
+}
+
+// keyPaths returns the dotted path of every key in the config with the
+// first place it is set. Feature fields are features.<key>; keys of
+// mappings nested in metadata are metadata.<mapping>.<key>.
+func keyPaths(cfg parsedConfig) map[string]fieldInfo {
+	paths := make(map[string]fieldInfo)
+	add := func(path string, info fieldInfo) {
+		if prev, ok := paths[path]; !ok || info.Line < prev.Line {
+			paths[path] = info
+		}
+	}
+
+	nested := make(map[int]bool)
+	for name, fields := range cfg.MetadataMaps {
+		for key, info := range fields {
+			add("metadata."+name+"."+key, info)
+			nested[info.Line] = true
+		}
+	}
+	for key, info := range cfg.Metadata {
+		if _, isMap := cfg.MetadataMaps[key]; !isMap && !nested[info.Line] {
+			add("metadata."+key, info)
+		}
+	}
+	for key, info := range cfg.Settings {
+		add("settings."+key, info)
+	}
+	for key, info := range cfg.TopLevel {
+		if !isIncludeKey(key) {
+			add(key, info)
+		}
+	}
+	for name, section := range cfg.Sections {
+		for key, info := range section.Fields {
+			add(name+"."+key, info)
+		}
+	}
+	for _, feature := range cfg.Features {
+		for key, info := range feature.Fields {
+			add("features."+key, info)
+		}
+	}
+	return paths
+}
+
+// consumes reports whether the manifest entry pattern covers path. A
+// trailing ".*" covers everything below its prefix.
+func consumes(pattern, path string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, ".*"); ok {
+		return strings.HasPrefix(path, prefix+".")
+	}
+	return pattern == path
+}
+
+// validateConsumedKeys compares the config against Options.ConsumedKeys:
+// keys nothing consumes (KEY001) and consumed keys the config does not set
+// (KEY002).
+func validateConsumedKeys(cfg parsedConfig, opts Options, issues *[]Issue) {
+	paths := keyPaths(cfg)
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := paths[sorted[i]], paths[sorted[j]]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return sorted[i] < sorted[j]
+	})
+
+	for _, path := range sorted {
+		consumed := false
+		for _, pattern := range opts.ConsumedKeys {
+			if consumes(pattern, path) {
+				consumed = true
+				break
+			}
+		}
+		if !consumed {
+			info := paths[path]
+			*issues = append(*issues, Issue{
+				Line:         info.Line,
+				Column:       info.Col,
+				Severity:     SeverityWarning,
+				RuleID:       "KEY001",
+				Message:      fmt.Sprintf("%s is not read by the application", path),
+				SuggestedFix: "Remove the key, or add it to the consumed-keys manifest if it is read",
+			})
+		}
+	}
+
+	for _, pattern := range opts.ConsumedKeys {
+		if strings.HasSuffix(pattern, ".*") {
+			continue
+		}
+		if _, ok := paths[pattern]; !ok {
+			*issues = append(*issues, Issue{
+				Line:         1,
+				Severity:     SeverityWarning,
+				RuleID:       "KEY002",
+				Message:      fmt.Sprintf("%s is read by the application but not set", pattern),
+				SuggestedFix: fmt.Sprintf("Set %s, or drop it from the manifest if the application no longer reads it", pattern),
+			})
+		}
+	}
+}
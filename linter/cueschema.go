@@ -0,0 +1,248 @@
+package linter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	cueerrors "cuelang.org/go/cue/errors"
+	cueyaml "cuelang.org/go/encoding/yaml"
+	"gopkg.in/yaml.v3"
+)
+
+// cueDocument is the file name the document is given in CUE, so error
+// positions in it can be told apart from those in the schema.
+const cueDocument = "config.yaml"
+
+// cueSchemaUses is how many documents one compiled schema checks before it
+// is dropped: every document built in a context stays in it.
+const cueSchemaUses = 256
+
+// maxCUEDepth bounds how deeply a CUE schema nests brackets and unary
+// operators; the CUE parser recurses through them and would run out of
+// stack long before a real schema gets this deep.
+const maxCUEDepth = 1000
+
+// cueSchema is a compiled Options.CUESchema and the context it was compiled
+// in. CUE values are tied to that context, which is not safe for concurrent
+// use, so a cueSchema serves one check at a time.
+type cueSchema struct {
+	ctx    *cue.Context
+	schema cue.Value
+	uses   int
+}
+
+// compileCUE compiles opts.CUESchema and picks opts.CUEDefinition from it.
+func compileCUE(opts Options) (*cueSchema, error) {
+	if depth := cueDepth(opts.CUESchema); depth > maxCUEDepth {
+		return nil, fmt.Errorf("cueSchema: nests deeper than %d levels", maxCUEDepth)
+	}
+	ctx := cuecontext.New()
+	schema := ctx.CompileString(opts.CUESchema, cue.Filename("schema.cue"))
+	if err := schema.Err(); err != nil {
+		return nil, fmt.Errorf("cueSchema: %s", cueMessage(err))
+	}
+	if opts.CUEDefinition != "" {
+		path := cue.ParsePath(opts.CUEDefinition)
+		if err := path.Err(); err != nil {
+			return nil, fmt.Errorf("cueDefinition %q: %w", opts.CUEDefinition, err)
+		}
+		if schema = schema.LookupPath(path); !schema.Exists() {
+			return nil, fmt.Errorf("cueDefinition %s is not defined in cueSchema", opts.CUEDefinition)
+		}
+	}
+	return &cueSchema{ctx: ctx, schema: schema}, nil
+}
+
+// newCUEPool returns a pool of schemas compiled from opts, so checks
+// running at the same time each get their own and later checks reuse them.
+func newCUEPool(opts Options) *sync.Pool {
+	return &sync.Pool{New: func() any {
+		s, err := compileCUE(opts)
+		if err != nil {
+			return nil
+		}
+		return s
+	}}
+}
+
+// acquireCUE returns a compiled schema for o and a func to hand it back:
+// one from the pool of the Linter that compiled o, if any, else a new one.
+func (o Options) acquireCUE() (*cueSchema, func(), error) {
+	if o.compiled != nil && o.compiled.cue != nil {
+		if s, ok := o.compiled.cue.Get().(*cueSchema); ok {
+			return s, func() {
+				if s.uses++; s.uses < cueSchemaUses {
+					o.compiled.cue.Put(s)
+				}
+			}, nil
+		}
+	}
+	s, err := compileCUE(o)
+	return s, func() {}, err
+}
+
+// cueDepth is the deepest nesting of brackets, and runs of unary operators
+// within them, in CUE source, outside strings and comments.
+func cueDepth(src string) int {
+	depth, deepest, run := 0, 0, 0
+	var quote byte
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == '!' || c == '-' || c == '+':
+			run++
+		}
+		if c != '!' && c != '-' && c != '+' && c != ' ' {
+			run = 0
+		}
+		deepest = max(deepest, depth+run)
+	}
+	return deepest
+}
+
+// validateCUE checks the document against Options.CUESchema, reporting
+// each constraint it breaks as CUE001. As for Options.Schema, only YAML and
+// JSON documents small enough to keep their source are checked.
+func validateCUE(cfg parsedConfig, opts Options, issues *[]Issue) {
+	if len(cfg.Source) > 0 {
+		checkCUE(cfg.Source, opts, issues)
+	}
+}
+
+func checkCUE(text []byte, opts Options, issues *[]Issue) {
+	root, ok := schemaInstance(text)
+	if !ok {
+		return
+	}
+	compiled, release, err := opts.acquireCUE()
+	if err != nil {
+		// Validate reports it.
+		return
+	}
+	defer release()
+	ctx, schema := compiled.ctx, compiled.schema
+	file, err := cueyaml.Extract(cueDocument, text)
+	if err != nil {
+		return
+	}
+	doc := ctx.BuildFile(file)
+	if doc.Err() != nil {
+		return
+	}
+	err = schema.Unify(doc).Validate(cue.Concrete(true))
+	// Error paths start with the definition's own.
+	prefix := len(cue.ParsePath(opts.CUEDefinition).Selectors())
+	var found []Issue
+	for _, e := range cueerrors.Errors(err) {
+		format, args := e.Msg()
+		if strings.HasSuffix(format, "empty disjunction:") {
+			// A heading; the errors of each disjunct follow it.
+			continue
+		}
+		elems := e.Path()
+		if len(elems) >= prefix {
+			elems = elems[prefix:]
+		}
+		at, path, set := cueLocate(root, elems)
+		issue := Issue{
+			Line:     at.Line,
+			Column:   at.Column,
+			Severity: SeverityError,
+			RuleID:   "CUE001",
+			Path:     path,
+		}
+		for _, pos := range e.InputPositions() {
+			if pos.Filename() == cueDocument && pos.Line() > 0 {
+				issue.Line, issue.Column = pos.Line(), pos.Column()
+				break
+			}
+		}
+		issue.Message = schemaSubject(path) + ": " + fmt.Sprintf(format, args...)
+		if !set && strings.HasPrefix(format, "incomplete value") {
+			issue.Message = schemaSubject(path) + " is required"
+		}
+		found = append(found, issue)
+	}
+	// CUE reports errors in its own order; list them in the document's.
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Line < found[j].Line })
+	*issues = append(*issues, found...)
+}
+
+// cueLocate finds the deepest key of path set in the document, reporting
+// whether that is path itself, and formats path as an issue path, with list
+// indexes in brackets.
+func cueLocate(root *yaml.Node, path []string) (*yaml.Node, string, bool) {
+	at, node := root, root
+	var b strings.Builder
+	for _, elem := range path {
+		if node != nil {
+			node = resolveAlias(node)
+		}
+		switch {
+		case node != nil && node.Kind == yaml.SequenceNode:
+			b.WriteString("[" + elem + "]")
+			i, err := strconv.Atoi(elem)
+			if err != nil || i < 0 || i >= len(node.Content) {
+				node = nil
+				continue
+			}
+			node = node.Content[i]
+			at = node
+		default:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(elem)
+			if node == nil || node.Kind != yaml.MappingNode {
+				node = nil
+				continue
+			}
+			var found *yaml.Node
+			for _, p := range mappingPairs(node, nil, 0) {
+				if p.key.Value == elem {
+					found, at = p.value, p.at
+					break
+				}
+			}
+			node = found
+		}
+	}
+	return at, b.String(), node != nil
+}
+
+// cueMessage is err's first message with its position, for option errors.
+func cueMessage(err error) string {
+	errs := cueerrors.Errors(err)
+	if len(errs) == 0 {
+		return err.Error()
+	}
+	e := errs[0]
+	format, args := e.Msg()
+	msg := fmt.Sprintf(format, args...)
+	if pos := e.Position(); pos.Line() > 0 {
+		msg = fmt.Sprintf("line %d: %s", pos.Line(), msg)
+	}
+	return msg
+}
@@ -0,0 +1,127 @@
+package linter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// builtinDefaults are the values assumed for missing keys unless the rc file
+// declares otherwise.
+var builtinDefaults = map[string]string{"settings.timeout": "30"}
+
+// defaults returns the effective defaults catalog by key path: the
+// built-ins, then DefaultTimeout, then Defaults.
+func (o Options) defaults() map[string]string {
+	out := make(map[string]string, len(builtinDefaults)+len(o.Defaults))
+	for key, value := range builtinDefaults {
+		out[key] = value
+	}
+	if o.DefaultTimeout > 0 {
+		out["settings.timeout"] = strconv.Itoa(o.DefaultTimeout)
+	}
+	for key, value := range o.Defaults {
+		if s, ok := defaultValue(value); ok {
+			out[key] = s
+		}
+	}
+	return out
+}
+
+func (o Options) defaultTimeout() int {
+	n, _ := strconv.Atoi(o.defaults()["settings.timeout"])
+	return n
+}
+
+// defaultValue renders a catalog value the way it is written in a config.
+func defaultValue(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int:
+		return strconv.Itoa(v), true
+	}
+	return "", false
+}
+
+// splitDefaultKey splits a catalog key into its section and field. Only
+// plain metadata and settings keys can be defaulted.
+func splitDefaultKey(key string) (section, field string, ok bool) {
+	section, field, ok = strings.Cut(key, ".")
+	if !ok || (section != "metadata" && section != "settings") || field == "" || strings.ContainsAny(field, ".[]") {
+		return "", "", false
+	}
+	return section, field, true
+}
+
+func validateDefaultsCatalog(defaults map[string]any) error {
+	for key, value := range defaults {
+		if _, _, ok := splitDefaultKey(key); !ok {
+			return fmt.Errorf("default %q must be a metadata.<key> or settings.<key> path", key)
+		}
+		s, ok := defaultValue(value)
+		if !ok {
+			return fmt.Errorf("default %q must be a string, number or boolean", key)
+		}
+		if key == "settings.timeout" && !isPositiveInt(s) {
+			return fmt.Errorf("default %q must be a positive integer", key)
+		}
+	}
+	return nil
+}
+
+// sectionFields returns the parsed fields and header line of a section
+// defaults may target.
+func sectionFields(cfg parsedConfig, section string) (map[string]fieldInfo, int) {
+	if section == "metadata" {
+		return cfg.Metadata, cfg.MetadataLine
+	}
+	return cfg.Settings, cfg.SettingsLine
+}
+
+// missingDefaults returns the catalog keys absent from sections the config
+// has, sorted by key.
+func missingDefaults(cfg parsedConfig, opts Options) []string {
+	var keys []string
+	for key := range opts.defaults() {
+		section, field, ok := splitDefaultKey(key)
+		if !ok {
+			continue
+		}
+		fields, _ := sectionFields(cfg, section)
+		if len(fields) == 0 {
+			continue
+		}
+		if _, ok := fields[field]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validateDefaults reports the declared defaults a config leaves out (DEF001)
+// as info issues. settings.timeout is covered by SET004.
+func validateDefaults(cfg parsedConfig, opts Options, issues *[]Issue) {
+	values := opts.defaults()
+	for _, key := range missingDefaults(cfg, opts) {
+		if key == "settings.timeout" {
+			continue
+		}
+		section, _, _ := splitDefaultKey(key)
+		_, line := sectionFields(cfg, section)
+		*issues = append(*issues, Issue{
+			Line:         line,
+			Severity:     SeverityInfo,
+			RuleID:       "DEF001",
+			Message:      fmt.Sprintf("%s is not set and will default to %q", key, values[key]),
+			SuggestedFix: fmt.Sprintf("Add %s: %s", key, values[key]),
+			Path:         key,
+		})
+	}
+}
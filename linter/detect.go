@@ -0,0 +1,77 @@
+package linter
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+// wellKnownNames are base names (without extension) commonly used for the
+// configs this linter understands.
+var wellKnownNames = []string{"config", "app", "service", "settings", "catalog-info", "deploy"}
+
+// Detect reports whether a file looks like something this linter can check
+// and why. It looks at the name and sniffs data (the start of the file is
+// enough); it never reports issues.
+func Detect(name string, data []byte) (ok bool, reason string) {
+	base := filepath.Base(name)
+	switch {
+	case strings.HasSuffix(base, ".tfvars"), strings.HasSuffix(base, ".tfvars.json"):
+		return true, "Terraform variables file"
+	case IsEnvFile(base):
+		return true, "dotenv file"
+	}
+
+	// The formats are told apart by extension, as LintNamed does.
+	limits := Limits{}.Effective()
+	var (
+		format string
+		cfg    parsedConfig
+		err    error
+	)
+	ext := filepath.Ext(base)
+	switch ext {
+	case ".yaml", ".yml", ".json":
+		format = "YAML"
+		if looksLikeJSON(data) {
+			format = "JSON"
+		}
+		cfg, err = parseConfig(data)
+	case ".ini", ".conf":
+		format = "INI"
+		cfg, _, err = parseINI(data, limits)
+	case ".properties":
+		format = "Properties"
+		cfg, _, err = parseProperties(data, limits)
+	case ".xml":
+		format = "XML"
+		cfg, err = parseXML(bytes.NewReader(data), limits)
+	default:
+		return false, ""
+	}
+	if err != nil {
+		return false, ""
+	}
+	var found []string
+	if strings.HasPrefix(cfg.TopLevel["apiVersion"].Value, "backstage.io/") {
+		found = append(found, "Backstage apiVersion")
+	}
+	for _, s := range []struct {
+		name string
+		line int
+	}{{"metadata", cfg.MetadataLine}, {"settings", cfg.SettingsLine}, {"features", cfg.FeaturesLine}} {
+		if s.line > 0 {
+			found = append(found, s.name)
+		}
+	}
+	// metadata alone is too common (Kubernetes, Helm) to count.
+	if len(found) == 0 || (len(found) == 1 && found[0] == "metadata") {
+		return false, ""
+	}
+
+	reason = format + " with " + strings.Join(found, ", ")
+	if contains(wellKnownNames, strings.TrimSuffix(base, ext)) {
+		reason += "; well-known name"
+	}
+	return true, reason
+}
@@ -0,0 +1,509 @@
+package linter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Document is a config kept parsed between edits, for watch mode and
+// editors. Apply re-parses and re-validates only the top-level sections and
+// feature entries an edit touches; everything else is reused. JSON documents
+// are a single section and are re-parsed in full. While the text is
+// malformed it is linted in full, so Issues always matches LintWithOptions.
+type Document struct {
+	opts  Options
+	lines []string
+	// final is set when the text ends with a newline.
+	final  bool
+	blocks []docBlock
+	// syntax holds the syntax errors of the whole text when it has more
+	// than one block; see decodeLines.
+	syntax []Issue
+	// whole is the whole text parsed as LintWithOptions parses it, kept
+	// while the text is malformed or its blocks do not split it the way
+	// the parser reads it; Issues then lints it in full.
+	whole *parsedConfig
+}
+
+// Edit replaces lines StartLine through EndLine-1 (1-based) with Text.
+// StartLine == EndLine inserts before StartLine; an empty Text deletes.
+type Edit struct {
+	StartLine int
+	EndLine   int
+	Text      string
+}
+
+type blockKind int
+
+const (
+	blockSection blockKind = iota
+	blockFeature
+)
+
+type blockSpan struct {
+	start int // 0-based index of the first line
+	n     int
+	kind  blockKind
+}
+
+// docBlock is a span parsed on its own. Lines in cfg and in the cached
+// issues are relative to the block; origin converts them to document lines.
+type docBlock struct {
+	blockSpan
+	cfg      parsedConfig
+	features []Issue
+	style    []Issue
+	types    []Issue
+	vault    []Issue
+}
+
+// featurePrefix puts a feature entry back into the features section when it
+// is parsed on its own.
+const featurePrefix = "features:\n"
+
+func (b docBlock) origin() int {
+	if b.kind == blockFeature {
+		return b.start - 1
+	}
+	return b.start
+}
+
+// NewDocument parses data for incremental linting with opts.
+func NewDocument(data []byte, opts Options) (*Document, error) {
+	d := &Document{opts: opts}
+	lines := splitLines(string(data))
+	if err := d.rebuild(lines, bytes.HasSuffix(data, []byte("\n")), 0, len(lines), 0); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Apply applies edits in order and returns the issues for the result. On
+// error the document is left as it was before the failing edit.
+func (d *Document) Apply(edits ...Edit) ([]Issue, error) {
+	for _, e := range edits {
+		if e.StartLine < 1 || e.EndLine < e.StartLine || e.EndLine > len(d.lines)+1 {
+			return nil, fmt.Errorf("edit lines %d-%d out of range", e.StartLine, e.EndLine)
+		}
+		text := splitLines(e.Text)
+		lines := make([]string, 0, len(d.lines)-(e.EndLine-e.StartLine)+len(text))
+		lines = append(lines, d.lines[:e.StartLine-1]...)
+		lines = append(lines, text...)
+		lines = append(lines, d.lines[e.EndLine-1:]...)
+
+		// An edit reaching past the last line decides how the text ends;
+		// deleting the last lines leaves one that ended with a newline.
+		final := d.final
+		if e.EndLine == len(d.lines)+1 && e.Text != "" {
+			final = strings.HasSuffix(e.Text, "\n")
+		} else if e.EndLine == len(d.lines)+1 && e.StartLine < e.EndLine {
+			final = e.StartLine > 1
+		}
+
+		delta := len(text) - (e.EndLine - e.StartLine)
+		if err := d.rebuild(lines, final, e.StartLine-1, e.StartLine-1+len(text), delta); err != nil {
+			return nil, err
+		}
+	}
+	return d.Issues(), nil
+}
+
+// rebuild re-splits lines into blocks, reusing every block that lies wholly
+// before editStart or at or after editEnd (shifted by delta) and parsing the
+// rest.
+func (d *Document) rebuild(lines []string, final bool, editStart, editEnd, delta int) error {
+	limits := d.opts.Limits.Effective()
+	if len(lines) > limits.MaxLines {
+		return &LimitError{Limit: "line count", Max: int64(limits.MaxLines), Line: limits.MaxLines + 1}
+	}
+	size := int64(0)
+	for _, line := range lines {
+		size += int64(len(line)) + 1
+	}
+	if size > limits.MaxBytes {
+		return &LimitError{Limit: "input size", Max: limits.MaxBytes}
+	}
+
+	old := make(map[blockSpan]docBlock, len(d.blocks))
+	for _, b := range d.blocks {
+		old[b.blockSpan] = b
+	}
+
+	spans := splitBlocks(lines)
+	blocks := make([]docBlock, 0, len(spans))
+	features := 0
+	for _, span := range spans {
+		end := span.start + span.n
+		var b docBlock
+		var ok bool
+		switch {
+		case end <= editStart:
+			b, ok = old[span]
+		case span.start >= editEnd:
+			prev := span
+			prev.start -= delta
+			if b, ok = old[prev]; ok {
+				b.blockSpan = span
+			}
+		}
+		if !ok {
+			var err error
+			if b, err = d.parseBlock(span, lines[span.start:end], final && end == len(lines)); err != nil {
+				return err
+			}
+		}
+		features += len(b.cfg.Features)
+		blocks = append(blocks, b)
+	}
+	if features > limits.MaxFeatures {
+		return &LimitError{Limit: "feature count", Max: int64(limits.MaxFeatures), Line: len(lines)}
+	}
+
+	var syntax []Issue
+	var whole *parsedConfig
+	if len(blocks) > 1 && size <= yamlDecodeMax {
+		text := joinLines(lines, final)
+		decoded := decodeLines(text)
+		if syntax = decoded.ParseIssues; len(syntax) > 0 || !sameKeys(blocks, decoded) {
+			cfg, err := parseStream(strings.NewReader(text), d.opts.Limits)
+			if err != nil {
+				return err
+			}
+			whole = &cfg
+		}
+	}
+
+	d.lines = lines
+	d.final = final
+	d.blocks = blocks
+	d.syntax = syntax
+	d.whole = whole
+	return nil
+}
+
+// decodeLines decodes the whole text for its syntax errors and structure.
+// Blocks that parse on their own can still be malformed together, and a
+// block cut out of a document can fail where the document does not, so
+// errors found in blocks are not used.
+func decodeLines(text string) parsedConfig {
+	cfg := newParsedConfig()
+	decodeYAML([]byte(text), &cfg)
+	return cfg
+}
+
+// sameKeys reports whether blocks hold the keys, sections and feature
+// entries the whole document decodes to, on the same lines. Blocks parsed
+// on their own can read a document differently, such as an entry that is
+// a nested list rather than a mapping.
+func sameKeys(blocks []docBlock, decoded parsedConfig) bool {
+	return reflect.DeepEqual(newPathIndex(mergeBlocks(blocks)), newPathIndex(decoded))
+}
+
+// parseBlock parses the lines of span; final is set when they end the text
+// and it ends with a newline.
+func (d *Document) parseBlock(span blockSpan, lines []string, final bool) (docBlock, error) {
+	text := joinLines(lines, final)
+	if span.kind == blockFeature {
+		text = featurePrefix + text
+	}
+	cfg, err := parseStream(strings.NewReader(text), d.opts.Limits)
+	if err != nil {
+		var limitErr *LimitError
+		if errors.As(err, &limitErr) && limitErr.Line > 0 {
+			shifted := *limitErr
+			shifted.Line += docBlock{blockSpan: span}.origin()
+			return docBlock{}, &shifted
+		}
+		return docBlock{}, err
+	}
+
+	if span.kind == blockFeature {
+		// The features key is featurePrefix, not a line of the document.
+		cfg.FeaturesLine = 0
+	}
+	b := docBlock{blockSpan: span, cfg: cfg}
+	if span.kind == blockFeature {
+		validateFeatures(cfg, &b.features)
+		if d.opts.Style {
+			validateStyleFeatures(cfg, d.opts, &b.style)
+		}
+	}
+	validateYAMLTypes(cfg, d.opts, &b.types)
+	validateVaultRefs(cfg, &b.vault)
+	return b, nil
+}
+
+// Issues returns the issues for the current text, in the same order as
+// LintWithOptions.
+func (d *Document) Issues() []Issue {
+	if d.whole != nil {
+		return lintParsed(*d.whole, d.opts)
+	}
+	skeleton := newParsedConfig()
+	for _, b := range d.blocks {
+		if b.kind == blockSection {
+			mergeShifted(&skeleton, b.cfg, b.origin())
+		}
+	}
+
+	issues := append([]Issue(nil), d.syntax...)
+	if len(d.blocks) == 1 {
+		issues = append(issues, d.blocks[0].cfg.ParseIssues...)
+	}
+	// full is the whole document, assembled only when a rule needs it; key
+	// paths need it too, so it is built at the end otherwise.
+	var full parsedConfig
+	p, routed := routeProfile(skeleton, d.opts)
+	if routed && !p.additive {
+		p.validate(skeleton, d.opts, &issues)
+	} else {
+		validateMetadata(skeleton, d.opts, &issues)
+		validateSettings(skeleton, d.opts, &issues)
+		validateFeatures(skeleton, &issues)
+		for _, b := range d.blocks {
+			issues = appendShifted(issues, b.features, b.origin())
+		}
+		// Dependencies, rollouts, placeholders and key usage span blocks, so
+		// they are checked on the whole document, which is only assembled
+		// when needed.
+		if routed || d.hasFeatureField("requires") || d.hasFeatureField("rollout") || len(d.opts.TemplateVariables) > 0 || len(d.opts.ConsumedKeys) > 0 || d.opts.StrictKeys {
+			full = d.merged()
+			validateFeatureRequires(full, d.opts, &issues)
+			validateRollout(full, d.opts, &issues)
+		}
+		validateLocaleValues(skeleton, d.opts, &issues)
+		if len(d.opts.Defaults) > 0 {
+			validateDefaults(skeleton, d.opts, &issues)
+		}
+		if d.opts.ReviewWindowDays > 0 {
+			validateReview(skeleton, d.opts, &issues)
+		}
+		if routed {
+			p.validate(full, d.opts, &issues)
+		}
+		if d.opts.Governance.enabled() {
+			validateGovernance(skeleton, d.opts, &issues)
+		}
+		if len(d.opts.TemplateVariables) > 0 {
+			validateTemplates(full, d.opts, &issues)
+		}
+		if len(d.opts.ConsumedKeys) > 0 {
+			validateConsumedKeys(full, d.opts, &issues)
+		}
+		if d.opts.StrictKeys {
+			validateUnknownKeys(full, d.opts, &issues)
+		}
+		if d.opts.Style {
+			validateStyleSections(skeleton, d.opts, &issues)
+			validateStyleFeatures(skeleton, d.opts, &issues)
+			for _, b := range d.blocks {
+				issues = appendShifted(issues, b.style, b.origin())
+			}
+		}
+	}
+	for _, b := range d.blocks {
+		issues = appendShifted(issues, b.types, b.origin())
+	}
+	var sups []suppression
+	for _, b := range d.blocks {
+		issues = appendShifted(issues, b.vault, b.origin())
+		for _, s := range b.cfg.Suppressions {
+			s.Line += b.origin()
+			s.Comment += b.origin()
+			sups = append(sups, s)
+		}
+	}
+	if len(d.opts.Schema) > 0 || d.opts.CUESchema != "" {
+		if text := []byte(joinLines(d.lines, d.final)); len(text) <= yamlDecodeMax {
+			if len(d.opts.Schema) > 0 {
+				checkSchema(text, d.opts, &issues)
+			}
+			if d.opts.CUESchema != "" {
+				checkCUE(text, d.opts, &issues)
+			}
+		}
+	}
+	rules := append(sectionRules(d.opts), patternRules(d.opts)...)
+	rules = append(rules, enumRules(d.opts)...)
+	rules = append(rules, expressionRules(d.opts)...)
+	if rules = append(rules, registeredRules(d.opts)...); len(rules) > 0 {
+		if full.Metadata == nil {
+			full = d.merged()
+		}
+		for _, r := range rules {
+			r.exec(full, d.opts, &issues)
+		}
+	}
+	issues = applyRuleSettings(applySuppressions(issues, sups, d.opts.ReportSuppressed), d.opts)
+	if full.Metadata == nil {
+		full = d.merged()
+	}
+	assignPaths(issues, full)
+	applyMessageTemplates(issues, full, d.opts)
+	if d.opts.Redact {
+		cfgs := make([]parsedConfig, len(d.blocks))
+		for i, b := range d.blocks {
+			cfgs[i] = b.cfg
+		}
+		redactIssues(issues, cfgs...)
+	}
+	return issues
+}
+
+// merged is the whole document as one config, with the lines of each block
+// shifted to its position.
+func (d *Document) merged() parsedConfig {
+	return mergeBlocks(d.blocks)
+}
+
+func mergeBlocks(blocks []docBlock) parsedConfig {
+	full := newParsedConfig()
+	for _, b := range blocks {
+		mergeShifted(&full, b.cfg, b.origin())
+	}
+	return full
+}
+
+func (d *Document) hasFeatureField(key string) bool {
+	for _, b := range d.blocks {
+		for _, f := range b.cfg.Features {
+			if _, ok := f.Fields[key]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitBlocks cuts YAML lines into top-level sections, with each entry of
+// the features list in its own block. Blank and comment lines stay with the
+// block before them.
+func splitBlocks(lines []string) []blockSpan {
+	spans := []blockSpan{{start: 0, kind: blockSection}}
+	hasContent := false
+	topIndent := -1
+	inFeatures := false
+	entryIndent := -1
+	entryFields := false
+
+	for i, line := range lines {
+		spans[len(spans)-1].n = i - spans[len(spans)-1].start
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed[0] == '#' {
+			continue
+		}
+		if topIndent < 0 && (trimmed[0] == '{' || trimmed[0] == '[' || trimmed[0] == '<') || anchored(trimmed) {
+			return []blockSpan{{start: 0, n: len(lines), kind: blockSection}}
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		isItem := strings.HasPrefix(trimmed, "-")
+		key, value, isKey := parseKeyValue(trimmed)
+		if topIndent < 0 && isKey && !isItem {
+			topIndent = indent
+		}
+
+		switch {
+		case isKey && !isItem && indent == topIndent:
+			if hasContent {
+				spans = append(spans, blockSpan{start: i, kind: blockSection})
+			}
+			inFeatures = key == "features" && value == ""
+			entryIndent, entryFields = -1, false
+		case inFeatures && isItem && !(entryFields && indent > entryIndent):
+			// Like the parser, only dashes nested under an entry that has
+			// fields are list items; every other dash starts an entry.
+			spans = append(spans, blockSpan{start: i, kind: blockFeature})
+			entryIndent = indent
+			_, _, entryFields = parseKeyValue(strings.TrimSpace(strings.TrimPrefix(trimmed, "-")))
+		case inFeatures && isKey && !isItem:
+			entryFields = true
+		}
+		hasContent = true
+	}
+	spans[len(spans)-1].n = len(lines) - spans[len(spans)-1].start
+	return spans
+}
+
+// anchored reports whether a line defines or uses a YAML anchor. Aliases
+// can point into other blocks, so such documents are parsed whole.
+func anchored(trimmed string) bool {
+	rest := strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))
+	if _, value, ok := parseKeyValue(rest); ok {
+		rest = value
+	}
+	return rest != "" && (rest[0] == '&' || rest[0] == '*')
+}
+
+// joinLines is the inverse of splitLines, with a final newline when final
+// is set.
+func joinLines(lines []string, final bool) string {
+	text := strings.Join(lines, "\n")
+	if final && len(lines) > 0 {
+		text += "\n"
+	}
+	return text
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+func mergeShifted(dst *parsedConfig, src parsedConfig, delta int) {
+	shift := func(dst, src map[string]fieldInfo) {
+		for key, info := range src {
+			info.Line += delta
+			dst[key] = info
+		}
+	}
+	shift(dst.Metadata, src.Metadata)
+	shift(dst.Settings, src.Settings)
+	shift(dst.TopLevel, src.TopLevel)
+	for key, fields := range src.MetadataMaps {
+		if dst.MetadataMaps == nil {
+			dst.MetadataMaps = make(map[string]map[string]fieldInfo)
+		}
+		dst.MetadataMaps[key] = make(map[string]fieldInfo, len(fields))
+		shift(dst.MetadataMaps[key], fields)
+	}
+	for key, section := range src.Sections {
+		fields := make(map[string]fieldInfo, len(section.Fields))
+		shift(fields, section.Fields)
+		dst.Sections[key] = sectionInfo{Fields: fields, Line: section.Line + delta, Comments: section.Comments}
+	}
+	for _, feature := range src.Features {
+		fields := make(map[string]fieldInfo, len(feature.Fields))
+		shift(fields, feature.Fields)
+		dst.Features = append(dst.Features, featureEntry{Fields: fields, Line: feature.Line + delta, Col: feature.Col, Comments: feature.Comments})
+	}
+	if src.MetadataLine > 0 {
+		dst.MetadataLine = src.MetadataLine + delta
+	}
+	if src.SettingsLine > 0 {
+		dst.SettingsLine = src.SettingsLine + delta
+	}
+	if src.FeaturesLine > 0 {
+		dst.FeaturesLine = src.FeaturesLine + delta
+	}
+}
+
+func appendShifted(dst, src []Issue, delta int) []Issue {
+	for _, issue := range src {
+		if issue.Line > 0 {
+			issue.Line += delta
+		}
+		dst = append(dst, issue)
+	}
+	return dst
+}
@@ -0,0 +1,131 @@
+package linter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDocumentApplyMatchesFullLint(t *testing.T) {
+	base := `# service config
+metadata:
+  name: payments
+  env: prod
+settings:
+  replicas: 2
+  timeout: 30
+  token: vault:secret/app
+features:
+  - name: a
+    enabled: true
+  - name: b
+    enabled: yes
+  - name: c
+    enabled: false
+`
+	doc, err := NewDocument([]byte(base), Options{})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	lines := splitLines(base)
+
+	edits := []Edit{
+		{StartLine: 6, EndLine: 7, Text: "  replicas: zero\n"},
+		{StartLine: 1, EndLine: 1, Text: "# header\n# more\n"},
+		{StartLine: 15, EndLine: 15, Text: "  - name: inserted\n    enabled: maybe\n"},
+		{StartLine: 11, EndLine: 13, Text: ""},
+		{StartLine: 4, EndLine: 5, Text: "  env: qa\n"},
+		{StartLine: 3, EndLine: 6, Text: ""},
+		{StartLine: 1, EndLine: 1, Text: "metadata:\n  name: again\n"},
+	}
+	for i, e := range edits {
+		got, err := doc.Apply(e)
+		if err != nil {
+			t.Fatalf("edit %d: expected nil error, got %v", i, err)
+		}
+
+		next := append([]string{}, lines[:e.StartLine-1]...)
+		next = append(next, splitLines(e.Text)...)
+		lines = append(next, lines[e.EndLine-1:]...)
+
+		want, err := LintBytes([]byte(strings.Join(lines, "\n")))
+		if err != nil {
+			t.Fatalf("edit %d: expected nil error, got %v", i, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("edit %d: incremental issues differ\n got: %+v\nwant: %+v", i, got, want)
+		}
+	}
+
+	if _, err := doc.Apply(Edit{StartLine: 0, EndLine: 1}); err == nil {
+		t.Error("expected out-of-range edit to be rejected")
+	}
+}
+
+func TestDocumentReusesUntouchedBlocks(t *testing.T) {
+	doc, err := NewDocument(benchCorpus(2_000), Options{})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	before := doc.blocks
+
+	if _, err := doc.Apply(Edit{StartLine: 6, EndLine: 7, Text: "  replicas: 4\n"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	for i := 3; i < len(doc.blocks); i++ {
+		if reflect.ValueOf(doc.blocks[i].cfg.Features[0].Fields).Pointer() != reflect.ValueOf(before[i].cfg.Features[0].Fields).Pointer() {
+			t.Fatalf("block %d was re-parsed", i)
+		}
+	}
+}
+
+func TestDocumentMatchesLintOnMalformedInput(t *testing.T) {
+	fixtures := []string{
+		"features:\n  {\n  }\n  - {name: a}\n}\n",
+		"metadata:\n  name: a\n  bogus: 1\nsettings:\n  replicas: 1\n[\n  timout: 3\nfeatures:\n  - name: x\n    enbled: true\n:\n  - name: y\n",
+		"metadata:\n  name: a\nsettings:\nsettings:  replicas: 1\n&a  timout: 3\nextra:\n  k: v\n",
+		"metadata:\n  name: a\nfeatures:\n  - name: x\n  bar: 2\n    enbled: true\n:    requires: [y]\n  - name: y\n",
+		"metadata:\n  name: a\nfeatures:\n- \n  - name: x\n    enbled: true\n  - name: y\n    enabled: false",
+		"'metadata:\n  name: a\nextra:&a\n  k: v\nfeatures:\n  - name: x\n",
+		"  - name: q\nenabled: maybe\n",
+	}
+	for _, opts := range []Options{{}, {StrictKeys: true, Style: true}} {
+		for _, fixture := range fixtures {
+			want, err := LintWithOptions([]byte(fixture), opts)
+			if err != nil {
+				t.Fatalf("%q: expected nil error, got %v", fixture, err)
+			}
+			doc, err := NewDocument([]byte(fixture), opts)
+			if err != nil {
+				t.Fatalf("%q: expected nil error, got %v", fixture, err)
+			}
+			if got := doc.Issues(); !reflect.DeepEqual(got, want) {
+				t.Errorf("%q: Document and LintWithOptions differ\n got: %+v\nwant: %+v", fixture, got, want)
+			}
+		}
+	}
+
+	// Breaking a document and fixing it again goes through the same states.
+	valid := "metadata:\n  name: a\n  env: dev\nsettings:\n  replicas: 1\n  timeout: 5\nfeatures:\n  - name: x\n    enabled: true\n"
+	doc, err := NewDocument([]byte(valid), Options{StrictKeys: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range []struct {
+		edit Edit
+		text string
+	}{
+		{Edit{StartLine: 6, EndLine: 6, Text: "[\n"}, strings.Replace(valid, "  timeout", "[\n  timeout", 1)},
+		{Edit{StartLine: 6, EndLine: 7}, valid},
+		{Edit{StartLine: 10, EndLine: 10, Text: "  - name: y"}, valid + "  - name: y"},
+	} {
+		got, err := doc.Apply(step.edit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := LintWithOptions([]byte(step.text), Options{StrictKeys: true})
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("after %+v: Document and LintWithOptions differ\n got: %+v\nwant: %+v", step.edit, got, want)
+		}
+	}
+}
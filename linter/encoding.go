@@ -0,0 +1,89 @@
+package linter
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"unicode/utf16"
+)
+
+// Encodings other than plain UTF-8 that configs exported from Windows tools
+// arrive in. They are transcoded to UTF-8 before parsing.
+const (
+	encodingUTF8BOM = "UTF-8 with a byte order mark"
+	encodingUTF16LE = "UTF-16LE"
+	encodingUTF16BE = "UTF-16BE"
+)
+
+// sniffEncoding names the encoding of an input starting with head, from its
+// byte order mark or, for UTF-16 without one, from the NUL byte next to each
+// of the first ASCII characters. It returns "" for UTF-8.
+func sniffEncoding(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte{0xEF, 0xBB, 0xBF}):
+		return encodingUTF8BOM
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE}):
+		return encodingUTF16LE
+	case bytes.HasPrefix(head, []byte{0xFE, 0xFF}):
+		return encodingUTF16BE
+	case len(head) < 4:
+		return ""
+	case head[0] != 0 && head[1] == 0 && head[2] != 0 && head[3] == 0:
+		return encodingUTF16LE
+	case head[0] == 0 && head[1] != 0 && head[2] == 0 && head[3] != 0:
+		return encodingUTF16BE
+	}
+	return ""
+}
+
+// toUTF8 returns data, written in encoding, as UTF-8 without a byte order
+// mark. A trailing odd byte of UTF-16 input is dropped.
+func toUTF8(data []byte, encoding string) []byte {
+	switch encoding {
+	case encodingUTF8BOM:
+		return data[3:]
+	case encodingUTF16LE, encodingUTF16BE:
+		data = bytes.TrimPrefix(bytes.TrimPrefix(data, []byte{0xFF, 0xFE}), []byte{0xFE, 0xFF})
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			if encoding == encodingUTF16LE {
+				units[i] = uint16(data[2*i]) | uint16(data[2*i+1])<<8
+			} else {
+				units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+			}
+		}
+		var out bytes.Buffer
+		out.Grow(len(units))
+		for _, r := range utf16.Decode(units) {
+			out.WriteRune(r)
+		}
+		return out.Bytes()
+	}
+	return data
+}
+
+// transcodeReader returns br's remaining input, in encoding, as UTF-8. A
+// byte order mark is skipped in place; UTF-16 input is read whole first.
+func transcodeReader(br *bufio.Reader, encoding string) (*bufio.Reader, error) {
+	if encoding == encodingUTF8BOM {
+		_, err := br.Discard(3)
+		return br, err
+	}
+	data, err := io.ReadAll(br)
+	if err != nil {
+		return nil, err
+	}
+	return bufio.NewReaderSize(bytes.NewReader(toUTF8(data, encoding)), textSniff), nil
+}
+
+// encodingIssue tells the user their config was transcoded (ENC001), so
+// tools that do not transcode are not a surprise.
+func encodingIssue(encoding string) Issue {
+	return Issue{
+		Line:         1,
+		Severity:     SeverityInfo,
+		RuleID:       "ENC001",
+		Message:      "the file is encoded as " + encoding + "; it was read as UTF-8",
+		SuggestedFix: "Save the file as UTF-8 without a byte order mark",
+	}
+}
@@ -0,0 +1,75 @@
+package linter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// envPath is the key path Options.Environments constrains.
+const envPath = "metadata.env"
+
+func validateEnums(enums map[string][]string) error {
+	for _, path := range sortedKeys(enums) {
+		if strings.TrimSpace(path) == "" {
+			return fmt.Errorf("enums must not contain an empty key path")
+		}
+		if len(enums[path]) == 0 {
+			return fmt.Errorf("enum for %s lists no values", path)
+		}
+	}
+	return nil
+}
+
+// enumRules is the rule checking Options.Enums, if any besides
+// metadata.env are set; META004 checks that one.
+func enumRules(opts Options) []namedRule {
+	for path := range opts.Enums {
+		if path != envPath {
+			return []namedRule{{"enums", validateEnumValues}}
+		}
+	}
+	return nil
+}
+
+// validateEnumValues reports values outside the enumeration declared for
+// their key path (ENUM001). Paths are matched as for Patterns, and each
+// item of a list is checked on its own.
+func validateEnumValues(cfg parsedConfig, opts Options, issues *[]Issue) {
+	for _, v := range patternValues(cfg) {
+		for _, declared := range sortedKeys(opts.Enums) {
+			if declared == envPath || !consumes(declared, v.field) {
+				continue
+			}
+			allowed := opts.Enums[declared]
+			values := []fieldInfo{v.value}
+			if !v.value.Quoted {
+				values = flowList(v.value.Value, v.value.Line, v.value.Col)
+			}
+			for _, item := range values {
+				if contains(allowed, item.Value) {
+					continue
+				}
+				*issues = append(*issues, Issue{
+					Line:         item.Line,
+					Column:       item.Col,
+					Severity:     SeverityWarning,
+					RuleID:       "ENUM001",
+					Message:      fmt.Sprintf("%s value %q is not recognized", v.field, item.Value),
+					SuggestedFix: fmt.Sprintf("Use one of: %s", strings.Join(allowed, ", ")),
+				})
+			}
+		}
+	}
+}
+
+// addEnums adds the enumerations of section.key paths to a schema built by
+// JSONSchema, so editors and completion offer their values.
+func addEnums(schema map[string]any, enums map[string][]string) {
+	for _, path := range sortedKeys(enums) {
+		name, key, ok := strings.Cut(path, ".")
+		if !ok || path == envPath || strings.Contains(key, ".") || key == "*" {
+			continue
+		}
+		schemaProperty(schemaSection(schema, name), key)["enum"] = enums[path]
+	}
+}
@@ -0,0 +1,154 @@
+package linter
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+type envVar struct {
+	Name   string
+	Value  string
+	Quoted bool
+	Line   int
+	Col    int
+	// ValueCol is where the value starts.
+	ValueCol int
+}
+
+// IsEnvFile reports whether name is a dotenv file: .env, .env.<stage> or
+// <stage>.env.
+func IsEnvFile(name string) bool {
+	base := filepath.Base(name)
+	return base == ".env" || strings.HasPrefix(base, ".env.") || strings.HasSuffix(base, ".env")
+}
+
+// LintEnvFile lints a dotenv file: duplicate variables (ENV001), empty
+// values (ENV002), unquoted values containing whitespace, which loaders and
+// shells disagree on (ENV003), plaintext secrets (ENV004) and lines that are
+// not NAME=value assignments (ENV005).
+func LintEnvFile(data []byte, opts Options) ([]Issue, error) {
+	if err := checkText(data[:min(len(data), textSniff)]); err != nil {
+		return nil, err
+	}
+	limits := opts.Limits.Effective()
+	if int64(len(data)) > limits.MaxBytes {
+		return nil, &LimitError{Limit: "input size", Max: limits.MaxBytes}
+	}
+	vars, issues := parseEnvFile(data)
+
+	seen := make(map[string]int)
+	for _, v := range vars {
+		if first, dup := seen[v.Name]; dup {
+			issues = append(issues, Issue{
+				Line:         v.Line,
+				Column:       v.Col,
+				Severity:     SeverityError,
+				RuleID:       "ENV001",
+				Message:      fmt.Sprintf("variable %s is already set on line %d", v.Name, first),
+				SuggestedFix: "Remove one of the assignments",
+			})
+			continue
+		}
+		seen[v.Name] = v.Line
+
+		switch {
+		case v.Value == "":
+			issues = append(issues, Issue{
+				Line:         v.Line,
+				Column:       v.Col,
+				Severity:     SeverityWarning,
+				RuleID:       "ENV002",
+				Message:      fmt.Sprintf("variable %s is empty", v.Name),
+				SuggestedFix: "Set a value or remove the variable",
+			})
+		case !v.Quoted && strings.ContainsAny(v.Value, " \t"):
+			issues = append(issues, Issue{
+				Line:         v.Line,
+				Column:       v.ValueCol,
+				Severity:     SeverityError,
+				RuleID:       "ENV003",
+				Message:      fmt.Sprintf("variable %s has an unquoted value with spaces", v.Name),
+				SuggestedFix: fmt.Sprintf("Quote it: %s=%q", v.Name, v.Value),
+			})
+		}
+
+		if looksLikeSecret(v.Name, v.Value) {
+			issues = append(issues, Issue{
+				Line:         v.Line,
+				Column:       v.Col,
+				Severity:     SeverityError,
+				RuleID:       "ENV004",
+				Message:      fmt.Sprintf("variable %s appears to contain a plaintext secret", v.Name),
+				SuggestedFix: "Load secrets from a secret store or reference them (vault:...) instead of committing them",
+			})
+		}
+	}
+	issues = applyRuleSettings(applySuppressions(issues, scanSuppressions(data), opts.ReportSuppressed), opts)
+	cfg := newParsedConfig()
+	for _, v := range vars {
+		cfg.TopLevel[v.Name] = fieldInfo{Value: v.Value, Line: v.Line, Col: v.Col}
+	}
+	applyMessageTemplates(issues, cfg, opts)
+	if opts.Redact {
+		redactIssues(issues, cfg)
+	}
+	return issues, nil
+}
+
+// parseEnvFile reads NAME=value assignments, with an optional export
+// prefix, # comments, and single- or double-quoted values that may span
+// lines.
+func parseEnvFile(data []byte) ([]envVar, []Issue) {
+	var vars []envVar
+	var issues []Issue
+	lines := splitLines(string(data))
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed[0] == '#' {
+			continue
+		}
+		assignment := strings.TrimPrefix(trimmed, "export ")
+		name, value, ok := strings.Cut(assignment, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !envNamePattern.MatchString(name) {
+			issues = append(issues, Issue{
+				Line:         i + 1,
+				Column:       strings.Index(line, trimmed) + 1,
+				Severity:     SeverityWarning,
+				RuleID:       "ENV005",
+				Message:      fmt.Sprintf("line %q is not a NAME=value assignment", trimmed),
+				SuggestedFix: "Write it as NAME=value, or comment it out with #",
+			})
+			continue
+		}
+
+		v := envVar{Name: name, Line: i + 1, Col: strings.Index(line, name) + 1}
+		trimmedValue := strings.TrimLeft(value, " \t")
+		v.ValueCol = strings.Index(line, "=") + 2 + len(value) - len(trimmedValue)
+		value = trimmedValue
+		if value != "" && (value[0] == '"' || value[0] == '\'') {
+			quote := value[:1]
+			v.Quoted = true
+			body := value[1:]
+			// A quoted value runs to its closing quote, possibly lines later.
+			for !strings.Contains(body, quote) && i+1 < len(lines) {
+				i++
+				body += "\n" + lines[i]
+			}
+			body, _, _ = strings.Cut(body, quote)
+			v.Value = body
+		} else {
+			if j := strings.Index(value, " #"); j >= 0 {
+				value = value[:j]
+			}
+			v.Value = strings.TrimRight(value, " \t")
+		}
+		vars = append(vars, v)
+	}
+	return vars, issues
+}
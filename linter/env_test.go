@@ -0,0 +1,32 @@
+package linter
+
+import "testing"
+
+func TestLintEnvFile(t *testing.T) {
+	content := []byte(`# local overrides
+export APP_NAME=billing
+GREETING=hello world
+LOG_LEVEL=
+DB_PASSWORD=hunter2
+MOTD="multi
+line"
+APP_NAME=payments # again
+not an assignment
+QUOTED="hello world" # fine
+`)
+
+	issues, err := LintNamed(".env.production", content, Options{})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	want := map[string]int{"ENV005": 9, "ENV003": 3, "ENV002": 4, "ENV004": 5, "ENV001": 8}
+	if len(issues) != len(want) {
+		t.Fatalf("expected %d issues, got %+v", len(want), issues)
+	}
+	for _, issue := range issues {
+		if want[issue.RuleID] != issue.Line {
+			t.Errorf("unexpected issue %+v", issue)
+		}
+	}
+}
@@ -0,0 +1,812 @@
+package linter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// expression rules are written in a small subset of CEL:
+//
+//	settings.replicas > 10 && metadata.env != "prod"
+//	feature.rollout > 0 && !has(feature.owner)
+//	metadata.name.matches("^[a-z][a-z0-9-]*$")
+//	metadata.env in ["dev", "staging"]
+//
+// Values are null, booleans, numbers, strings, lists and objects (the
+// config's sections). Unquoted config values read as YAML would: true and
+// false are booleans, null and ~ are null and numbers are numbers. Fields
+// that are not set are null.
+
+// errExprType is returned when an operator or function gets a value of the
+// wrong type. A rule whose expression fails this way does not report.
+var errExprType = errors.New("type mismatch")
+
+type exprToken struct {
+	kind string // "num", "str", "ident", "op" or "eof"
+	text string
+	num  float64
+	pos  int
+}
+
+var exprOps = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "%", "(", ")", "[", "]", ",", "."}
+
+func lexExpr(src string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(src) && (src[j] >= '0' && src[j] <= '9' || src[j] == '.') {
+				j++
+			}
+			n, err := strconv.ParseFloat(src[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("bad number %q at %d", src[i:j], i)
+			}
+			tokens = append(tokens, exprToken{kind: "num", text: src[i:j], num: n, pos: i})
+			i = j
+		case c == '"' || c == '\'':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(src) && src[j] != c; j++ {
+				if src[j] == '\\' && j+1 < len(src) {
+					j++
+					switch src[j] {
+					case 'n':
+						b.WriteByte('\n')
+					case 't':
+						b.WriteByte('\t')
+					default:
+						b.WriteByte(src[j])
+					}
+					continue
+				}
+				b.WriteByte(src[j])
+			}
+			if j == len(src) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			tokens = append(tokens, exprToken{kind: "str", text: b.String(), pos: i})
+			i = j + 1
+		case c == '_' || c < utf8.RuneSelf && unicode.IsLetter(rune(c)):
+			j := i
+			for j < len(src) && (src[j] == '_' || src[j] < utf8.RuneSelf && (unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j])))) {
+				j++
+			}
+			tokens = append(tokens, exprToken{kind: "ident", text: src[i:j], pos: i})
+			i = j
+		default:
+			op := ""
+			for _, candidate := range exprOps {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at %d", c, i)
+			}
+			tokens = append(tokens, exprToken{kind: "op", text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, exprToken{kind: "eof", pos: len(src)}), nil
+}
+
+// exprNode is a node of a compiled expression.
+type exprNode interface {
+	eval(env *exprEnv) (any, error)
+}
+
+type (
+	exprLiteral struct{ value any }
+	exprIdent   struct{ name string }
+	exprMember  struct {
+		target exprNode
+		name   string
+	}
+	exprIndex struct{ target, index exprNode }
+	exprList  struct{ items []exprNode }
+	exprUnary struct {
+		op      string
+		operand exprNode
+	}
+	exprBinary struct {
+		op          string
+		left, right exprNode
+	}
+	exprCall struct {
+		name string
+		// target is the receiver of a method call such as x.matches(re).
+		target exprNode
+		args   []exprNode
+		re     *regexp.Regexp
+	}
+)
+
+const (
+	// maxExprDepth bounds how deeply parentheses, lists, calls and unary
+	// operators nest, so the parser and evaluator stay off the stack limit.
+	maxExprDepth = 256
+	// maxExprTokens bounds an expression's length, and with it how long a
+	// chain of binary operators evaluation recurses through.
+	maxExprTokens = 10_000
+)
+
+// exprParser is a precedence-climbing parser over the tokens of one
+// expression.
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+	depth  int
+	// idents are the root identifiers the expression reads.
+	idents map[string]bool
+}
+
+// compiledExpr is a parsed expression and the root identifiers it reads.
+type compiledExpr struct {
+	root   exprNode
+	idents map[string]bool
+}
+
+func compileExpr(src string) (*compiledExpr, error) {
+	tokens, err := lexExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) > maxExprTokens {
+		return nil, fmt.Errorf("expression is longer than %d tokens", maxExprTokens)
+	}
+	p := &exprParser{tokens: tokens, idents: make(map[string]bool)}
+	root, err := p.parse(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != "eof" {
+		return nil, fmt.Errorf("unexpected %q at %d", tok.text, tok.pos)
+	}
+	return &compiledExpr{root: root, idents: p.idents}, nil
+}
+
+func (p *exprParser) peek() exprToken { return p.tokens[p.pos] }
+
+func (p *exprParser) next() exprToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != "eof" {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *exprParser) expect(op string) error {
+	if tok := p.next(); tok.kind != "op" || tok.text != op {
+		if tok.kind == "eof" {
+			return fmt.Errorf("expected %q at end of expression", op)
+		}
+		return fmt.Errorf("expected %q at %d, got %q", op, tok.pos, tok.text)
+	}
+	return nil
+}
+
+// binaryPrecedence ranks the binary operators; higher binds tighter.
+func binaryPrecedence(tok exprToken) int {
+	if tok.kind == "ident" && tok.text == "in" {
+		return 3
+	}
+	if tok.kind != "op" {
+		return 0
+	}
+	switch tok.text {
+	case "||":
+		return 1
+	case "&&":
+		return 2
+	case "==", "!=", "<", "<=", ">", ">=":
+		return 3
+	case "+", "-":
+		return 4
+	case "*", "/", "%":
+		return 5
+	}
+	return 0
+}
+
+// nest is called on entering a nested expression; the returned func leaves
+// it.
+func (p *exprParser) nest() (func(), error) {
+	if p.depth++; p.depth > maxExprDepth {
+		return nil, fmt.Errorf("expression nests deeper than %d levels at %d", maxExprDepth, p.peek().pos)
+	}
+	return func() { p.depth-- }, nil
+}
+
+func (p *exprParser) parse(minPrec int) (exprNode, error) {
+	leave, err := p.nest()
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		prec := binaryPrecedence(tok)
+		if prec == 0 || prec <= minPrec {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parse(prec)
+		if err != nil {
+			return nil, err
+		}
+		left = exprBinary{op: tok.text, left: left, right: right}
+	}
+}
+
+func (p *exprParser) unary() (exprNode, error) {
+	if tok := p.peek(); tok.kind == "op" && (tok.text == "!" || tok.text == "-") {
+		p.next()
+		leave, err := p.nest()
+		if err != nil {
+			return nil, err
+		}
+		defer leave()
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return exprUnary{op: tok.text, operand: operand}, nil
+	}
+	return p.postfix()
+}
+
+func (p *exprParser) postfix() (exprNode, error) {
+	node, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		switch {
+		case tok.kind == "op" && tok.text == ".":
+			p.next()
+			name := p.next()
+			if name.kind != "ident" {
+				return nil, fmt.Errorf("expected a field name at %d", name.pos)
+			}
+			if next := p.peek(); next.kind == "op" && next.text == "(" {
+				if node, err = p.call(name, node); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			node = exprMember{target: node, name: name.text}
+		case tok.kind == "op" && tok.text == "[":
+			p.next()
+			index, err := p.parse(0)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			node = exprIndex{target: node, index: index}
+		default:
+			return node, nil
+		}
+	}
+}
+
+func (p *exprParser) primary() (exprNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case "num":
+		return exprLiteral{tok.num}, nil
+	case "str":
+		return exprLiteral{tok.text}, nil
+	case "ident":
+		switch tok.text {
+		case "true", "false":
+			return exprLiteral{tok.text == "true"}, nil
+		case "null":
+			return exprLiteral{nil}, nil
+		}
+		if next := p.peek(); next.kind == "op" && next.text == "(" {
+			return p.call(tok, nil)
+		}
+		p.idents[tok.text] = true
+		return exprIdent{tok.text}, nil
+	case "op":
+		switch tok.text {
+		case "(":
+			node, err := p.parse(0)
+			if err != nil {
+				return nil, err
+			}
+			return node, p.expect(")")
+		case "[":
+			var list exprList
+			for {
+				if next := p.peek(); next.kind == "op" && next.text == "]" {
+					p.next()
+					return list, nil
+				}
+				item, err := p.parse(0)
+				if err != nil {
+					return nil, err
+				}
+				list.items = append(list.items, item)
+				if next := p.peek(); next.kind == "op" && next.text == "," {
+					p.next()
+				} else if err := p.expect("]"); err != nil {
+					return nil, err
+				} else {
+					return list, nil
+				}
+			}
+		}
+	case "eof":
+		return nil, errors.New("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", tok.text, tok.pos)
+}
+
+// exprFunctions are the functions and methods expressions can call, by
+// their number of arguments (the receiver of a method counts).
+var exprFunctions = map[string]int{
+	"has":        1,
+	"size":       1,
+	"matches":    2,
+	"startsWith": 2,
+	"endsWith":   2,
+	"contains":   2,
+}
+
+func (p *exprParser) call(name exprToken, target exprNode) (exprNode, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	c := exprCall{name: name.text, target: target}
+	if target != nil {
+		c.args = append(c.args, target)
+	}
+	for {
+		if next := p.peek(); next.kind == "op" && next.text == ")" {
+			p.next()
+			break
+		}
+		arg, err := p.parse(0)
+		if err != nil {
+			return nil, err
+		}
+		c.args = append(c.args, arg)
+		if next := p.peek(); next.kind == "op" && next.text == "," {
+			p.next()
+			continue
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		break
+	}
+	want, ok := exprFunctions[c.name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s at %d", c.name, name.pos)
+	}
+	if len(c.args) != want {
+		return nil, fmt.Errorf("%s takes %d argument(s), got %d", c.name, want, len(c.args))
+	}
+	if c.name == "matches" {
+		if lit, ok := c.args[1].(exprLiteral); ok {
+			pattern, ok := lit.value.(string)
+			if !ok {
+				return nil, fmt.Errorf("matches needs a string pattern")
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("matches: %w", err)
+			}
+			c.re = re
+		}
+	}
+	return c, nil
+}
+
+// exprObject is a section of the config, or a mapping nested in one.
+type exprObject struct {
+	fields map[string]fieldInfo
+	maps   map[string]map[string]fieldInfo
+	line   int
+}
+
+// member returns the value of key: a nested mapping, a field, the fields
+// flattened under key (key.a, key.b) as an object, or null.
+func (o exprObject) member(env *exprEnv, key string) any {
+	if m, ok := o.maps[key]; ok {
+		return exprObject{fields: m}
+	}
+	if f, ok := o.fields[key]; ok {
+		return env.field(f)
+	}
+	var nested map[string]fieldInfo
+	for name, f := range o.fields {
+		if rest, ok := strings.CutPrefix(name, key+"."); ok {
+			if nested == nil {
+				nested = make(map[string]fieldInfo)
+			}
+			nested[rest] = f
+		}
+	}
+	if nested != nil {
+		return exprObject{fields: nested}
+	}
+	return nil
+}
+
+// exprEnv resolves the root identifiers of an expression against one
+// config, and remembers the first field it read so an issue can point at
+// it.
+type exprEnv struct {
+	cfg     parsedConfig
+	feature *featureEntry
+	at      *fieldInfo
+}
+
+func (env *exprEnv) field(f fieldInfo) any {
+	if env.at == nil {
+		env.at = &f
+	}
+	return scalarValue(f)
+}
+
+var exprNumber = regexp.MustCompile(`^[-+]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][-+]?[0-9]+)?$`)
+
+// scalarValue types a config value the way YAML reads it unquoted.
+func scalarValue(f fieldInfo) any {
+	if f.Quoted {
+		return f.Value
+	}
+	switch f.Value {
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	case "null", "Null", "NULL", "~":
+		return nil
+	}
+	if exprNumber.MatchString(f.Value) {
+		n, _ := strconv.ParseFloat(f.Value, 64)
+		return n
+	}
+	return f.Value
+}
+
+func (env *exprEnv) lookup(name string) any {
+	cfg := env.cfg
+	switch name {
+	case "metadata":
+		if cfg.MetadataLine == 0 && len(cfg.Metadata) == 0 {
+			return nil
+		}
+		return exprObject{fields: cfg.Metadata, maps: cfg.MetadataMaps, line: cfg.MetadataLine}
+	case "settings":
+		if cfg.SettingsLine == 0 && len(cfg.Settings) == 0 {
+			return nil
+		}
+		return exprObject{fields: cfg.Settings, line: cfg.SettingsLine}
+	case "features":
+		list := make([]any, len(cfg.Features))
+		for i, f := range cfg.Features {
+			list[i] = exprObject{fields: f.Fields, line: f.Line}
+		}
+		return list
+	case "feature":
+		if env.feature == nil {
+			return nil
+		}
+		return exprObject{fields: env.feature.Fields, line: env.feature.Line}
+	}
+	if s, ok := cfg.Sections[name]; ok {
+		return exprObject{fields: s.Fields, line: s.Line}
+	}
+	if f, ok := cfg.TopLevel[name]; ok {
+		return env.field(f)
+	}
+	return nil
+}
+
+func (n exprLiteral) eval(*exprEnv) (any, error) { return n.value, nil }
+
+func (n exprIdent) eval(env *exprEnv) (any, error) { return env.lookup(n.name), nil }
+
+func (n exprMember) eval(env *exprEnv) (any, error) {
+	target, err := n.target.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch t := target.(type) {
+	case exprObject:
+		return t.member(env, n.name), nil
+	case nil:
+		return nil, nil
+	}
+	return nil, errExprType
+}
+
+func (n exprIndex) eval(env *exprEnv) (any, error) {
+	target, err := n.target.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	index, err := n.index.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch t := target.(type) {
+	case []any:
+		i, ok := index.(float64)
+		if !ok || i != math.Trunc(i) {
+			return nil, errExprType
+		}
+		if i < 0 || int(i) >= len(t) {
+			return nil, nil
+		}
+		return t[int(i)], nil
+	case exprObject:
+		key, ok := index.(string)
+		if !ok {
+			return nil, errExprType
+		}
+		return t.member(env, key), nil
+	case nil:
+		return nil, nil
+	}
+	return nil, errExprType
+}
+
+func (n exprList) eval(env *exprEnv) (any, error) {
+	list := make([]any, len(n.items))
+	for i, item := range n.items {
+		v, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = v
+	}
+	return list, nil
+}
+
+func (n exprUnary) eval(env *exprEnv) (any, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "!":
+		if b, ok := v.(bool); ok {
+			return !b, nil
+		}
+	case "-":
+		if f, ok := toNumber(v); ok {
+			return -f, nil
+		}
+	}
+	return nil, errExprType
+}
+
+func (n exprBinary) eval(env *exprEnv) (any, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "&&" || n.op == "||" {
+		l, ok := left.(bool)
+		if !ok {
+			return nil, errExprType
+		}
+		if l == (n.op == "||") {
+			return l, nil
+		}
+		right, err := n.right.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		if r, ok := right.(bool); ok {
+			return r, nil
+		}
+		return nil, errExprType
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return exprEqual(left, right), nil
+	case "!=":
+		return !exprEqual(left, right), nil
+	case "in":
+		list, ok := right.([]any)
+		if !ok {
+			return nil, errExprType
+		}
+		for _, item := range list {
+			if exprEqual(left, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "<", "<=", ">", ">=":
+		c, ok := exprCompare(left, right)
+		if !ok {
+			return nil, errExprType
+		}
+		switch n.op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		}
+		return c >= 0, nil
+	case "+":
+		if l, ok := left.(string); ok {
+			if r, ok := right.(string); ok {
+				return l + r, nil
+			}
+		}
+	}
+	l, lok := toNumber(left)
+	r, rok := toNumber(right)
+	if !lok || !rok {
+		return nil, errExprType
+	}
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, errExprType
+		}
+		return l / r, nil
+	}
+	if r == 0 {
+		return nil, errExprType
+	}
+	return math.Mod(l, r), nil
+}
+
+func (n exprCall) eval(env *exprEnv) (any, error) {
+	args := make([]any, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	switch n.name {
+	case "has":
+		return args[0] != nil, nil
+	case "size":
+		switch v := args[0].(type) {
+		case string:
+			return float64(utf8.RuneCountInString(v)), nil
+		case []any:
+			return float64(len(v)), nil
+		case exprObject:
+			return float64(len(v.fields) + len(v.maps)), nil
+		}
+		return nil, errExprType
+	}
+	s, ok := args[0].(string)
+	if !ok {
+		// Unquoted values such as 8080 or true still match as written.
+		if args[0] == nil || !isScalar(args[0]) {
+			return nil, errExprType
+		}
+		s = formatScalar(args[0])
+	}
+	arg, ok := args[1].(string)
+	if !ok {
+		return nil, errExprType
+	}
+	switch n.name {
+	case "matches":
+		re := n.re
+		if re == nil {
+			var err error
+			if re, err = regexp.Compile(arg); err != nil {
+				return nil, err
+			}
+		}
+		return re.MatchString(s), nil
+	case "startsWith":
+		return strings.HasPrefix(s, arg), nil
+	case "endsWith":
+		return strings.HasSuffix(s, arg), nil
+	}
+	return strings.Contains(s, arg), nil
+}
+
+func isScalar(v any) bool {
+	switch v.(type) {
+	case bool, float64, string:
+		return true
+	}
+	return false
+}
+
+func formatScalar(v any) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// toNumber reads v as a number; strings that hold one, such as a quoted
+// "10", count.
+func toNumber(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		if exprNumber.MatchString(v) {
+			f, err := strconv.ParseFloat(v, 64)
+			return f, err == nil
+		}
+	}
+	return 0, false
+}
+
+func exprEqual(a, b any) bool {
+	if c, ok := exprCompare(a, b); ok {
+		return c == 0
+	}
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case bool:
+		bb, ok := b.(bool)
+		return ok && a == bb
+	}
+	return false
+}
+
+// exprCompare orders two numbers, or two strings; a number and a string
+// holding a number compare as numbers.
+func exprCompare(a, b any) (int, bool) {
+	if as, ok := a.(string); ok {
+		if bs, ok := b.(string); ok {
+			return strings.Compare(as, bs), true
+		}
+	}
+	x, aok := toNumber(a)
+	y, bok := toNumber(b)
+	if !aok || !bok {
+		return 0, false
+	}
+	switch {
+	case x < y:
+		return -1, true
+	case x > y:
+		return 1, true
+	}
+	return 0, true
+}
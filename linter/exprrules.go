@@ -0,0 +1,135 @@
+package linter
+
+import "fmt"
+
+// ExpressionRule is a custom check written as an expression over the
+// config, so platform teams can add policy from the rc file or a rule pack
+// without writing Go:
+//
+//	{
+//	  "id": "ACME010",
+//	  "expr": "settings.replicas > 10 && metadata.env != \"prod\"",
+//	  "message": "only prod may run more than 10 replicas"
+//	}
+//
+// The expression language is a small subset of CEL: field access
+// (metadata.labels.team, features[0].name), literals, lists, the operators
+// ! - * / % + < <= > >= == != in && || and the functions has(x), size(x),
+// x.matches(re), x.startsWith(s), x.endsWith(s) and x.contains(s).
+type ExpressionRule struct {
+	ID string `json:"id"`
+	// Expr is true when the config breaks the rule. An expression that
+	// reads feature is checked once for each feature entry, with feature
+	// set to it. Expressions that fail on the config, such as a string
+	// compared with a number, do not report.
+	Expr         string `json:"expr"`
+	Message      string `json:"message"`
+	SuggestedFix string `json:"suggestedFix,omitempty"`
+	// Severity defaults to SeverityWarning.
+	Severity Severity `json:"severity,omitempty"`
+}
+
+func (r ExpressionRule) validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("expression rule %q has no id", r.Expr)
+	}
+	if r.Message == "" {
+		return fmt.Errorf("expression rule %s has no message", r.ID)
+	}
+	switch r.Severity {
+	case "", SeverityError, SeverityWarning, SeverityInfo, SeverityHint:
+	default:
+		return fmt.Errorf("expression rule %s: unknown severity %q", r.ID, r.Severity)
+	}
+	if _, err := compileExpr(r.Expr); err != nil {
+		return fmt.Errorf("expression rule %s: %w", r.ID, err)
+	}
+	return nil
+}
+
+// compileExpressions compiles each rule's expression by source.
+func compileExpressions(rules []ExpressionRule) map[string]*compiledExpr {
+	compiled := make(map[string]*compiledExpr, len(rules))
+	for _, r := range rules {
+		if expr, err := compileExpr(r.Expr); err == nil {
+			compiled[r.Expr] = expr
+		}
+	}
+	return compiled
+}
+
+// expression is the compiled form of src: the one Linter compiled with the
+// options when there is one.
+func (o Options) expression(src string) (*compiledExpr, error) {
+	if o.compiled != nil && o.compiled.exprs != nil {
+		if expr, ok := o.compiled.exprs[src]; ok {
+			return expr, nil
+		}
+	}
+	return compileExpr(src)
+}
+
+// mergeExpressionRules adds other's rules to rules, replacing those with the
+// same ID in place.
+func mergeExpressionRules(rules, other []ExpressionRule) []ExpressionRule {
+	merged := append([]ExpressionRule(nil), rules...)
+outer:
+	for _, r := range other {
+		for i := range merged {
+			if merged[i].ID == r.ID {
+				merged[i] = r
+				continue outer
+			}
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// expressionRules wraps the expression rules opts does not turn off to run
+// with the built-in ones. Rules that do not compile are skipped; Validate
+// reports them.
+func expressionRules(opts Options) []namedRule {
+	var rules []namedRule
+	for _, r := range opts.ExpressionRules {
+		r := r
+		expr, err := opts.expression(r.Expr)
+		if err != nil || !opts.reportsRule(r.ID) {
+			continue
+		}
+		rules = append(rules, namedRule{r.ID, func(cfg parsedConfig, _ Options, issues *[]Issue) {
+			if !expr.idents["feature"] {
+				r.check(expr, &exprEnv{cfg: cfg}, 1, 0, issues)
+				return
+			}
+			for i := range cfg.Features {
+				f := &cfg.Features[i]
+				r.check(expr, &exprEnv{cfg: cfg, feature: f}, f.Line, f.Col, issues)
+			}
+		}})
+	}
+	return rules
+}
+
+// check reports an issue when expr is true in env, on the first field the
+// expression read or else at line and col.
+func (r ExpressionRule) check(expr *compiledExpr, env *exprEnv, line, col int, issues *[]Issue) {
+	if v, err := expr.root.eval(env); err != nil || v != true {
+		return
+	}
+	if env.at != nil {
+		line, col = env.at.Line, env.at.Col
+	}
+	severity := r.Severity
+	if severity == "" {
+		severity = SeverityWarning
+	}
+	*issues = append(*issues, Issue{
+		Line:         line,
+		Column:       col,
+		Severity:     severity,
+		RuleID:       r.ID,
+		Message:      r.Message,
+		SuggestedFix: r.SuggestedFix,
+	})
+}
@@ -0,0 +1,169 @@
+package linter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Fix applies safe, behaviour-preserving fixes and returns the rewritten
+// config together with the issues that remain afterwards. Safe fixes are:
+// trailing whitespace and line ending normalization, canonical lowercase
+// booleans for feature flags, and writing out the defaults (settings.timeout
+// and the Options.Defaults catalog) the linter would otherwise assume. Under
+// the prod profile, features without an enabled flag get enabled: false.
+// JSON and XML documents only get whitespace fixes. UTF-16 input and byte
+// order marks are rewritten as plain UTF-8.
+func Fix(data []byte, opts Options) ([]byte, []Issue, error) {
+	data = toUTF8(data, sniffEncoding(data[:min(len(data), textSniff)]))
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	for len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if !looksLikeJSON(data) && !looksLikeXML(data) {
+		cfg, err := parseConfig([]byte(strings.Join(lines, "\n")))
+		if err != nil {
+			return nil, nil, err
+		}
+		fixBooleans(cfg, lines)
+		if p, ok := routeProfile(cfg, opts); ok && p.name == "prod" {
+			lines = fixExplicitEnabled(cfg, lines)
+			if cfg, err = parseConfig([]byte(strings.Join(lines, "\n"))); err != nil {
+				return nil, nil, err
+			}
+		}
+		lines = fixMissingDefaults(cfg, opts, lines)
+	}
+
+	fixed := []byte(strings.Join(lines, "\n") + "\n")
+	issues, err := LintWithOptions(fixed, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fixed, issues, nil
+}
+
+func fixBooleans(cfg parsedConfig, lines []string) {
+	for _, feature := range cfg.Features {
+		enabled, ok := feature.Fields["enabled"]
+		if !ok || !isBool(enabled.Value) || enabled.Value == strings.ToLower(enabled.Value) {
+			continue
+		}
+		line := lines[enabled.Line-1]
+		if i := strings.LastIndex(line, enabled.Value); i != -1 {
+			lines[enabled.Line-1] = line[:i] + strings.ToLower(enabled.Value) + line[i+len(enabled.Value):]
+		}
+	}
+}
+
+// fixExplicitEnabled adds enabled: false to block-style feature entries
+// without an enabled flag, after the entry's last line. Entries are
+// rewritten bottom-up so earlier line numbers stay valid.
+func fixExplicitEnabled(cfg parsedConfig, lines []string) []string {
+	for i := len(cfg.Features) - 1; i >= 0; i-- {
+		feature := cfg.Features[i]
+		if _, ok := feature.Fields["enabled"]; ok || len(feature.Fields) == 0 || strings.Contains(lines[feature.Line-1], "{") {
+			continue
+		}
+		// The entry's keys line up with the first one, after the dash.
+		dashLine := lines[feature.Line-1]
+		if feature.Col < 1 || feature.Col > len(dashLine) || dashLine[feature.Col-1] != '-' {
+			continue
+		}
+		after := dashLine[feature.Col:]
+		if strings.TrimSpace(after) == "" {
+			continue
+		}
+		col := feature.Col + 1 + len(after) - len(strings.TrimLeft(after, " "))
+		last := 0
+		for _, f := range feature.Fields {
+			last = max(last, f.Line)
+		}
+		// Skip past block list items and anything nested under the last key.
+		for last < len(lines) {
+			next := lines[last]
+			trimmed := strings.TrimLeft(next, " ")
+			indent := len(next) - len(trimmed)
+			if trimmed == "" || indent < col-1 || (indent == col-1 && !strings.HasPrefix(trimmed, "-")) {
+				break
+			}
+			last++
+		}
+		out := make([]string, 0, len(lines)+1)
+		out = append(out, lines[:last]...)
+		out = append(out, strings.Repeat(" ", col-1)+"enabled: false")
+		lines = append(out, lines[last:]...)
+	}
+	return lines
+}
+
+// fixMissingDefaults appends missing defaulted keys to the metadata and
+// settings blocks, indented like each block's last field. Later blocks are
+// written first so earlier line numbers stay valid.
+func fixMissingDefaults(cfg parsedConfig, opts Options, lines []string) []string {
+	values := opts.defaults()
+	inserts := make(map[string][]string)
+	for _, key := range missingDefaults(cfg, opts) {
+		section, field, _ := splitDefaultKey(key)
+		inserts[section] = append(inserts[section], field+": "+yamlScalar(values[key]))
+	}
+	sections := []string{"metadata", "settings"}
+	if cfg.MetadataLine < cfg.SettingsLine {
+		sections[0], sections[1] = sections[1], sections[0]
+	}
+	for _, section := range sections {
+		if len(inserts[section]) > 0 {
+			fields, header := sectionFields(cfg, section)
+			lines = appendToSection(fields, header, inserts[section], lines)
+		}
+	}
+	return lines
+}
+
+// yamlScalar quotes value when it would not read back as written.
+func yamlScalar(value string) string {
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	if value == "" || value != strings.TrimSpace(value) || strings.ContainsAny(value[:1], "-?:,[]{}#&*!|>'\"%@`") ||
+		strings.Contains(value, ": ") || strings.Contains(value, " #") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// appendToSection inserts keys after the last field of a section.
+func appendToSection(fields map[string]fieldInfo, headerLine int, keys []string, lines []string) []string {
+	last := fieldInfo{}
+	minCol := 0
+	for _, f := range fields {
+		if f.Line > last.Line {
+			last = f
+		}
+		if minCol == 0 || f.Col < minCol {
+			minCol = f.Col
+		}
+	}
+	// Only append after a plain top-level key, indented under the section
+	// header; anywhere else the keys would land in the wrong block.
+	if last.Value == "" || last.Col != minCol || last.Col < 1 || headerLine < 1 {
+		return lines
+	}
+	header := lines[headerLine-1]
+	body := lines[last.Line-1]
+	if last.Col <= len(header)-len(strings.TrimLeft(header, " \t"))+1 || strings.HasPrefix(strings.TrimSpace(body), "-") {
+		return lines
+	}
+
+	indent := strings.Repeat(" ", last.Col-1)
+	out := make([]string, 0, len(lines)+len(keys))
+	out = append(out, lines[:last.Line]...)
+	for _, key := range keys {
+		out = append(out, indent+key)
+	}
+	return append(out, lines[last.Line:]...)
+}
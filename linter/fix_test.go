@@ -0,0 +1,92 @@
+package linter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFix(t *testing.T) {
+	content := "metadata:\n  name: awesome   \r\n  env: prod\nsettings:\n  replicas: 2\nfeatures:\n  - name: a\n    enabled: True\n\n\n"
+
+	fixed, issues, err := Fix([]byte(content), Options{DefaultTimeout: 45})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	want := "metadata:\n  name: awesome\n  env: prod\nsettings:\n  replicas: 2\n  timeout: 45\nfeatures:\n  - name: a\n    enabled: true\n"
+	if string(fixed) != want {
+		t.Errorf("unexpected fix result:\n%q\nwant:\n%q", fixed, want)
+	}
+	if len(issues) != 0 {
+		t.Errorf("expected no remaining issues, got %+v", issues)
+	}
+}
+
+func TestFixJSONOnlyWhitespace(t *testing.T) {
+	content := "{\n  \"settings\": {\"replicas\": 1}  \n}"
+
+	fixed, _, err := Fix([]byte(content), Options{})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if string(fixed) != "{\n  \"settings\": {\"replicas\": 1}\n}\n" {
+		t.Errorf("unexpected fix result %q", fixed)
+	}
+}
+
+func TestFixWritesDefaultsCatalog(t *testing.T) {
+	content := "settings:\n  replicas: 2\nmetadata:\n  name: app\n  env: prod\n"
+	opts := Options{Defaults: map[string]any{"settings.timeout": float64(60), "settings.logLevel": "info", "metadata.owner": "@acme/platform"}}
+
+	before, err := LintWithOptions([]byte("metadata:\n  name: app\n  env: prod\nsettings:\n  replicas: 2\n  timeout: 5\n  logLevel: debug\n"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(before) != 1 || before[0].RuleID != "DEF001" || before[0].Severity != SeverityInfo || before[0].Path != "metadata.owner" {
+		t.Errorf("expected one DEF001 info issue for metadata.owner, got %+v", before)
+	}
+
+	fixed, issues, err := Fix([]byte(content), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "settings:\n  replicas: 2\n  logLevel: info\n  timeout: 60\nmetadata:\n  name: app\n  env: prod\n  owner: \"@acme/platform\"\n"
+	if string(fixed) != want {
+		t.Errorf("unexpected fix result:\n%q\nwant:\n%q", fixed, want)
+	}
+	if len(issues) != 0 {
+		t.Errorf("expected the defaults to be written out, got %+v", issues)
+	}
+}
+
+func TestFixProdExplicitEnabled(t *testing.T) {
+	content := "metadata:\n  name: app\n  env: prod\nsettings:\n  replicas: 2\n  timeout: 5\nfeatures:\n  - name: a\n    requires:\n      - b\n  - name: b\n    enabled: true\n  - name: c\n"
+	opts := Options{Profiles: []string{"prod"}}
+
+	before, err := LintWithOptions([]byte(content), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prod []string
+	for _, issue := range before {
+		if issue.RuleID == "PROD001" {
+			prod = append(prod, issue.Path)
+		}
+	}
+	if want := []string{"features[0]", "features[2]"}; !reflect.DeepEqual(prod, want) {
+		t.Errorf("expected PROD001 on %v, got %+v", want, before)
+	}
+
+	fixed, issues, err := Fix([]byte(content), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "metadata:\n  name: app\n  env: prod\nsettings:\n  replicas: 2\n  timeout: 5\nfeatures:\n  - name: a\n    requires:\n      - b\n    enabled: false\n  - name: b\n    enabled: true\n  - name: c\n    enabled: false\n"
+	if string(fixed) != want {
+		t.Errorf("unexpected fix result:\n%q\nwant:\n%q", fixed, want)
+	}
+	// a now requires b while disabled, which is fine; nothing else is left.
+	if len(issues) != 0 {
+		t.Errorf("expected no remaining issues, got %+v", issues)
+	}
+}
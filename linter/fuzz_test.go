@@ -0,0 +1,61 @@
+package linter
+
+import (
+	"bytes"
+	"testing"
+)
+
+func fuzzSeeds(f *testing.F) {
+	f.Add([]byte("metadata:\n  name: a\n  env: prod\nsettings:\n  replicas: 1\nfeatures:\n  - name: x\n    enabled: true\n"))
+	f.Add([]byte(`{"metadata": {"name": "a"}, "features": [{"name": "x", "enabled": true}]}`))
+	f.Add([]byte("features:\n  {\n  }\n  - {name: a}\n}\n"))
+	f.Add([]byte("settings:\n  replicas: -1\n  timeout: 99999999999999999999\n"))
+	f.Add([]byte("metadata:\n  token: vault:secret/app#\n"))
+	f.Add([]byte(recursiveAlias))
+	f.Add([]byte(aliasBomb(9)))
+	f.Add([]byte("0: \r0"))
+}
+
+func FuzzParse(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		cfg, err := parseConfig(data)
+		if err != nil {
+			return
+		}
+		lines := bytes.Count(data, []byte{'\n'}) + 1
+		for _, feature := range cfg.Features {
+			if feature.Line < 1 || feature.Line > lines {
+				t.Fatalf("feature line %d outside 1..%d", feature.Line, lines)
+			}
+		}
+	})
+}
+
+func FuzzLintBytes(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		issues, err := LintBytes(data)
+		if err != nil {
+			return
+		}
+		lines := bytes.Count(data, []byte{'\n'}) + 1
+		for _, issue := range issues {
+			if issue.Line < 1 || issue.Line > lines || issue.Column < 0 {
+				t.Fatalf("issue %+v outside the input (%d lines)", issue, lines)
+			}
+			if issue.RuleID == "" || issue.Message == "" {
+				t.Fatalf("issue without rule or message: %+v", issue)
+			}
+		}
+
+		fixed, _, err := Fix(data, Options{})
+		if err != nil {
+			t.Fatalf("Fix failed on lintable input: %v", err)
+		}
+		again, _, err := Fix(fixed, Options{})
+		if err != nil || !bytes.Equal(again, fixed) {
+			t.Fatalf("Fix is not idempotent:\n%q\n%q", fixed, again)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package linter
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Ways issues in generated files are reported; see Generated.
+const (
+	GeneratedDowngrade = "downgrade"
+	GeneratedSeparate  = "separate"
+)
+
+// Generated marks files a generator writes. Fixing their issues means
+// changing the generator, so they are reported apart from hand-written
+// files: with GeneratedDowngrade (the default) each severity is lowered one
+// step (see Downgrade); with GeneratedSeparate they are reported after the
+// other files, in their own section, and never fail the run. Only callers
+// that lint files by path (the CLI) apply it.
+type Generated struct {
+	// Paths are globs as for MatchPath, relative to where the linter runs.
+	Paths []string `json:"paths,omitempty"`
+	Mode  string   `json:"mode,omitempty"`
+}
+
+// Matches reports whether name is a generated file.
+func (g Generated) Matches(name string) bool {
+	name = filepath.ToSlash(filepath.Clean(name))
+	for _, pattern := range g.Paths {
+		if MatchPath(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g Generated) merge(other Generated) Generated {
+	if len(other.Paths) > 0 {
+		g.Paths = other.Paths
+	}
+	if other.Mode != "" {
+		g.Mode = other.Mode
+	}
+	return g
+}
+
+func (g Generated) validate() error {
+	switch g.Mode {
+	case "", GeneratedDowngrade, GeneratedSeparate:
+	default:
+		return fmt.Errorf("unknown generated mode %q (use %s or %s)", g.Mode, GeneratedDowngrade, GeneratedSeparate)
+	}
+	for _, pattern := range g.Paths {
+		if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+			return fmt.Errorf("generated path %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Downgrade returns issues with each severity lowered one step: errors to
+// warnings, warnings to info and info to hints.
+func Downgrade(issues []Issue) []Issue {
+	out := make([]Issue, len(issues))
+	for i, issue := range issues {
+		switch issue.Severity {
+		case SeverityError:
+			issue.Severity = SeverityWarning
+		case SeverityWarning:
+			issue.Severity = SeverityInfo
+		case SeverityInfo:
+			issue.Severity = SeverityHint
+		}
+		out[i] = issue
+	}
+	return out
+}
+
+// MatchPath matches a slash-separated name against pattern, where a **
+// segment matches any number of directories and other segments follow
+// path.Match.
+func MatchPath(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, err := path.Match(pattern[0], name[0])
+	return err == nil && ok && matchSegments(pattern[1:], name[1:])
+}
@@ -0,0 +1,195 @@
+package linter
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Governance configures the ownership rules (GOV001–GOV003). Every check is
+// off until enabled, either field by field or through a Preset; fields set
+// alongside a preset add to it.
+type Governance struct {
+	// Preset names one of GovernancePresets.
+	Preset string `json:"preset,omitempty"`
+	// RequireOwner requires metadata.owner to be an email address or a team
+	// handle (@org/team, team:name or group:name).
+	RequireOwner bool `json:"requireOwner,omitempty"`
+	// OwnerPattern replaces the built-in owner formats with a regexp.
+	OwnerPattern string `json:"ownerPattern,omitempty"`
+	// RequireRepository requires metadata.repository to be an http(s) URL.
+	RequireRepository bool `json:"requireRepository,omitempty"`
+	// RequiredLabels lists keys metadata.labels must set.
+	RequiredLabels []string `json:"requiredLabels,omitempty"`
+}
+
+var governancePresets = map[string]Governance{
+	"ownership": {RequireOwner: true},
+	"standard":  {RequireOwner: true, RequireRepository: true},
+	"strict":    {RequireOwner: true, RequireRepository: true, RequiredLabels: []string{"team", "tier"}},
+}
+
+// GovernancePresets lists the names of the built-in governance presets.
+func GovernancePresets() []string {
+	names := make([]string, 0, len(governancePresets))
+	for name := range governancePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var (
+	ownerEmailPattern  = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[a-zA-Z]{2,}$`)
+	ownerHandlePattern = regexp.MustCompile(`^(@[a-zA-Z0-9][-a-zA-Z0-9]*/[a-zA-Z0-9][-_.a-zA-Z0-9]*|(team|group):[a-zA-Z0-9][-_.a-zA-Z0-9]*)$`)
+)
+
+// effective returns g with its preset expanded.
+func (g Governance) effective() Governance {
+	p := governancePresets[g.Preset]
+	p.Preset = g.Preset
+	p.RequireOwner = p.RequireOwner || g.RequireOwner
+	p.RequireRepository = p.RequireRepository || g.RequireRepository
+	p.OwnerPattern = g.OwnerPattern
+	for _, label := range g.RequiredLabels {
+		if !contains(p.RequiredLabels, label) {
+			p.RequiredLabels = append(p.RequiredLabels[:len(p.RequiredLabels):len(p.RequiredLabels)], label)
+		}
+	}
+	return p
+}
+
+func (g Governance) enabled() bool {
+	g = g.effective()
+	return g.RequireOwner || g.RequireRepository || len(g.RequiredLabels) > 0
+}
+
+func (g Governance) merge(other Governance) Governance {
+	if other.Preset != "" {
+		g.Preset = other.Preset
+	}
+	if other.RequireOwner {
+		g.RequireOwner = true
+	}
+	if other.OwnerPattern != "" {
+		g.OwnerPattern = other.OwnerPattern
+	}
+	if other.RequireRepository {
+		g.RequireRepository = true
+	}
+	if len(other.RequiredLabels) > 0 {
+		g.RequiredLabels = other.RequiredLabels
+	}
+	return g
+}
+
+func (g Governance) validate() error {
+	if _, ok := governancePresets[g.Preset]; g.Preset != "" && !ok {
+		return fmt.Errorf("unknown governance preset %q", g.Preset)
+	}
+	if g.OwnerPattern != "" {
+		if _, err := regexp.Compile(g.OwnerPattern); err != nil {
+			return fmt.Errorf("governance ownerPattern: %w", err)
+		}
+	}
+	return nil
+}
+
+// ownerPattern returns the compiled OwnerPattern, or nil when unset.
+func (o Options) ownerPattern() *regexp.Regexp {
+	if o.compiled != nil {
+		return o.compiled.ownerPattern
+	}
+	if o.Governance.OwnerPattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(o.Governance.OwnerPattern)
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+func validateGovernance(cfg parsedConfig, opts Options, issues *[]Issue) {
+	gov := opts.Governance.effective()
+	baseLine := cfg.MetadataLine
+	if baseLine == 0 {
+		baseLine = 1
+	}
+
+	if gov.RequireOwner {
+		owner, ok := cfg.Metadata["owner"]
+		validOwner := ownerEmailPattern.MatchString(owner.Value) || ownerHandlePattern.MatchString(owner.Value)
+		if re := opts.ownerPattern(); re != nil {
+			validOwner = re.MatchString(owner.Value)
+		}
+		switch {
+		case !ok || owner.Value == "":
+			*issues = append(*issues, Issue{
+				Line:         baseLine,
+				Severity:     SeverityError,
+				RuleID:       "GOV001",
+				Message:      "metadata.owner is required",
+				SuggestedFix: "Set metadata.owner to a team handle (e.g. @acme/payments) or an email address",
+			})
+		case !validOwner:
+			*issues = append(*issues, Issue{
+				Line:         owner.Line,
+				Column:       owner.Col,
+				Severity:     SeverityError,
+				RuleID:       "GOV001",
+				Message:      fmt.Sprintf("metadata.owner %q is not an email address or team handle", owner.Value),
+				SuggestedFix: "Use a team handle (e.g. @acme/payments) or an email address",
+			})
+		}
+	}
+
+	if gov.RequireRepository {
+		repo, ok := cfg.Metadata["repository"]
+		switch {
+		case !ok || repo.Value == "":
+			*issues = append(*issues, Issue{
+				Line:         baseLine,
+				Severity:     SeverityError,
+				RuleID:       "GOV002",
+				Message:      "metadata.repository is required",
+				SuggestedFix: "Set metadata.repository to the source repository URL",
+			})
+		case !isHTTPURL(repo.Value):
+			*issues = append(*issues, Issue{
+				Line:         repo.Line,
+				Column:       repo.Col,
+				Severity:     SeverityError,
+				RuleID:       "GOV002",
+				Message:      fmt.Sprintf("metadata.repository %q is not an http(s) URL", repo.Value),
+				SuggestedFix: "Use the repository's web URL, e.g. https://github.com/acme/payments",
+			})
+		}
+	}
+
+	if len(gov.RequiredLabels) > 0 {
+		labels := cfg.MetadataMaps["labels"]
+		var missing []string
+		for _, label := range gov.RequiredLabels {
+			if labels[label].Value == "" {
+				missing = append(missing, label)
+			}
+		}
+		if len(missing) > 0 {
+			*issues = append(*issues, Issue{
+				Line:         baseLine,
+				Severity:     SeverityError,
+				RuleID:       "GOV003",
+				Message:      fmt.Sprintf("metadata.labels is missing required labels: %s", strings.Join(missing, ", ")),
+				SuggestedFix: fmt.Sprintf("Add metadata.labels.%s", missing[0]),
+			})
+		}
+	}
+}
+
+func isHTTPURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
@@ -0,0 +1,133 @@
+package linter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Hash fingerprints what a config says rather than how it is written, so
+// pipelines can tell a real change from a reformat: comments, key order,
+// whitespace, quoting, anchors and the text encoding do not change it, and a
+// JSON config hashes like the same config written in YAML. Any change to a
+// key or a value does, including its type ("1" and 1 differ). Every document
+// of a multi-document YAML stream counts. XML configs hash the tree Parse
+// returns.
+//
+// It fails on the input Parse fails on and on syntax errors.
+func Hash(data []byte) (string, error) {
+	cfg, err := Parse(data)
+	if err != nil {
+		return "", err
+	}
+	var value any
+	if cfg.Format == "xml" {
+		for _, issue := range cfg.ParseIssues {
+			if issue.Severity == SeverityError {
+				return "", errors.New(issue.Message)
+			}
+		}
+		value = xmlValue(cfg)
+	} else if value, err = yamlValue(toUTF8(data, sniffEncoding(data[:min(len(data), textSniff)]))); err != nil {
+		return "", err
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// yamlValue decodes every document in data. Aliases and merge keys are
+// expanded by the decoder, which also rejects excessive aliasing.
+func yamlValue(data []byte) (any, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var docs []any
+	for {
+		var doc any
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, normalizeValue(doc))
+	}
+	if len(docs) == 1 {
+		return docs[0], nil
+	}
+	return docs, nil
+}
+
+// normalizeValue makes a decoded value encodable as JSON: mappings with
+// non-string keys get their keys formatted, and infinities and NaN become
+// the strings YAML writes them as.
+func normalizeValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, child := range v {
+			v[key] = normalizeValue(child)
+		}
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for key, child := range v {
+			m[fmt.Sprint(key)] = normalizeValue(child)
+		}
+		return m
+	case []any:
+		for i, child := range v {
+			v[i] = normalizeValue(child)
+		}
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return ".nan"
+		case math.IsInf(v, 1):
+			return ".inf"
+		case math.IsInf(v, -1):
+			return "-.inf"
+		}
+	}
+	return v
+}
+
+// xmlValue is the content of an XML config as nested maps of field values.
+func xmlValue(cfg *Config) map[string]any {
+	fields := func(fs map[string]Field) map[string]string {
+		m := make(map[string]string, len(fs))
+		for key, f := range fs {
+			m[key] = f.Value
+		}
+		return m
+	}
+	out := make(map[string]any)
+	for key, f := range cfg.TopLevel {
+		out[key] = f.Value
+	}
+	for name, s := range cfg.Sections {
+		out[name] = fields(s.Fields)
+	}
+	if cfg.Metadata != nil {
+		out["metadata"] = fields(cfg.Metadata.Fields)
+	}
+	if cfg.Settings != nil {
+		out["settings"] = fields(cfg.Settings.Fields)
+	}
+	if cfg.Features != nil {
+		features := make([]map[string]string, len(cfg.Features))
+		for i, f := range cfg.Features {
+			features[i] = fields(f.Fields)
+		}
+		out["features"] = features
+	}
+	return out
+}
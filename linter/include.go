@@ -0,0 +1,167 @@
+package linter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// IncludeLoader opens a document referenced by include: or extends:. ref is
+// already resolved against the referencing document.
+type IncludeLoader func(ctx context.Context, ref string) (io.ReadCloser, error)
+
+var includeClient = &http.Client{Timeout: 30 * time.Second}
+
+// LoadInclude is the IncludeLoader for local paths and http(s) URLs.
+func LoadInclude(ctx context.Context, ref string) (io.ReadCloser, error) {
+	if !isHTTPURL(ref) {
+		return os.Open(ref)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ref, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := includeClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", ref, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// LintIncludes lints the document read from r as the effective document its
+// include:/extends: references produce. Referenced documents (paths relative
+// to name, or URLs) are loaded with load, resolved recursively and merged
+// first, in order, with the document itself on top, using
+// Options.OverlayMerge. Every issue has File set to the document it is in.
+// Cycles (INC001), chains deeper than Limits.MaxIncludeDepth (INC002) and
+// references that cannot be loaded (INC003) are reported on the reference.
+// It returns ctx's error once ctx is done, as LintContext does.
+func LintIncludes(ctx context.Context, name string, r io.Reader, load IncludeLoader, opts Options) ([]Issue, error) {
+	root, err := parseStream(contextReader{ctx: ctx, r: r}, opts.Limits)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(root.Includes) == 0 {
+		issues := lintParsedContext(ctx, root, opts)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for i := range issues {
+			issues[i].File = name
+		}
+		return issues, nil
+	}
+
+	res := includeResolver{ctx: ctx, load: load, opts: opts}
+	key := name
+	if !isHTTPURL(name) {
+		key = filepath.Clean(name)
+	}
+	if err := res.resolve(name, root, []string{key}); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	issues := lintMerged(res.docs, opts, false)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return append(issues, res.problems...), nil
+}
+
+type includeResolver struct {
+	ctx      context.Context
+	load     IncludeLoader
+	opts     Options
+	docs     []namedConfig
+	problems []Issue
+}
+
+// resolve appends cfg's includes depth first, then cfg itself. stack holds
+// the chain of documents leading to name, name included.
+func (r *includeResolver) resolve(name string, cfg parsedConfig, stack []string) error {
+	for _, ref := range cfg.Includes {
+		target, refErr := resolveIncludeRef(name, ref.Value)
+		problem := func(rule, message string) {
+			r.problems = append(r.problems, Issue{
+				File:     name,
+				Line:     ref.Line,
+				Column:   ref.Col,
+				Severity: SeverityError,
+				RuleID:   rule,
+				Message:  message,
+			})
+		}
+		if refErr != nil {
+			problem("INC003", fmt.Sprintf("cannot load %s: %v", ref.Value, refErr))
+			continue
+		}
+		if contains(stack, target) {
+			problem("INC001", fmt.Sprintf("include cycle: %s -> %s", strings.Join(stack, " -> "), target))
+			continue
+		}
+		if limit := r.opts.Limits.Effective().MaxIncludeDepth; len(stack) > limit {
+			problem("INC002", fmt.Sprintf("%s is nested deeper than the include limit of %d", ref.Value, limit))
+			continue
+		}
+
+		rc, err := r.load(r.ctx, target)
+		if err != nil {
+			problem("INC003", fmt.Sprintf("cannot load %s: %v", ref.Value, err))
+			continue
+		}
+		sub, err := parseStream(contextReader{ctx: r.ctx, r: rc}, r.opts.Limits)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", target, err)
+		}
+		if err := r.resolve(target, sub, append(stack[:len(stack):len(stack)], target)); err != nil {
+			return err
+		}
+	}
+	r.docs = append(r.docs, namedConfig{name: name, cfg: cfg})
+	return nil
+}
+
+// resolveIncludeRef resolves ref against the document named from. A remote
+// document may only reference documents relative to it on the same host, so
+// a fetched config cannot read local files or make the linter fetch from
+// elsewhere.
+func resolveIncludeRef(from, ref string) (string, error) {
+	if !isHTTPURL(from) {
+		if isHTTPURL(ref) || filepath.IsAbs(ref) {
+			return ref, nil
+		}
+		return filepath.Join(filepath.Dir(from), ref), nil
+	}
+	if filepath.IsAbs(ref) || strings.HasPrefix(ref, "/") || strings.HasPrefix(ref, `\`) {
+		return "", fmt.Errorf("a remote document can only include relative references")
+	}
+	base, err := url.Parse(from)
+	if err != nil {
+		return "", err
+	}
+	rel, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	target := base.ResolveReference(rel)
+	if rel.IsAbs() || target.Scheme != base.Scheme || target.Host != base.Host {
+		return "", fmt.Errorf("a remote document can only include relative references")
+	}
+	return target.String(), nil
+}
@@ -0,0 +1,171 @@
+package linter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LintINI lints an INI-style config with the same rules as YAML and JSON
+// configs. [metadata] and [settings] map to their sections. Features are
+// either flags under [features] (beta = true) or one section per feature,
+// [features.beta] or [feature "beta"]; a comma-separated requires lists
+// dependencies. Keys before the first section are top-level keys.
+//
+// Malformed section headers (INI001) and lines that are not key = value
+// pairs (INI002) are reported where they are.
+func LintINI(data []byte, opts Options) ([]Issue, error) {
+	if err := checkText(data[:min(len(data), textSniff)]); err != nil {
+		return nil, err
+	}
+	limits := opts.Limits.Effective()
+	if int64(len(data)) > limits.MaxBytes {
+		return nil, &LimitError{Limit: "input size", Max: limits.MaxBytes}
+	}
+	cfg, issues, err := parseINI(data, limits)
+	if err != nil {
+		return nil, err
+	}
+	return lintParsed(cfg, opts, issues...), nil
+}
+
+func parseINI(data []byte, limits Limits) (parsedConfig, []Issue, error) {
+	cfg := newParsedConfig()
+	cfg.Frontend = "ini"
+	var issues []Issue
+	// fields is where keys of the current section go; nil while a malformed
+	// header's keys are skipped.
+	fields := cfg.TopLevel
+	section := ""
+	flags := false // in [features], where each key is a feature flag
+
+	for i, line := range splitLines(string(data)) {
+		lineNo := i + 1
+		if lineNo > limits.MaxLines {
+			return cfg, nil, &LimitError{Limit: "line count", Max: int64(limits.MaxLines), Line: lineNo}
+		}
+		if len(line) > limits.MaxLineBytes {
+			return cfg, nil, &LimitError{Limit: "line length", Max: int64(limits.MaxLineBytes), Line: lineNo}
+		}
+		if len(cfg.Features) > limits.MaxFeatures {
+			return cfg, nil, &LimitError{Limit: "feature count", Max: int64(limits.MaxFeatures), Line: lineNo}
+		}
+		cfg.Lines = lineNo
+		if rest, directive, ok := cutSuppression(line); ok {
+			if s, ok := parseSuppression(directive, lineNo); ok {
+				cfg.Suppressions = append(cfg.Suppressions, s)
+			}
+			line = rest
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed[0] == ';' || trimmed[0] == '#' {
+			continue
+		}
+		col := strings.Index(line, trimmed) + 1
+
+		if trimmed[0] == '[' {
+			name, ok := strings.CutSuffix(trimmed, "]")
+			name = strings.TrimSpace(strings.TrimPrefix(name, "["))
+			if !ok || name == "" || strings.ContainsAny(name, "[]") {
+				issues = append(issues, Issue{
+					Line:         lineNo,
+					Column:       col,
+					Severity:     SeverityError,
+					RuleID:       "INI001",
+					Message:      fmt.Sprintf("malformed section header %q", trimmed),
+					SuggestedFix: "Write section headers as [name] on a line of their own",
+				})
+				fields, section, flags = nil, "", false
+				continue
+			}
+			fields, section, flags = cfg.iniSection(name, lineNo, col)
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, "=")
+		if k, v, colon := strings.Cut(trimmed, ":"); colon && (!ok || len(k) < len(key)) {
+			key, value, ok = k, v, true
+		}
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			issues = append(issues, Issue{
+				Line:         lineNo,
+				Column:       col,
+				Severity:     SeverityWarning,
+				RuleID:       "INI002",
+				Message:      fmt.Sprintf("line %q is not a key = value pair and is ignored", trimmed),
+				SuggestedFix: "Write it as key = value, or comment it out with ;",
+			})
+			continue
+		}
+		value = stripINIComment(strings.TrimSpace(value))
+		info := fieldInfo{Value: trimQuotes(value), Line: lineNo, Col: col}
+		info.Quoted = info.Value != value
+		if key == "requires" && section == "feature" && !strings.HasPrefix(info.Value, "[") {
+			info.Value = "[" + info.Value + "]"
+		}
+		if !flags && fields == nil {
+			continue
+		}
+		if flags {
+			cfg.Features = append(cfg.Features, featureEntry{
+				Fields: map[string]fieldInfo{"name": {Value: key, Line: lineNo, Col: col}, "enabled": info},
+				Line:   lineNo,
+				Col:    col,
+			})
+			continue
+		}
+		fields[key] = info
+	}
+	return cfg, issues, nil
+}
+
+// iniSection starts the section named by a header and returns where its
+// keys go, the kind of section and whether its keys are feature flags.
+func (cfg *parsedConfig) iniSection(name string, line, col int) (fields map[string]fieldInfo, section string, flags bool) {
+	switch name {
+	case "metadata":
+		cfg.MetadataLine = line
+		return cfg.Metadata, "metadata", false
+	case "settings":
+		cfg.SettingsLine = line
+		return cfg.Settings, "settings", false
+	case "features":
+		cfg.FeaturesLine = line
+		return nil, "features", true
+	}
+
+	feature, ok := strings.CutPrefix(name, "features.")
+	if !ok {
+		if rest, isFeature := strings.CutPrefix(name, "feature "); isFeature {
+			feature, ok = trimQuotes(strings.TrimSpace(rest)), true
+		}
+	}
+	if ok {
+		if cfg.FeaturesLine == 0 {
+			cfg.FeaturesLine = line
+		}
+		entry := featureEntry{Fields: make(map[string]fieldInfo), Line: line, Col: col}
+		if feature != "" {
+			entry.Fields["name"] = fieldInfo{Value: feature, Line: line, Col: col}
+		}
+		cfg.Features = append(cfg.Features, entry)
+		return entry.Fields, "feature", false
+	}
+
+	s := sectionInfo{Fields: make(map[string]fieldInfo), Line: line}
+	cfg.Sections[name] = s
+	return s.Fields, "section", false
+}
+
+// stripINIComment removes a trailing ; or # comment from an unquoted value.
+func stripINIComment(value string) string {
+	if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "'") {
+		return value
+	}
+	for _, marker := range []string{" ;", " #", "\t;", "\t#"} {
+		if i := strings.Index(value, marker); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+	}
+	return value
+}
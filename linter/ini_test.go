@@ -0,0 +1,44 @@
+package linter
+
+import "testing"
+
+func TestLintINI(t *testing.T) {
+	content := []byte(`; legacy billing service
+[metadata]
+name = billing
+env = prod ; inline comment
+
+[settings]
+replicas = 0
+timeout = 30
+
+[features]
+beta = yes
+
+[feature "search"]
+enabled = true
+requires = beta, ghost
+
+[broken
+stray line
+`)
+
+	issues, err := LintNamed("billing.ini", content, Options{})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	want := map[string]int{"INI001": 17, "INI002": 18, "SET003": 7, "FEAT003": 11, "FEAT004": 15, "FEAT005": 15}
+	got := make(map[string]int)
+	for _, issue := range issues {
+		got[issue.RuleID] = issue.Line
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %+v", want, issues)
+	}
+	for id, line := range want {
+		if got[id] != line {
+			t.Errorf("expected %s on line %d, got %+v", id, line, issues)
+		}
+	}
+}
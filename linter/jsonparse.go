@@ -0,0 +1,155 @@
+package linter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// jsonMaxNesting bounds how deep decodeJSON recurses, like encoding/json.
+const jsonMaxNesting = 10000
+
+// decodeJSON is decodeYAML for JSON documents. It reads text token by token,
+// mapping each token's byte offset to its line and column, so objects on one
+// line, nested objects and tab indentation all report accurate positions.
+// It reports false and leaves cfg alone when text is not a JSON object. A
+// syntax error is added to cfg.ParseIssues, and cfg is built from what was
+// read before it.
+func decodeJSON(text []byte, cfg *parsedConfig) bool {
+	d := jsonDecoder{dec: json.NewDecoder(bytes.NewReader(text)), text: text, lineStarts: []int{0}}
+	d.dec.UseNumber()
+	for i, c := range text {
+		if c == '\n' {
+			d.lineStarts = append(d.lineStarts, i+1)
+		}
+	}
+	root, err := d.value(0)
+	if err == nil {
+		if _, err = d.dec.Token(); errors.Is(err, io.EOF) {
+			err = nil
+		} else if err == nil {
+			err = errJSONTrailing
+		}
+	}
+	if err != nil {
+		cfg.ParseIssues = append(cfg.ParseIssues, d.syntaxIssue(err))
+	}
+	if root == nil || root.Kind != yaml.MappingNode {
+		return false
+	}
+	buildConfig(root, text, cfg)
+	return true
+}
+
+// jsonDecoder turns a JSON token stream into yaml.Node trees, so JSON and
+// YAML documents share one builder.
+type jsonDecoder struct {
+	dec        *json.Decoder
+	text       []byte
+	lineStarts []int // byte offset of each line
+}
+
+var (
+	errJSONNesting  = errors.New("json nested too deeply")
+	errJSONTrailing = errors.New("unexpected content after the top-level value")
+)
+
+// value reads the next value. On error inside an array or object it returns
+// the node with everything read before the error.
+func (d *jsonDecoder) value(depth int) (*yaml.Node, error) {
+	if depth > jsonMaxNesting {
+		return nil, errJSONNesting
+	}
+	line, col := d.position(d.tokenStart())
+	tok, err := d.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	node := &yaml.Node{Line: line, Column: col}
+	switch tok := tok.(type) {
+	case json.Delim:
+		node.Kind, node.Style = yaml.SequenceNode, yaml.FlowStyle
+		if tok == '{' {
+			node.Kind = yaml.MappingNode
+		}
+		for d.dec.More() {
+			if node.Kind == yaml.MappingNode {
+				key, err := d.value(depth + 1)
+				if err != nil {
+					return node, err
+				}
+				node.Content = append(node.Content, key)
+			}
+			child, err := d.value(depth + 1)
+			if err != nil {
+				if child != nil {
+					node.Content = append(node.Content, child)
+				} else if node.Kind == yaml.MappingNode {
+					node.Content = node.Content[:len(node.Content)-1]
+				}
+				return node, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		if _, err := d.dec.Token(); err != nil {
+			return node, err
+		}
+	case string:
+		node.Kind, node.Tag, node.Value, node.Style = yaml.ScalarNode, "!!str", tok, yaml.DoubleQuotedStyle
+	case json.Number:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!float", tok.String()
+		if _, err := tok.Int64(); err == nil {
+			node.Tag = "!!int"
+		}
+	case bool:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!bool", "false"
+		if tok {
+			node.Value = "true"
+		}
+	case nil:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!null", "null"
+	}
+	return node, nil
+}
+
+// syntaxIssue reports a decoder error as SYN001 where the decoder stopped.
+func (d *jsonDecoder) syntaxIssue(err error) Issue {
+	offset := int(d.dec.InputOffset())
+	msg := err.Error()
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = int(syntaxErr.Offset)
+	case errors.Is(err, errJSONTrailing):
+		// The decoder has read the extra token; point at its start.
+		offset = int(d.dec.InputOffset()) - 1
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		offset, msg = len(d.text), "unexpected end of input"
+	}
+	line, col := d.position(min(offset, max(len(d.text)-1, 0)))
+	return Issue{Line: line, Column: col, Severity: SeverityError, RuleID: "SYN001", Message: "malformed JSON: " + msg}
+}
+
+// tokenStart returns the offset of the next token, past the whitespace and
+// separators the decoder has not consumed yet.
+func (d *jsonDecoder) tokenStart() int {
+	i := int(d.dec.InputOffset())
+	for i < len(d.text) {
+		switch d.text[i] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			i++
+			continue
+		}
+		break
+	}
+	return i
+}
+
+func (d *jsonDecoder) position(offset int) (line, col int) {
+	i := sort.Search(len(d.lineStarts), func(i int) bool { return d.lineStarts[i] > offset }) - 1
+	return i + 1, offset - d.lineStarts[i] + 1
+}
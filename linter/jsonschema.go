@@ -0,0 +1,746 @@
+package linter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// schemaSteps bounds the subschemas applied in one check, so aliases and
+// recursive references cannot make a small document expensive.
+const schemaSteps = 1 << 20
+
+// jsonSchema is a compiled schema. A nil field is a keyword the schema does
+// not use.
+type jsonSchema struct {
+	// always is set for the schemas true and false.
+	always *bool
+
+	ref  string
+	refd *jsonSchema
+
+	types    []string
+	enum     []any
+	hasConst bool
+	constant any
+
+	properties           map[string]*jsonSchema
+	required             []string
+	additionalProperties *jsonSchema
+	patternProperties    []patternSchema
+	minProperties        *int
+	maxProperties        *int
+
+	prefixItems []*jsonSchema
+	items       *jsonSchema
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+
+	allOf      []*jsonSchema
+	anyOf      []*jsonSchema
+	oneOf      []*jsonSchema
+	not        *jsonSchema
+	ifSchema   *jsonSchema
+	thenSchema *jsonSchema
+	elseSchema *jsonSchema
+}
+
+type patternSchema struct {
+	re     *regexp.Regexp
+	schema *jsonSchema
+}
+
+var schemaTypes = map[string]bool{"object": true, "array": true, "string": true, "number": true, "integer": true, "boolean": true, "null": true}
+
+// SchemaFrom reads a JSON Schema written as JSON or YAML into the JSON
+// Options.Schema holds. The schema is checked with the keywords that
+// constrain structure and values:
+//
+//	type, enum, const
+//	properties, required, additionalProperties, patternProperties,
+//	minProperties, maxProperties
+//	items, prefixItems, minItems, maxItems, uniqueItems
+//	minLength, maxLength, pattern
+//	minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf
+//	allOf, anyOf, oneOf, not, if, then, else
+//	$ref to the schema itself ("#/$defs/port", "#/definitions/port")
+//
+// Other keywords, such as format and the annotations, are ignored, as a
+// validator may ignore them.
+func SchemaFrom(data []byte) (json.RawMessage, error) {
+	if json.Valid(data) {
+		return json.RawMessage(data), nil
+	}
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	out, err := json.Marshal(normalizeValue(doc))
+	if err != nil {
+		return nil, fmt.Errorf("schema is not a JSON value: %w", err)
+	}
+	return out, nil
+}
+
+// compiledSchema is the compiled Options.Schema: the one Linter compiled
+// with the options when there is one.
+func (o Options) compiledSchema() (*jsonSchema, error) {
+	if o.compiled != nil && o.compiled.jsonSchema != nil {
+		return o.compiled.jsonSchema, nil
+	}
+	return compileSchema(o.Schema)
+}
+
+type schemaCompiler struct {
+	root any
+	// byPointer holds the subschemas compiled so far by JSON pointer, so a
+	// recursive $ref resolves to the schema being compiled.
+	byPointer map[string]*jsonSchema
+	pending   []*jsonSchema
+}
+
+func compileSchema(src []byte) (*jsonSchema, error) {
+	var root any
+	if err := json.Unmarshal(src, &root); err != nil {
+		return nil, fmt.Errorf("schema: %w", err)
+	}
+	c := &schemaCompiler{root: root, byPointer: make(map[string]*jsonSchema)}
+	s, err := c.compile(root, "#")
+	if err != nil {
+		return nil, err
+	}
+	for len(c.pending) > 0 {
+		next := c.pending[len(c.pending)-1]
+		c.pending = c.pending[:len(c.pending)-1]
+		if next.refd, err = c.resolve(next.ref); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// resolve compiles the subschema a local $ref points to.
+func (c *schemaCompiler) resolve(ref string) (*jsonSchema, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("schema: $ref %q: only references within the schema (#...) are supported", ref)
+	}
+	if s, ok := c.byPointer["#"+pointer]; ok {
+		return s, nil
+	}
+	node := c.root
+	canonical := "#"
+	if pointer != "" {
+		if !strings.HasPrefix(pointer, "/") {
+			return nil, fmt.Errorf("schema: $ref %q: anchors are not supported", ref)
+		}
+		for _, token := range strings.Split(pointer[1:], "/") {
+			token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+			canonical += "/" + escapePointer(token)
+			switch v := node.(type) {
+			case map[string]any:
+				node, ok = v[token]
+			case []any:
+				i, err := strconv.Atoi(token)
+				ok = err == nil && i >= 0 && i < len(v)
+				if ok {
+					node = v[i]
+				}
+			default:
+				ok = false
+			}
+			if !ok {
+				return nil, fmt.Errorf("schema: $ref %q does not resolve", ref)
+			}
+		}
+	}
+	return c.compile(node, canonical)
+}
+
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+func (c *schemaCompiler) compile(v any, pointer string) (*jsonSchema, error) {
+	if s, ok := c.byPointer[pointer]; ok {
+		return s, nil
+	}
+	s := &jsonSchema{}
+	c.byPointer[pointer] = s
+	if b, ok := v.(bool); ok {
+		s.always = &b
+		return s, nil
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("schema: %s must be an object or a boolean", pointer)
+	}
+	fail := func(keyword, want string) error {
+		return fmt.Errorf("schema: %s/%s must be %s", pointer, keyword, want)
+	}
+	sub := func(keyword string) (*jsonSchema, error) {
+		raw, ok := m[keyword]
+		if !ok {
+			return nil, nil
+		}
+		return c.compile(raw, pointer+"/"+keyword)
+	}
+	list := func(keyword string) ([]*jsonSchema, error) {
+		raw, ok := m[keyword]
+		if !ok {
+			return nil, nil
+		}
+		items, ok := raw.([]any)
+		if !ok || len(items) == 0 {
+			return nil, fail(keyword, "a non-empty array of schemas")
+		}
+		out := make([]*jsonSchema, len(items))
+		for i, item := range items {
+			var err error
+			if out[i], err = c.compile(item, pointer+"/"+keyword+"/"+strconv.Itoa(i)); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	}
+	number := func(keyword string) (*float64, error) {
+		raw, ok := m[keyword]
+		if !ok {
+			return nil, nil
+		}
+		f, ok := raw.(float64)
+		if !ok {
+			return nil, fail(keyword, "a number")
+		}
+		return &f, nil
+	}
+	count := func(keyword string) (*int, error) {
+		raw, ok := m[keyword]
+		if !ok {
+			return nil, nil
+		}
+		f, ok := raw.(float64)
+		if !ok || f < 0 || f != math.Trunc(f) {
+			return nil, fail(keyword, "a non-negative integer")
+		}
+		n := int(f)
+		return &n, nil
+	}
+	regex := func(keyword, src string) (*regexp.Regexp, error) {
+		re, err := regexp.Compile(src)
+		if err != nil {
+			return nil, fmt.Errorf("schema: %s/%s: %w", pointer, keyword, err)
+		}
+		return re, nil
+	}
+
+	var err error
+	if raw, ok := m["$ref"]; ok {
+		if s.ref, ok = raw.(string); !ok {
+			return nil, fail("$ref", "a string")
+		}
+		c.pending = append(c.pending, s)
+	}
+	switch t := m["type"].(type) {
+	case nil:
+	case string:
+		s.types = []string{t}
+	case []any:
+		for _, name := range t {
+			name, _ := name.(string)
+			s.types = append(s.types, name)
+		}
+	default:
+		return nil, fail("type", "a type name or an array of them")
+	}
+	for _, name := range s.types {
+		if !schemaTypes[name] {
+			return nil, fmt.Errorf("schema: %s/type: unknown type %q", pointer, name)
+		}
+	}
+	if raw, ok := m["enum"]; ok {
+		if s.enum, ok = raw.([]any); !ok {
+			return nil, fail("enum", "an array")
+		}
+	}
+	s.constant, s.hasConst = m["const"]
+
+	if raw, ok := m["properties"]; ok {
+		props, ok := raw.(map[string]any)
+		if !ok {
+			return nil, fail("properties", "an object")
+		}
+		s.properties = make(map[string]*jsonSchema, len(props))
+		for name, prop := range props {
+			if s.properties[name], err = c.compile(prop, pointer+"/properties/"+escapePointer(name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if raw, ok := m["required"]; ok {
+		names, ok := raw.([]any)
+		if !ok {
+			return nil, fail("required", "an array of strings")
+		}
+		for _, name := range names {
+			name, ok := name.(string)
+			if !ok {
+				return nil, fail("required", "an array of strings")
+			}
+			s.required = append(s.required, name)
+		}
+	}
+	if raw, ok := m["patternProperties"]; ok {
+		props, ok := raw.(map[string]any)
+		if !ok {
+			return nil, fail("patternProperties", "an object")
+		}
+		patterns := make([]string, 0, len(props))
+		for p := range props {
+			patterns = append(patterns, p)
+		}
+		sort.Strings(patterns)
+		for _, p := range patterns {
+			re, err := regex("patternProperties", p)
+			if err != nil {
+				return nil, err
+			}
+			ps, err := c.compile(props[p], pointer+"/patternProperties/"+escapePointer(p))
+			if err != nil {
+				return nil, err
+			}
+			s.patternProperties = append(s.patternProperties, patternSchema{re, ps})
+		}
+	}
+	if s.additionalProperties, err = sub("additionalProperties"); err != nil {
+		return nil, err
+	}
+	if s.prefixItems, err = list("prefixItems"); err != nil {
+		return nil, err
+	}
+	if s.items, err = sub("items"); err != nil {
+		return nil, err
+	}
+	if s.not, err = sub("not"); err != nil {
+		return nil, err
+	}
+	if s.ifSchema, err = sub("if"); err != nil {
+		return nil, err
+	}
+	if s.thenSchema, err = sub("then"); err != nil {
+		return nil, err
+	}
+	if s.elseSchema, err = sub("else"); err != nil {
+		return nil, err
+	}
+	for keyword, dst := range map[string]*[]*jsonSchema{"allOf": &s.allOf, "anyOf": &s.anyOf, "oneOf": &s.oneOf} {
+		if *dst, err = list(keyword); err != nil {
+			return nil, err
+		}
+	}
+	for keyword, dst := range map[string]**int{
+		"minProperties": &s.minProperties, "maxProperties": &s.maxProperties,
+		"minItems": &s.minItems, "maxItems": &s.maxItems,
+		"minLength": &s.minLength, "maxLength": &s.maxLength,
+	} {
+		if *dst, err = count(keyword); err != nil {
+			return nil, err
+		}
+	}
+	for keyword, dst := range map[string]**float64{
+		"minimum": &s.minimum, "maximum": &s.maximum,
+		"exclusiveMinimum": &s.exclusiveMinimum, "exclusiveMaximum": &s.exclusiveMaximum,
+		"multipleOf": &s.multipleOf,
+	} {
+		if *dst, err = number(keyword); err != nil {
+			return nil, err
+		}
+	}
+	if s.multipleOf != nil && *s.multipleOf <= 0 {
+		return nil, fail("multipleOf", "greater than 0")
+	}
+	if raw, ok := m["uniqueItems"]; ok {
+		if s.uniqueItems, ok = raw.(bool); !ok {
+			return nil, fail("uniqueItems", "a boolean")
+		}
+	}
+	if raw, ok := m["pattern"]; ok {
+		src, ok := raw.(string)
+		if !ok {
+			return nil, fail("pattern", "a string")
+		}
+		if s.pattern, err = regex("pattern", src); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// validateSchema checks the document against Options.Schema, reporting
+// each violation as SCHEMA001 on the line of the value, or of the mapping
+// lacking a required key. Only YAML and JSON documents small enough to
+// keep their source are checked.
+func validateSchema(cfg parsedConfig, opts Options, issues *[]Issue) {
+	if len(cfg.Source) > 0 {
+		checkSchema(cfg.Source, opts, issues)
+	}
+}
+
+func checkSchema(text []byte, opts Options, issues *[]Issue) {
+	schema, err := opts.compiledSchema()
+	if err != nil {
+		// Validate reports it.
+		return
+	}
+	root, ok := schemaInstance(text)
+	if !ok {
+		return
+	}
+	c := schemaCheck{steps: schemaSteps}
+	c.validate(schema, root, root, "", issues)
+}
+
+// schemaInstance decodes the document the schema applies to. Malformed and
+// empty documents are not checked; their syntax issues stand instead.
+func schemaInstance(text []byte) (root *yaml.Node, ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	var doc yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(text)).Decode(&doc); err != nil {
+		return nil, false
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || checkAliases(doc.Content[0]) != nil {
+		return nil, false
+	}
+	return doc.Content[0], true
+}
+
+type schemaCheck struct {
+	steps int
+}
+
+// validate appends to issues the ways node breaks s. at is where the node
+// is reported, its key for mapping values, and path its key path.
+func (c *schemaCheck) validate(s *jsonSchema, node, at *yaml.Node, path string, issues *[]Issue) {
+	if c.steps--; c.steps < 0 {
+		return
+	}
+	node = resolveAlias(node)
+	report := func(format string, args ...any) {
+		*issues = append(*issues, Issue{
+			Line:     at.Line,
+			Column:   at.Column,
+			Severity: SeverityError,
+			RuleID:   "SCHEMA001",
+			Message:  schemaSubject(path) + " " + fmt.Sprintf(format, args...),
+			Path:     path,
+		})
+	}
+	if s.always != nil {
+		if !*s.always {
+			report("is not allowed by the schema")
+		}
+		return
+	}
+	if s.refd != nil {
+		c.validate(s.refd, node, at, path, issues)
+	}
+
+	kind := instanceKind(node)
+	if len(s.types) > 0 && !typeAllows(s.types, kind) {
+		report("must be %s, got %s", joinOr(s.types), kind)
+		return
+	}
+	if len(s.enum) > 0 || s.hasConst {
+		value := instanceValue(node)
+		if s.hasConst && !reflect.DeepEqual(value, s.constant) {
+			report("must be %s", schemaLiteral(s.constant))
+		}
+		if len(s.enum) > 0 && !containsValue(s.enum, value) {
+			literals := make([]string, len(s.enum))
+			for i, v := range s.enum {
+				literals[i] = schemaLiteral(v)
+			}
+			report("must be one of %s", strings.Join(literals, ", "))
+		}
+	}
+
+	switch kind {
+	case "object":
+		c.validateObject(s, node, at, path, report, issues)
+	case "array":
+		c.validateArray(s, node, path, report, issues)
+	case "string":
+		n := utf8.RuneCountInString(node.Value)
+		if s.minLength != nil && n < *s.minLength {
+			report("must be at least %d characters long", *s.minLength)
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			report("must be at most %d characters long", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(node.Value) {
+			report("must match %s", s.pattern)
+		}
+	case "number", "integer":
+		var f float64
+		if node.Decode(&f) == nil {
+			validateNumber(s, f, report)
+		}
+	}
+
+	for _, sub := range s.allOf {
+		c.validate(sub, node, at, path, issues)
+	}
+	if len(s.anyOf) > 0 && c.matching(s.anyOf, node, at, path, 1) == 0 {
+		report("does not match any schema in anyOf")
+	}
+	if len(s.oneOf) > 0 {
+		switch c.matching(s.oneOf, node, at, path, 2) {
+		case 0:
+			report("does not match any schema in oneOf")
+		case 2:
+			report("matches more than one schema in oneOf")
+		}
+	}
+	if s.not != nil && c.matches(s.not, node, at, path) {
+		report("must not match the schema in not")
+	}
+	if s.ifSchema != nil {
+		if c.matches(s.ifSchema, node, at, path) {
+			if s.thenSchema != nil {
+				c.validate(s.thenSchema, node, at, path, issues)
+			}
+		} else if s.elseSchema != nil {
+			c.validate(s.elseSchema, node, at, path, issues)
+		}
+	}
+}
+
+func (c *schemaCheck) validateObject(s *jsonSchema, node, at *yaml.Node, path string, report func(string, ...any), issues *[]Issue) {
+	pairs := mappingPairs(node, nil, 0)
+	set := make(map[string]bool, len(pairs))
+	for _, p := range pairs {
+		set[p.key.Value] = true
+	}
+	for _, name := range s.required {
+		if !set[name] {
+			*issues = append(*issues, Issue{
+				Line:     at.Line,
+				Column:   at.Column,
+				Severity: SeverityError,
+				RuleID:   "SCHEMA001",
+				Message:  schemaSubject(joinKeyPath(path, name)) + " is required",
+				Path:     joinKeyPath(path, name),
+			})
+		}
+	}
+	if s.minProperties != nil && len(pairs) < *s.minProperties {
+		report("must have at least %d keys", *s.minProperties)
+	}
+	if s.maxProperties != nil && len(pairs) > *s.maxProperties {
+		report("must have at most %d keys", *s.maxProperties)
+	}
+	for _, p := range pairs {
+		key := joinKeyPath(path, p.key.Value)
+		matched := false
+		if prop, ok := s.properties[p.key.Value]; ok {
+			c.validate(prop, p.value, p.at, key, issues)
+			matched = true
+		}
+		for _, pp := range s.patternProperties {
+			if pp.re.MatchString(p.key.Value) {
+				c.validate(pp.schema, p.value, p.at, key, issues)
+				matched = true
+			}
+		}
+		if !matched && s.additionalProperties != nil {
+			c.validate(s.additionalProperties, p.value, p.at, key, issues)
+		}
+	}
+}
+
+func (c *schemaCheck) validateArray(s *jsonSchema, node *yaml.Node, path string, report func(string, ...any), issues *[]Issue) {
+	items := node.Content
+	if s.minItems != nil && len(items) < *s.minItems {
+		report("must have at least %d items", *s.minItems)
+	}
+	if s.maxItems != nil && len(items) > *s.maxItems {
+		report("must have at most %d items", *s.maxItems)
+	}
+	for i, item := range items {
+		itemPath := path + "[" + strconv.Itoa(i) + "]"
+		switch {
+		case i < len(s.prefixItems):
+			c.validate(s.prefixItems[i], item, item, itemPath, issues)
+		case s.items != nil:
+			c.validate(s.items, item, item, itemPath, issues)
+		}
+	}
+	if s.uniqueItems {
+		seen := make([]any, 0, len(items))
+		for _, item := range items {
+			value := instanceValue(resolveAlias(item))
+			if containsValue(seen, value) {
+				report("must not repeat items")
+				break
+			}
+			seen = append(seen, value)
+		}
+	}
+}
+
+func validateNumber(s *jsonSchema, f float64, report func(string, ...any)) {
+	if s.minimum != nil && f < *s.minimum {
+		report("must be at least %v", *s.minimum)
+	}
+	if s.maximum != nil && f > *s.maximum {
+		report("must be at most %v", *s.maximum)
+	}
+	if s.exclusiveMinimum != nil && f <= *s.exclusiveMinimum {
+		report("must be greater than %v", *s.exclusiveMinimum)
+	}
+	if s.exclusiveMaximum != nil && f >= *s.exclusiveMaximum {
+		report("must be less than %v", *s.exclusiveMaximum)
+	}
+	if s.multipleOf != nil {
+		if q := f / *s.multipleOf; math.Abs(q-math.Round(q)) > 1e-9 {
+			report("must be a multiple of %v", *s.multipleOf)
+		}
+	}
+}
+
+// matching counts the schemas node matches, stopping at limit.
+func (c *schemaCheck) matching(schemas []*jsonSchema, node, at *yaml.Node, path string, limit int) int {
+	n := 0
+	for _, s := range schemas {
+		if c.matches(s, node, at, path) {
+			if n++; n == limit {
+				break
+			}
+		}
+	}
+	return n
+}
+
+func (c *schemaCheck) matches(s *jsonSchema, node, at *yaml.Node, path string) bool {
+	var issues []Issue
+	c.validate(s, node, at, path, &issues)
+	return len(issues) == 0
+}
+
+// instanceKind is the JSON type of a node; YAML tags without one, such as
+// timestamps, are strings.
+func instanceKind(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch node.ShortTag() {
+	case "!!null":
+		return "null"
+	case "!!bool":
+		return "boolean"
+	case "!!int":
+		return "integer"
+	case "!!float":
+		var f float64
+		if node.Decode(&f) == nil && f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+	return "string"
+}
+
+func typeAllows(types []string, kind string) bool {
+	for _, t := range types {
+		if t == kind || t == "number" && kind == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// instanceValue is node as encoding/json decodes its JSON form, to compare
+// with enum and const.
+func instanceValue(node *yaml.Node) any {
+	var v any
+	if node.Decode(&v) != nil {
+		return nil
+	}
+	data, err := json.Marshal(normalizeValue(v))
+	if err != nil {
+		return nil
+	}
+	var out any
+	json.Unmarshal(data, &out)
+	return out
+}
+
+func containsValue(values []any, v any) bool {
+	for _, candidate := range values {
+		if reflect.DeepEqual(candidate, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func schemaLiteral(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func schemaSubject(path string) string {
+	if path == "" {
+		return "the document"
+	}
+	return path
+}
+
+func joinKeyPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// joinOr lists names as "a, b or c", with an article before the first.
+func joinOr(names []string) string {
+	list := names[0]
+	if len(names) > 1 {
+		list = strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+	}
+	switch names[0] {
+	case "object", "array", "integer":
+		return "an " + list
+	case "null":
+		return list
+	}
+	return "a " + list
+}
@@ -0,0 +1,139 @@
+package linter
+
+import (
+	"fmt"
+	"io"
+)
+
+// Limits caps how much input the parser accepts. Zero fields fall back to
+// DefaultLimits, which are far above any hand-written config but stop
+// pathological input before it exhausts memory.
+type Limits struct {
+	MaxBytes     int64 `json:"maxBytes,omitempty"`
+	MaxLineBytes int   `json:"maxLineBytes,omitempty"`
+	MaxLines     int   `json:"maxLines,omitempty"`
+	MaxDepth     int   `json:"maxDepth,omitempty"`
+	MaxFeatures  int   `json:"maxFeatures,omitempty"`
+	// MaxIncludeDepth bounds include:/extends: chains (see LintIncludes).
+	MaxIncludeDepth int `json:"maxIncludeDepth,omitempty"`
+}
+
+// DefaultLimits are applied to every field left unset.
+var DefaultLimits = Limits{
+	MaxBytes:     512 << 20,
+	MaxLineBytes: 16 << 20,
+	MaxLines:     10_000_000,
+	MaxDepth:     64,
+	MaxFeatures:  100_000,
+
+	MaxIncludeDepth: 16,
+}
+
+// LimitError reports the first limit an input exceeded. Line is 0 when the
+// limit is not tied to a position (input size).
+type LimitError struct {
+	Limit string
+	Max   int64
+	Line  int
+}
+
+func (e *LimitError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s exceeds the limit of %d", e.Line, e.Limit, e.Max)
+	}
+	return fmt.Sprintf("%s exceeds the limit of %d", e.Limit, e.Max)
+}
+
+// Effective returns l with unset fields filled from DefaultLimits.
+func (l Limits) Effective() Limits {
+	if l.MaxBytes <= 0 {
+		l.MaxBytes = DefaultLimits.MaxBytes
+	}
+	if l.MaxLineBytes <= 0 {
+		l.MaxLineBytes = DefaultLimits.MaxLineBytes
+	}
+	if l.MaxLines <= 0 {
+		l.MaxLines = DefaultLimits.MaxLines
+	}
+	if l.MaxDepth <= 0 {
+		l.MaxDepth = DefaultLimits.MaxDepth
+	}
+	if l.MaxFeatures <= 0 {
+		l.MaxFeatures = DefaultLimits.MaxFeatures
+	}
+	if l.MaxIncludeDepth <= 0 {
+		l.MaxIncludeDepth = DefaultLimits.MaxIncludeDepth
+	}
+	return l
+}
+
+// merge returns l with every field set in other taking precedence.
+func (l Limits) merge(other Limits) Limits {
+	if other.MaxBytes > 0 {
+		l.MaxBytes = other.MaxBytes
+	}
+	if other.MaxLineBytes > 0 {
+		l.MaxLineBytes = other.MaxLineBytes
+	}
+	if other.MaxLines > 0 {
+		l.MaxLines = other.MaxLines
+	}
+	if other.MaxDepth > 0 {
+		l.MaxDepth = other.MaxDepth
+	}
+	if other.MaxFeatures > 0 {
+		l.MaxFeatures = other.MaxFeatures
+	}
+	if other.MaxIncludeDepth > 0 {
+		l.MaxIncludeDepth = other.MaxIncludeDepth
+	}
+	return l
+}
+
+// limitedReader fails with a LimitError once more than max bytes are read.
+type limitedReader struct {
+	r    io.Reader
+	max  int64
+	read int64
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	n, err := lr.r.Read(p)
+	lr.read += int64(n)
+	if lr.read > lr.max {
+		return n, &LimitError{Limit: "input size", Max: lr.max}
+	}
+	return n, err
+}
+
+// depthTracker approximates nesting depth from indentation (YAML) and open
+// brackets (JSON); the deeper of the two counts.
+type depthTracker struct {
+	indents  []int
+	brackets int
+}
+
+func (d *depthTracker) observe(indentCol int, line string) int {
+	for len(d.indents) > 0 && d.indents[len(d.indents)-1] >= indentCol {
+		d.indents = d.indents[:len(d.indents)-1]
+	}
+	d.indents = append(d.indents, indentCol)
+
+	peak := d.brackets
+	inString := false
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && inString:
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			d.brackets++
+			peak = max(peak, d.brackets)
+		case c == '}' || c == ']':
+			d.brackets--
+		}
+	}
+	return max(len(d.indents), peak)
+}
@@ -2,47 +2,149 @@ package linter
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
 )
 
+// Version identifies the lint engine in reports and API responses.
+const Version = "1.0.0"
+
 type Severity string
 
 const (
 	SeverityError   Severity = "error"
 	SeverityWarning Severity = "warn"
+	// SeverityStyle marks purely stylistic findings. They are only reported
+	// when Options.Style is set, and never fail a run.
+	SeverityStyle Severity = "style"
+	// SeverityInfo marks informational findings, such as a key that will
+	// take its default value.
+	SeverityInfo Severity = "info"
+	// SeverityHint marks the least important findings, which only suggest
+	// a different way to write the config.
+	SeverityHint Severity = "hint"
 )
 
-const defaultTimeout = 30
+// severityRanks orders the severities for thresholds; style and unknown
+// severities rank lowest, below every threshold.
+var severityRanks = map[Severity]int{
+	SeverityError:   4,
+	SeverityWarning: 3,
+	SeverityInfo:    2,
+	SeverityHint:    1,
+}
+
+// AtLeast reports whether s is as severe as threshold or more, ranking
+// error, warn, info, then hint. Style findings are below every threshold.
+func (s Severity) AtLeast(threshold Severity) bool {
+	return severityRanks[s] >= severityRanks[threshold]
+}
+
+// Fails reports whether any issue is at least as severe as threshold.
+func Fails(issues []Issue, threshold Severity) bool {
+	for _, issue := range issues {
+		if issue.Severity.AtLeast(threshold) {
+			return true
+		}
+	}
+	return false
+}
 
 var allowedEnvironments = []string{"dev", "staging", "prod"}
 
 type Issue struct {
+	// File is set when a lint spans several documents (overlays) and names
+	// the one the issue is in.
+	File         string   `json:"file,omitempty"`
 	Line         int      `json:"line"`
+	Column       int      `json:"column,omitempty"`
 	Severity     Severity `json:"severity"`
+	RuleID       string   `json:"ruleId,omitempty"`
 	Message      string   `json:"message"`
 	SuggestedFix string   `json:"suggestedFix,omitempty"`
+	// Path is the dotted key path the issue is about, e.g.
+	// settings.replicas or features[2].enabled. It is empty for issues
+	// not tied to a key.
+	Path string `json:"path,omitempty"`
+	// Suppressed marks an issue an inline suppression covers. Such issues
+	// are only returned with Options.ReportSuppressed; see SplitSuppressed.
+	Suppressed bool `json:"suppressed,omitempty"`
 }
 
 type fieldInfo struct {
 	Value string
 	Line  int
+	Col   int
+	// Quoted is set when a YAML value was written in quotes.
+	Quoted bool
+	// Style and Comments are kept for tools that rewrite the config; only
+	// the YAML decoder sets them.
+	Style    string
+	Comments Comments
 }
 
 type featureEntry struct {
-	Fields map[string]fieldInfo
-	Line   int
+	Fields   map[string]fieldInfo
+	Line     int
+	Col      int
+	Comments Comments
+}
+
+type sectionInfo struct {
+	Fields   map[string]fieldInfo
+	Line     int
+	Comments Comments
 }
 
 type parsedConfig struct {
 	Metadata     map[string]fieldInfo
 	MetadataLine int
+	// MetadataMaps holds the keys of mappings nested in metadata (labels,
+	// annotations) by mapping name. Their keys are in Metadata as well.
+	MetadataMaps map[string]map[string]fieldInfo
 	Settings     map[string]fieldInfo
 	SettingsLine int
 	Features     []featureEntry
 	FeaturesLine int
+	// TopLevel holds scalar keys at the document root (apiVersion, kind) and
+	// Sections any root mapping other than the three built-in ones.
+	TopLevel map[string]fieldInfo
+	Sections map[string]sectionInfo
+	// Suppressions are the inline configlint-disable comments.
+	Suppressions []suppression
+	// Includes are the include:/extends: references, in order.
+	Includes []fieldInfo
+	// Lines is the number of lines read.
+	Lines int
+	// JSON is set when the document is JSON rather than YAML. Frontend names
+	// the other format it was read from ("ini", "properties", "xml"), if any.
+	JSON     bool
+	Frontend string
+	// ParseIssues are problems found while reading a format parseStream
+	// detects on its own, such as malformed XML.
+	ParseIssues []Issue
+	// Comments are the document's own comments and KeyComments those on
+	// the metadata, settings and features keys, from the YAML decoder.
+	Comments    Comments
+	KeyComments map[string]Comments
+	// Source is the YAML or JSON text the config was decoded from, for the
+	// schema check. It is not kept for larger documents or other formats.
+	Source []byte
+}
+
+func newParsedConfig() parsedConfig {
+	return parsedConfig{
+		Metadata: make(map[string]fieldInfo),
+		Settings: make(map[string]fieldInfo),
+		TopLevel: make(map[string]fieldInfo),
+		Sections: make(map[string]sectionInfo),
+	}
 }
 
 func LintConfig(path string) ([]Issue, error) {
@@ -53,95 +155,314 @@ func LintConfig(path string) ([]Issue, error) {
 	return LintBytes(data)
 }
 
+// LintNamed lints data using the frontend selected by name's extension:
+// Terraform variable files get the tfvars profile, .ini and .conf files are
+// read as INI, .properties files as Java properties, .xml files as XML,
+// dotenv files (.env, .env.*, *.env) as dotenv, and everything else is
+// treated as a YAML or JSON config. UTF-16 input and byte order marks are
+// transcoded to UTF-8 and reported as an ENC001 info issue.
+func LintNamed(name string, data []byte, opts Options) ([]Issue, error) {
+	var lint func([]byte, Options) ([]Issue, error)
+	switch {
+	case strings.HasSuffix(name, ".tfvars"):
+		lint = func(data []byte, opts Options) ([]Issue, error) { return LintTFVars(data, false, opts) }
+	case strings.HasSuffix(name, ".tfvars.json"):
+		lint = func(data []byte, opts Options) ([]Issue, error) { return LintTFVars(data, true, opts) }
+	case strings.HasSuffix(name, ".ini"), strings.HasSuffix(name, ".conf"):
+		lint = LintINI
+	case strings.HasSuffix(name, ".properties"):
+		lint = LintProperties
+	case strings.HasSuffix(name, ".xml"):
+		lint = LintXML
+	case IsEnvFile(name):
+		lint = LintEnvFile
+	default:
+		return LintWithOptions(data, opts)
+	}
+	// The YAML and JSON parser transcodes on its own; the other frontends
+	// get UTF-8 here.
+	encoding := sniffEncoding(data[:min(len(data), textSniff)])
+	issues, err := lint(toUTF8(data, encoding), opts)
+	if err != nil || encoding == "" {
+		return issues, err
+	}
+	return applyRuleSettings(append([]Issue{encodingIssue(encoding)}, issues...), opts), nil
+}
+
 func LintBytes(data []byte) ([]Issue, error) {
-	cfg, err := parseConfig(data)
+	return LintWithOptions(data, Options{})
+}
+
+func LintWithOptions(data []byte, opts Options) ([]Issue, error) {
+	return LintReader(bytes.NewReader(data), opts)
+}
+
+// LintReader lints a YAML, JSON or XML config as it is read, holding one
+// line at a time instead of the whole input (documents up to 16 MiB are kept
+// for the YAML decoder). Use it for large generated configs and request
+// bodies; LintBytes and LintWithOptions wrap it.
+func LintReader(r io.Reader, opts Options) ([]Issue, error) {
+	return LintReaderContext(context.Background(), r, opts)
+}
+
+// LintContext is LintWithOptions that gives up with ctx's error once ctx is
+// cancelled or its deadline passes, so servers can drop work for clients
+// that went away and callers can bound the time spent on one input.
+func LintContext(ctx context.Context, data []byte, opts Options) ([]Issue, error) {
+	return LintReaderContext(ctx, bytes.NewReader(data), opts)
+}
+
+// LintReaderContext is LintReader with ctx as for LintContext. ctx is
+// checked as the input is read and before each check runs; a check that
+// has started runs to the end.
+func LintReaderContext(ctx context.Context, r io.Reader, opts Options) ([]Issue, error) {
+	cfg, err := parseStream(contextReader{ctx: ctx, r: r}, opts.Limits)
 	if err != nil {
 		return nil, err
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	issues := lintParsedContext(ctx, cfg, opts)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
 
-	var issues []Issue
-	validateMetadata(cfg, &issues)
-	validateSettings(cfg, &issues)
-	validateFeatures(cfg, &issues)
+// contextReader fails reads with ctx's error once ctx is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
 
-	return issues, nil
+func (cr contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+// lintParsed runs the rules on cfg and finishes their issues, together
+// with any issues its frontend reported while parsing.
+func lintParsed(cfg parsedConfig, opts Options, parseIssues ...Issue) []Issue {
+	return lintParsedContext(context.Background(), cfg, opts, parseIssues...)
+}
+
+// lintParsedContext is lintParsed that stops running checks once ctx is
+// done; the issues are then incomplete, and callers return ctx's error.
+func lintParsedContext(ctx context.Context, cfg parsedConfig, opts Options, parseIssues ...Issue) []Issue {
+	parseIssues = append(parseIssues, cfg.ParseIssues...)
+	issues := applySuppressions(append(parseIssues, runRules(ctx, cfg, opts, rulesFor(cfg, opts))...), cfg.Suppressions, opts.ReportSuppressed)
+	issues = applyRuleSettings(issues, opts)
+	assignPaths(issues, cfg)
+	applyMessageTemplates(issues, cfg, opts)
+	if opts.Redact {
+		redactIssues(issues, cfg)
+	}
+	return issues
 }
 
 func parseConfig(data []byte) (parsedConfig, error) {
-	cfg := parsedConfig{
-		Metadata: make(map[string]fieldInfo),
-		Settings: make(map[string]fieldInfo),
+	return parseStream(bytes.NewReader(data), Limits{})
+}
+
+// parseStream parses r line by line, failing with a *LimitError as soon as
+// the input crosses one of limits, or with ErrNotText when it starts with
+// binary content. UTF-16 input and byte order marks are transcoded first,
+// with an ENC001 parse issue. Input starting with a tag is read by parseXML instead.
+// Documents up to yamlDecodeMax are then
+// re-read with a YAML or JSON decoder (see decodeYAML and decodeJSON); the
+// line scanner's result stands for larger ones and for malformed documents.
+func parseStream(r io.Reader, limits Limits) (parsedConfig, error) {
+	limits = limits.Effective()
+	cfg := newParsedConfig()
+	// text keeps the input for decodeYAML until it grows past yamlDecodeMax.
+	var text []byte
+	keep := true
+	br := bufio.NewReaderSize(&limitedReader{r: r, max: limits.MaxBytes}, textSniff)
+	if head, _ := br.Peek(textSniff); len(head) > 0 {
+		encoding := sniffEncoding(head)
+		if encoding != "" {
+			var err error
+			if br, err = transcodeReader(br, encoding); err != nil {
+				return cfg, err
+			}
+			head, _ = br.Peek(textSniff)
+			cfg.ParseIssues = append(cfg.ParseIssues, encodingIssue(encoding))
+		}
+		if err := checkText(head); err != nil {
+			return cfg, err
+		}
+		if looksLikeXML(head) {
+			xcfg, err := parseXML(br, limits)
+			if encoding != "" {
+				xcfg.ParseIssues = append([]Issue{encodingIssue(encoding)}, xcfg.ParseIssues...)
+			}
+			return xcfg, err
+		}
 	}
-	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	scanner := bufio.NewScanner(br)
+	scanner.Buffer(make([]byte, 0, min(64*1024, limits.MaxLineBytes)), limits.MaxLineBytes)
+	scanner.Split(scanLineBlocks)
+	var depth depthTracker
 	lineNo := 0
 	section := ""
+	topIndent := 0
+	// metaIndent is the indent of metadata's own keys and metaMap the nested
+	// mapping being read, if any.
+	metaIndent := 0
+	metaMap := ""
+	// listKey is the current feature's field holding a block list; dashes
+	// nested deeper than the entry are its items, not new entries.
+	listKey := ""
 	var currentFeature featureEntry
 
 	for scanner.Scan() {
-		lineNo++
-		line := scanner.Text()
-		trimmed := strings.TrimSpace(line)
-		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
-			continue
+		// Each token is a block of whole lines; converting it once lets every
+		// line, key and value below be a substring instead of a copy.
+		block := scanner.Text()
+		if keep {
+			text = append(text, block...)
+			keep = len(text) <= yamlDecodeMax
 		}
-
-		trimmed = strings.TrimSuffix(trimmed, ",")
-		clean := strings.TrimSpace(trimmed)
-		switch clean {
-		case "{", "}", "[", "]":
-			if section == "features" && clean == "}" && len(currentFeature.Fields) > 0 {
-				cfg.Features = append(cfg.Features, currentFeature)
-				currentFeature = featureEntry{}
+		for block != "" {
+			lineNo++
+			if lineNo > limits.MaxLines {
+				return cfg, &LimitError{Limit: "line count", Max: int64(limits.MaxLines), Line: lineNo}
+			}
+			var line string
+			line, block, _ = strings.Cut(block, "\n")
+			line = strings.TrimSuffix(line, "\r")
+			if rest, directive, ok := cutSuppression(line); ok {
+				if s, ok := parseSuppression(directive, lineNo); ok {
+					cfg.Suppressions = append(cfg.Suppressions, s)
+				}
+				line = rest
+			}
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" || trimmed[0] == '#' {
+				continue
+			}
+			if topIndent == 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+				cfg.JSON = true
+			}
+			indentCol := len(line) - len(strings.TrimLeft(line, " \t")) + 1
+			if depth.observe(indentCol, trimmed) > limits.MaxDepth {
+				return cfg, &LimitError{Limit: "nesting depth", Max: int64(limits.MaxDepth), Line: lineNo}
+			}
+			if len(cfg.Features) > limits.MaxFeatures {
+				return cfg, &LimitError{Limit: "feature count", Max: int64(limits.MaxFeatures), Line: lineNo}
 			}
-			continue
-		}
 
-		if section == "features" {
-			if strings.HasPrefix(clean, "-") {
-				if len(currentFeature.Fields) > 0 {
+			trimmed = strings.TrimSuffix(trimmed, ",")
+			clean := strings.TrimSpace(trimmed)
+			switch clean {
+			case "{", "}", "[", "]":
+				if section == "features" && clean == "}" && len(currentFeature.Fields) > 0 {
 					cfg.Features = append(cfg.Features, currentFeature)
+					currentFeature = featureEntry{}
 				}
-				currentFeature = featureEntry{
-					Fields: make(map[string]fieldInfo),
-					Line:   lineNo,
-				}
-				clean = strings.TrimSpace(strings.TrimPrefix(clean, "-"))
-				if clean == "" {
+				continue
+			}
+
+			if section == "features" {
+				if len(currentFeature.Fields) > 0 && indentCol > currentFeature.Col &&
+					(strings.HasPrefix(clean, "-") || (listKey != "" && !strings.Contains(clean, ":"))) {
+					if listKey != "" {
+						item := trimQuotes(strings.TrimSpace(strings.TrimPrefix(clean, "-")))
+						list := currentFeature.Fields[listKey]
+						if list.Value == "" {
+							list.Value = "[" + item + "]"
+						} else {
+							list.Value = list.Value[:len(list.Value)-1] + ", " + item + "]"
+						}
+						currentFeature.Fields[listKey] = list
+					}
 					continue
 				}
-			}
-			if strings.HasPrefix(clean, "{") {
-				if len(currentFeature.Fields) > 0 {
-					cfg.Features = append(cfg.Features, currentFeature)
+				if strings.HasPrefix(clean, "-") {
+					if len(currentFeature.Fields) > 0 {
+						cfg.Features = append(cfg.Features, currentFeature)
+					}
+					currentFeature = featureEntry{
+						Fields: make(map[string]fieldInfo),
+						Line:   lineNo,
+						Col:    indentCol,
+					}
+					clean = strings.TrimSpace(strings.TrimPrefix(clean, "-"))
+					if clean == "" {
+						continue
+					}
 				}
-				currentFeature = featureEntry{
-					Fields: make(map[string]fieldInfo),
-					Line:   lineNo,
+				if strings.HasPrefix(clean, "{") {
+					if len(currentFeature.Fields) > 0 {
+						cfg.Features = append(cfg.Features, currentFeature)
+					}
+					currentFeature = featureEntry{
+						Fields: make(map[string]fieldInfo),
+						Line:   lineNo,
+						Col:    indentCol,
+					}
+					clean = strings.TrimSpace(strings.TrimPrefix(clean, "{"))
+					if clean == "" {
+						continue
+					}
 				}
-				clean = strings.TrimSpace(strings.TrimPrefix(clean, "{"))
-				if clean == "" {
+				if clean == "}" {
+					if len(currentFeature.Fields) > 0 {
+						cfg.Features = append(cfg.Features, currentFeature)
+						currentFeature = featureEntry{}
+					}
 					continue
 				}
 			}
-			if clean == "}" {
-				if len(currentFeature.Fields) > 0 {
-					cfg.Features = append(cfg.Features, currentFeature)
-					currentFeature = featureEntry{}
+
+			if isIncludeKey(section) && indentCol > topIndent {
+				// Items of a block include list: `- base.yaml` in YAML,
+				// `"base.json",` in JSON.
+				if ref := trimQuotes(strings.TrimSpace(strings.TrimPrefix(clean, "-"))); ref != "" {
+					col := strings.Index(line, clean) + 1
+					cfg.Includes = append(cfg.Includes, fieldInfo{Value: ref, Line: lineNo, Col: col})
 				}
 				continue
 			}
-		}
 
-		key, value, hasValue := parseKeyValue(clean)
-		if key == "" {
-			continue
-		}
+			key, value, hasValue := parseKeyValue(clean)
+			quoted := hasQuotedValue(clean)
+			if key == "" {
+				continue
+			}
+			// clean only loses whitespace, "-" and "{" on the left, none of
+			// which it can start with, so its first occurrence is its position.
+			col := strings.Index(line, clean) + 1
+			if topIndent == 0 {
+				topIndent = indentCol
+			}
+
+			if section == "" {
+				switch key {
+				case "metadata", `"metadata"`:
+					section = "metadata"
+					cfg.MetadataLine = lineNo
+					metaIndent = 0
+					continue
+				case "settings", `"settings"`:
+					section = "settings"
+					cfg.SettingsLine = lineNo
+					continue
+				case "features", `"features"`:
+					section = "features"
+					cfg.FeaturesLine = lineNo
+					continue
+				}
+			}
 
-		if section == "" {
 			switch key {
 			case "metadata", `"metadata"`:
 				section = "metadata"
 				cfg.MetadataLine = lineNo
+				metaIndent = 0
 				continue
 			case "settings", `"settings"`:
 				section = "settings"
@@ -152,62 +473,138 @@ func parseConfig(data []byte) (parsedConfig, error) {
 				cfg.FeaturesLine = lineNo
 				continue
 			}
-		}
 
-		switch key {
-		case "metadata", `"metadata"`:
-			section = "metadata"
-			cfg.MetadataLine = lineNo
-			continue
-		case "settings", `"settings"`:
-			section = "settings"
-			cfg.SettingsLine = lineNo
-			continue
-		case "features", `"features"`:
-			section = "features"
-			cfg.FeaturesLine = lineNo
-			continue
-		}
+			if indentCol == topIndent && !strings.HasPrefix(trimmed, "-") {
+				if hasValue && value != "" {
+					cfg.TopLevel[key] = fieldInfo{Value: value, Line: lineNo, Col: col, Quoted: quoted}
+					if isIncludeKey(key) {
+						cfg.Includes = append(cfg.Includes, flowList(value, lineNo, col)...)
+					}
+					section = ""
+				} else {
+					section = key
+					cfg.Sections[key] = sectionInfo{Fields: make(map[string]fieldInfo), Line: lineNo}
+				}
+				continue
+			}
 
-		if section == "metadata" {
-			if hasValue {
-				cfg.Metadata[key] = fieldInfo{Value: value, Line: lineNo}
+			if other, ok := cfg.Sections[section]; ok {
+				if hasValue {
+					other.Fields[key] = fieldInfo{Value: value, Line: lineNo, Col: col, Quoted: quoted}
+				}
+				continue
 			}
-			continue
-		}
 
-		if section == "settings" {
-			if hasValue {
-				cfg.Settings[key] = fieldInfo{Value: value, Line: lineNo}
+			if section == "metadata" {
+				if metaIndent == 0 || indentCol <= metaIndent {
+					metaIndent, metaMap = indentCol, ""
+					if hasValue && value == "" {
+						metaMap = key
+					}
+				} else if metaMap != "" && hasValue {
+					if cfg.MetadataMaps == nil {
+						cfg.MetadataMaps = make(map[string]map[string]fieldInfo)
+					}
+					if cfg.MetadataMaps[metaMap] == nil {
+						cfg.MetadataMaps[metaMap] = make(map[string]fieldInfo)
+					}
+					cfg.MetadataMaps[metaMap][key] = fieldInfo{Value: value, Line: lineNo, Col: col, Quoted: quoted}
+				}
+				if hasValue {
+					cfg.Metadata[key] = fieldInfo{Value: value, Line: lineNo, Col: col, Quoted: quoted}
+				}
+				continue
 			}
-			continue
-		}
 
-		if section == "features" {
-			if !hasValue {
+			if section == "settings" {
+				if hasValue {
+					cfg.Settings[key] = fieldInfo{Value: value, Line: lineNo, Col: col, Quoted: quoted}
+				}
 				continue
 			}
-			if len(currentFeature.Fields) == 0 {
-				currentFeature = featureEntry{
-					Fields: make(map[string]fieldInfo),
-					Line:   lineNo,
+
+			if section == "features" {
+				if !hasValue {
+					continue
+				}
+				if len(currentFeature.Fields) == 0 {
+					currentFeature = featureEntry{
+						Fields: make(map[string]fieldInfo),
+						Line:   lineNo,
+						Col:    indentCol,
+					}
+				}
+				currentFeature.Fields[key] = fieldInfo{Value: value, Line: lineNo, Col: col, Quoted: quoted}
+				listKey = ""
+				if value == "" {
+					listKey = key
 				}
 			}
-			currentFeature.Fields[key] = fieldInfo{Value: value, Line: lineNo}
 		}
 	}
 
 	if len(currentFeature.Fields) > 0 {
 		cfg.Features = append(cfg.Features, currentFeature)
 	}
+	cfg.Lines = lineNo
+
+	if len(cfg.Features) > limits.MaxFeatures {
+		return cfg, &LimitError{Limit: "feature count", Max: int64(limits.MaxFeatures), Line: lineNo}
+	}
 
 	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return cfg, &LimitError{Limit: "line length", Max: int64(limits.MaxLineBytes), Line: lineNo + 1}
+		}
 		return cfg, err
 	}
 
+	decode := decodeYAML
+	if cfg.JSON {
+		decode = decodeJSON
+	}
+	if !keep {
+		return cfg, nil
+	}
+	decoded := decode(text, &cfg)
+	cfg.Source = text
+	if decoded && len(cfg.Features) > limits.MaxFeatures {
+		return cfg, &LimitError{Limit: "feature count", Max: int64(limits.MaxFeatures), Line: lineNo}
+	}
 	return cfg, nil
 }
 
+func isIncludeKey(key string) bool {
+	return key == "include" || key == "extends"
+}
+
+// flowList splits an inline `[a, b]` list into its items; any other value
+// is a single item.
+func flowList(value string, line, col int) []fieldInfo {
+	if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+		return []fieldInfo{{Value: value, Line: line, Col: col}}
+	}
+	var items []fieldInfo
+	for _, item := range strings.Split(value[1:len(value)-1], ",") {
+		if item = trimQuotes(strings.TrimSpace(item)); item != "" {
+			items = append(items, fieldInfo{Value: item, Line: line, Col: col})
+		}
+	}
+	return items
+}
+
+// scanLineBlocks is a bufio.SplitFunc returning as many complete lines as
+// the buffer holds, so a line longer than the buffer is still ErrTooLong.
+func scanLineBlocks(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i+1], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
 func parseKeyValue(line string) (string, string, bool) {
 	idx := strings.Index(line, ":")
 	if idx == -1 {
@@ -215,9 +612,9 @@ func parseKeyValue(line string) (string, string, bool) {
 	}
 
 	key := strings.TrimSpace(line[:idx])
-	key = strings.Trim(key, `"'`)
+	key = trimQuotes(key)
 	value := strings.TrimSpace(line[idx+1:])
-	value = strings.Trim(value, `"'`)
+	value = trimQuotes(value)
 
 	if value == "{" || value == "[" {
 		value = ""
@@ -226,6 +623,25 @@ func parseKeyValue(line string) (string, string, bool) {
 	return key, value, true
 }
 
+// hasQuotedValue reports whether a YAML-style line (unquoted key) has a
+// quoted value. JSON lines, where quoting is mandatory, never match.
+func hasQuotedValue(line string) bool {
+	key, value, ok := strings.Cut(line, ":")
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	return ok && key != "" && key[0] != '"' && key[0] != '\'' && value != "" && (value[0] == '"' || value[0] == '\'')
+}
+
+// trimQuotes is strings.Trim(s, `"'`) without building a cutset per call.
+func trimQuotes(s string) string {
+	for s != "" && (s[0] == '"' || s[0] == '\'') {
+		s = s[1:]
+	}
+	for s != "" && (s[len(s)-1] == '"' || s[len(s)-1] == '\'') {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
 func looksLikeJSON(data []byte) bool {
 	for _, b := range data {
 		if b == ' ' || b == '\n' || b == '\r' || b == '\t' {
@@ -236,7 +652,8 @@ func looksLikeJSON(data []byte) bool {
 	return false
 }
 
-func validateMetadata(cfg parsedConfig, issues *[]Issue) {
+func validateMetadata(cfg parsedConfig, opts Options, issues *[]Issue) {
+	environments := opts.environments()
 	baseLine := cfg.MetadataLine
 	if baseLine == 0 {
 		baseLine = 1
@@ -249,6 +666,7 @@ func validateMetadata(cfg parsedConfig, issues *[]Issue) {
 		*issues = append(*issues, Issue{
 			Line:     baseLine,
 			Severity: SeverityError,
+			RuleID:   "META001",
 			Message:  "missing metadata section",
 			SuggestedFix: "Add a 'metadata' mapping with 'name' and 'env' fields",
 		})
@@ -262,7 +680,9 @@ func validateMetadata(cfg parsedConfig, issues *[]Issue) {
 		}
 		*issues = append(*issues, Issue{
 			Line:     name.Line,
+			Column:   name.Col,
 			Severity: SeverityError,
+			RuleID:   "META002",
 			Message:  "metadata.name is required",
 			SuggestedFix: "Set metadata.name to a non-empty identifier, e.g. metadata.name: my-service",
 		})
@@ -275,21 +695,25 @@ func validateMetadata(cfg parsedConfig, issues *[]Issue) {
 		}
 		*issues = append(*issues, Issue{
 			Line:     env.Line,
+			Column:   env.Col,
 			Severity: SeverityError,
+			RuleID:   "META003",
 			Message:  "metadata.env is required",
-			SuggestedFix: fmt.Sprintf("Set metadata.env to one of: %s", strings.Join(allowedEnvironments, ", ")),
+			SuggestedFix: fmt.Sprintf("Set metadata.env to one of: %s", strings.Join(environments, ", ")),
 		})
-	} else if !contains(allowedEnvironments, env.Value) {
+	} else if !contains(environments, env.Value) {
 		*issues = append(*issues, Issue{
 			Line:         env.Line,
+			Column:       env.Col,
 			Severity:     SeverityWarning,
+			RuleID:       "META004",
 			Message:      fmt.Sprintf("metadata.env value %q is not recognized", env.Value),
-			SuggestedFix: fmt.Sprintf("Use one of: %s", strings.Join(allowedEnvironments, ", ")),
+			SuggestedFix: fmt.Sprintf("Use one of: %s", strings.Join(environments, ", ")),
 		})
 	}
 }
 
-func validateSettings(cfg parsedConfig, issues *[]Issue) {
+func validateSettings(cfg parsedConfig, opts Options, issues *[]Issue) {
 	baseLine := cfg.SettingsLine
 	if baseLine == 0 {
 		baseLine = 1
@@ -299,6 +723,7 @@ func validateSettings(cfg parsedConfig, issues *[]Issue) {
 		*issues = append(*issues, Issue{
 			Line:     baseLine,
 			Severity: SeverityError,
+			RuleID:   "SET001",
 			Message:  "missing settings section",
 			SuggestedFix: "Add a 'settings' mapping with 'replicas' and 'timeout'",
 		})
@@ -310,13 +735,16 @@ func validateSettings(cfg parsedConfig, issues *[]Issue) {
 		*issues = append(*issues, Issue{
 			Line:     baseLine,
 			Severity: SeverityError,
+			RuleID:   "SET002",
 			Message:  "settings.replicas is required",
 			SuggestedFix: "Add settings.replicas: 1",
 		})
 	} else if !isPositiveInt(replicas.Value) {
 		*issues = append(*issues, Issue{
 			Line:     replicas.Line,
+			Column:   replicas.Col,
 			Severity: SeverityError,
+			RuleID:   "SET003",
 			Message:  "settings.replicas must be a positive integer",
 		})
 	}
@@ -326,13 +754,16 @@ func validateSettings(cfg parsedConfig, issues *[]Issue) {
 		*issues = append(*issues, Issue{
 			Line:     baseLine,
 			Severity: SeverityWarning,
-			Message:  "settings.timeout is missing; defaulting to 30",
-			SuggestedFix: fmt.Sprintf("Add settings.timeout: %d", defaultTimeout),
+			RuleID:   "SET004",
+			Message:  fmt.Sprintf("settings.timeout is missing; defaulting to %d", opts.defaultTimeout()),
+			SuggestedFix: fmt.Sprintf("Add settings.timeout: %d", opts.defaultTimeout()),
 		})
 	} else if !isPositiveInt(timeout.Value) {
 		*issues = append(*issues, Issue{
 			Line:     timeout.Line,
+			Column:   timeout.Col,
 			Severity: SeverityWarning,
+			RuleID:   "SET005",
 			Message:  "settings.timeout should be a positive integer",
 		})
 	}
@@ -343,7 +774,9 @@ func validateFeatures(cfg parsedConfig, issues *[]Issue) {
 		if len(feature.Fields) == 0 {
 			*issues = append(*issues, Issue{
 				Line:     feature.Line,
+				Column:   feature.Col,
 				Severity: SeverityWarning,
+				RuleID:   "FEAT001",
 				Message:  "each feature entry should be a mapping",
 			})
 			continue
@@ -353,7 +786,9 @@ func validateFeatures(cfg parsedConfig, issues *[]Issue) {
 		if !hasName || name.Value == "" {
 			*issues = append(*issues, Issue{
 				Line:     feature.Line,
+				Column:   feature.Col,
 				Severity: SeverityWarning,
+				RuleID:   "FEAT002",
 				Message:  "feature entry missing name",
 				SuggestedFix: "Add name: <feature-name>",
 			})
@@ -363,7 +798,9 @@ func validateFeatures(cfg parsedConfig, issues *[]Issue) {
 		if !hasEnabled || !isBool(enabled.Value) {
 			*issues = append(*issues, Issue{
 				Line:     feature.Line,
+				Column:   feature.Col,
 				Severity: SeverityWarning,
+				RuleID:   "FEAT003",
 				Message:  "feature enabled should be true or false",
 			})
 		}
@@ -371,13 +808,8 @@ func validateFeatures(cfg parsedConfig, issues *[]Issue) {
 }
 
 func isPositiveInt(value string) bool {
-	if value == "" {
-		return false
-	}
-	if _, err := strconv.Atoi(value); err != nil || value == "0" {
-		return false
-	}
-	return true
+	n, err := strconv.Atoi(value)
+	return err == nil && n > 0
 }
 
 func isBool(value string) bool {
@@ -0,0 +1,67 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// 1,000,000 / 1 000 / 1'000, including the no-break spaces spreadsheets
+	// paste. Dot grouping is left alone: it is indistinguishable from a
+	// version string.
+	localeGrouped = regexp.MustCompile(`^[-+]?[0-9]{1,3}([, '\x{00a0}\x{202f}])[0-9]{3}(?:[, '\x{00a0}\x{202f}][0-9]{3})*$`)
+	// 1,5 / 0,25 / 1,5s
+	localeDecimalComma = regexp.MustCompile(`^([-+]?[0-9]+),([0-9]+)(ms|s|m|h|d)?$`)
+	// 01/02/2024, 1-2-24, 01.02.2024; ISO dates start with the year.
+	localeDate = regexp.MustCompile(`^[0-9]{1,2}(?:/[0-9]{1,2}/(?:[0-9]{2}|[0-9]{4})|-[0-9]{1,2}-(?:[0-9]{2}|[0-9]{4})|\.[0-9]{1,2}\.[0-9]{4})$`)
+
+	localeSeparators = strings.NewReplacer(",", "", " ", "", "'", "", "\u00a0", "", "\u202f", "")
+)
+
+// localeNumber reports a number written with locale-specific separators and
+// its canonical form.
+func localeNumber(value string) (canonical string, ok bool) {
+	if m := localeGrouped.FindStringSubmatch(value); m != nil {
+		// A single comma group ("1,500") is a decimal in some locales and a
+		// thousands group in others.
+		if m[1] == "," && strings.Count(value, ",") == 1 {
+			return fmt.Sprintf("%s or %s", localeSeparators.Replace(value), strings.Replace(value, ",", ".", 1)), true
+		}
+		return localeSeparators.Replace(value), true
+	}
+	if m := localeDecimalComma.FindStringSubmatch(value); m != nil {
+		return m[1] + "." + m[2] + m[3], true
+	}
+	return "", false
+}
+
+// validateLocaleValues reports settings written in a locale format that
+// downstream parsers read differently: numbers with grouping or decimal
+// commas (LOC001) and day/month dates whose order is ambiguous (LOC002).
+func validateLocaleValues(cfg parsedConfig, _ Options, issues *[]Issue) {
+	for _, v := range fieldsByLine("settings", cfg.Settings) {
+		value := v.value.Value
+		if canonical, ok := localeNumber(value); ok {
+			*issues = append(*issues, Issue{
+				Line:         v.value.Line,
+				Column:       v.value.Col,
+				Severity:     SeverityWarning,
+				RuleID:       "LOC001",
+				Message:      fmt.Sprintf("%s value %q is a locale-formatted number that most parsers read as a string or a different number", v.field, value),
+				SuggestedFix: "Write it as " + canonical,
+			})
+			continue
+		}
+		if localeDate.MatchString(value) {
+			*issues = append(*issues, Issue{
+				Line:         v.value.Line,
+				Column:       v.value.Col,
+				Severity:     SeverityWarning,
+				RuleID:       "LOC002",
+				Message:      fmt.Sprintf("%s value %q is a locale-formatted date; parsers disagree on its day and month order", v.field, value),
+				SuggestedFix: "Write dates as YYYY-MM-DD",
+			})
+		}
+	}
+}
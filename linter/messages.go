@@ -0,0 +1,109 @@
+package linter
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// MessageTemplate overrides how one rule's issues read. Both fields are Go
+// text/template strings executed with a MessageData; an empty field keeps
+// the built-in text.
+type MessageTemplate struct {
+	Message      string `json:"message,omitempty"`
+	SuggestedFix string `json:"suggestedFix,omitempty"`
+}
+
+// MessageData is what message templates can use. Message and SuggestedFix
+// are the built-in texts, Value is the value on the issue's line, and
+// Allowed lists the accepted values for rules that have a fixed set.
+type MessageData struct {
+	RuleID       string
+	Path         string
+	Line         int
+	Value        string
+	Allowed      []string
+	Message      string
+	SuggestedFix string
+}
+
+func parseMessageTemplates(messages map[string]MessageTemplate) (map[string][2]*template.Template, error) {
+	out := make(map[string][2]*template.Template, len(messages))
+	for id, m := range messages {
+		var t [2]*template.Template
+		for i, text := range []string{m.Message, m.SuggestedFix} {
+			if text == "" {
+				continue
+			}
+			parsed, err := template.New(id).Option("missingkey=error").Parse(text)
+			if err != nil {
+				return nil, fmt.Errorf("message template for %s: %w", id, err)
+			}
+			t[i] = parsed
+		}
+		// Templates written for a renamed rule follow it.
+		out[CurrentRuleID(id)] = t
+	}
+	return out, nil
+}
+
+// allowedValues lists the accepted values for rules with a fixed set.
+func allowedValues(ruleID string, opts Options) []string {
+	switch ruleID {
+	case "META003", "META004":
+		return opts.environments()
+	case "FEAT003":
+		return []string{"true", "false"}
+	}
+	return nil
+}
+
+// applyMessageTemplates rewrites issues with the rc file's message
+// templates. It runs after key paths are assigned and before redaction, so
+// redaction also covers template output. A template that fails to execute
+// leaves the built-in text.
+func applyMessageTemplates(issues []Issue, cfg parsedConfig, opts Options) {
+	if len(opts.Messages) == 0 {
+		return
+	}
+	templates, err := parseMessageTemplates(opts.Messages)
+	if err != nil {
+		return
+	}
+	var values map[int]string
+	for i, issue := range issues {
+		t, ok := templates[issue.RuleID]
+		if !ok {
+			continue
+		}
+		if values == nil {
+			values = make(map[int]string)
+			for _, v := range configValues(cfg) {
+				if _, seen := values[v.value.Line]; !seen {
+					values[v.value.Line] = v.value.Value
+				}
+			}
+		}
+		data := MessageData{
+			RuleID:       issue.RuleID,
+			Path:         issue.Path,
+			Line:         issue.Line,
+			Value:        values[issue.Line],
+			Allowed:      allowedValues(issue.RuleID, opts),
+			Message:      issue.Message,
+			SuggestedFix: issue.SuggestedFix,
+		}
+		if opts.Redact && data.Value != "" {
+			data.Value = strings.Trim(redacted, `"`)
+		}
+		for j, field := range []*string{&issues[i].Message, &issues[i].SuggestedFix} {
+			if t[j] == nil {
+				continue
+			}
+			var b strings.Builder
+			if err := t[j].Execute(&b, data); err == nil {
+				*field = b.String()
+			}
+		}
+	}
+}
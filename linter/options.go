@@ -0,0 +1,508 @@
+package linter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Options tunes the built-in checks. The zero value lints with the defaults.
+type Options struct {
+	// Environments lists the values metadata.env may have (META004); by
+	// default dev, staging and prod. Enums["metadata.env"] sets it too.
+	Environments   []string `json:"environments,omitempty"`
+	DefaultTimeout int      `json:"defaultTimeout,omitempty"`
+	// Profiles enables built-in profiles (see Profiles) for documents whose
+	// apiVersion/kind they recognize.
+	Profiles []string `json:"profiles,omitempty"`
+	// TerraformVariables lists declared variable names; tfvars values for
+	// any other name are reported as unused.
+	TerraformVariables []string `json:"terraformVariables,omitempty"`
+	// Limits bounds the input the parser accepts; see DefaultLimits.
+	Limits Limits `json:"limits,omitempty"`
+	// Style enables the SeverityStyle rules (section order, key order,
+	// needless quoting). They are off by default.
+	Style bool `json:"style,omitempty"`
+	// Concurrency caps the workers running rules within one lint; 0 uses
+	// GOMAXPROCS and 1 runs them sequentially. It never changes the result.
+	Concurrency int `json:"concurrency,omitempty"`
+	// Governance enables the ownership rules; see Governance.
+	Governance Governance `json:"governance,omitempty"`
+	// TemplateVariables declares the variables ${name} and {{name}}
+	// placeholders may use. When set, placeholders are checked against it.
+	TemplateVariables map[string]string `json:"templateVariables,omitempty"`
+	// ConsumedKeys lists the dotted key paths the application reads
+	// (settings.timeout, features.enabled, metadata.labels.*). When set,
+	// keys nothing reads and read keys the config lacks are reported.
+	ConsumedKeys []string `json:"consumedKeys,omitempty"`
+	// StrictKeys reports keys outside the known schema (KEY003), such as a
+	// misspelled replicas. Keys in Defaults and ConsumedKeys are known.
+	StrictKeys bool `json:"strictKeys,omitempty"`
+	// Redact replaces config values quoted in issue messages and fixes with
+	// "<redacted>", so results can leave the machine without them.
+	Redact bool `json:"redact,omitempty"`
+	// OverlayMerge is how LintOverlays combines documents: MergeDeep (the
+	// default) or MergeReplace.
+	OverlayMerge string `json:"overlayMerge,omitempty"`
+	// Defaults is the catalog of values assumed for missing metadata and
+	// settings keys, by key path ({"settings.timeout": 30}). Missing keys
+	// with a default are reported (DEF001) and written out by Fix.
+	Defaults map[string]any `json:"defaults,omitempty"`
+	// MaxWarnings is the project's warning budget: a run with more warnings
+	// fails even without strict mode. Unset means no budget.
+	MaxWarnings *int `json:"maxWarnings,omitempty"`
+	// ReviewWindowDays is how many days may pass after metadata.lastReviewed
+	// before the config is reported as due for review (REV001). 0 disables
+	// the review rules.
+	ReviewWindowDays int `json:"reviewWindowDays,omitempty"`
+	// MaxProdRollout caps feature rollout percentages in prod configs
+	// (FEAT009) unless metadata.annotations.rollout-approved-by is set.
+	// Unset means no cap.
+	MaxProdRollout *int `json:"maxProdRollout,omitempty"`
+	// Generated marks generated files, whose issues are reported apart;
+	// see Generated.
+	Generated Generated `json:"generated,omitempty"`
+	// Messages overrides issue messages and suggested fixes by rule ID; see
+	// MessageTemplate.
+	Messages map[string]MessageTemplate `json:"messages,omitempty"`
+	// DisabledRules turns rules off by ID (SET005, FEAT003): their issues
+	// are not reported. EnabledRules, when set, turns off every rule it does
+	// not list. Renamed IDs are followed; see CurrentRuleID.
+	DisabledRules []string `json:"disabledRules,omitempty"`
+	EnabledRules  []string `json:"enabledRules,omitempty"`
+	// Severities overrides the severity of rules by ID, for example to
+	// demote an unknown env (META004) to SeverityInfo or promote a missing
+	// timeout (SET004) to SeverityError. Renamed IDs are followed.
+	Severities map[string]Severity `json:"severities,omitempty"`
+	// ExpressionRules are custom checks written as expressions; see
+	// ExpressionRule. Layers add rules, replacing those with the same ID.
+	ExpressionRules []ExpressionRule `json:"expressionRules,omitempty"`
+	// FailOn is the least severe issue that fails a run: SeverityError (the
+	// default), SeverityWarning, SeverityInfo or SeverityHint. Strict mode
+	// lowers it to SeverityWarning; see FailThreshold.
+	FailOn Severity `json:"failOn,omitempty"`
+	// Schema is a JSON Schema the document must match, for structure the
+	// built-in checks do not know; violations are reported as SCHEMA001.
+	// See SchemaFrom for the keywords checked.
+	Schema json.RawMessage `json:"schema,omitempty"`
+	// CUESchema is CUE source the document must unify with, as cue vet
+	// checks it: values must satisfy its constraints, closed definitions
+	// allow no other fields, and fields it declares without a default must
+	// be set. CUEDefinition names the definition to check against, such as
+	// #Config; by default it is the whole schema. Violations are reported
+	// as CUE001.
+	CUESchema     string `json:"cueSchema,omitempty"`
+	CUEDefinition string `json:"cueDefinition,omitempty"`
+	// Patterns maps key paths to the regexp their values must match
+	// (PAT001), such as {"metadata.name": "^[a-z][a-z0-9-]*$"}. Feature
+	// keys are features.<key>, and a path ending in ".*" covers every key
+	// below it. Layers add patterns, replacing those for the same path.
+	Patterns map[string]string `json:"patterns,omitempty"`
+	// Enums maps key paths to the values they may have (ENUM001), such as
+	// {"settings.log_level": ["debug", "info", "warn", "error"]}. Paths are
+	// written as for Patterns. Layers add enumerations, replacing those for
+	// the same path.
+	Enums map[string][]string `json:"enums,omitempty"`
+	// Sections declares the keys of sections by name; see SectionSchema.
+	// Layers replace whole sections.
+	Sections map[string]SectionSchema `json:"sections,omitempty"`
+	// ReportSuppressed returns the issues inline suppressions cover, marked
+	// Suppressed, instead of dropping them, so reports can list them apart.
+	// It is not part of the rule configuration.
+	ReportSuppressed bool `json:"-"`
+	// Stats, when set, collects per-check timings and issue counts from
+	// every lint run with these options. It is not part of the rule
+	// configuration.
+	Stats *RuleStats `json:"-"`
+
+	// compiled is set by Linter to share precomputed lookups.
+	compiled *compiledOptions
+}
+
+// Merge returns o with every field that is set in other taking precedence.
+func (o Options) Merge(other Options) Options {
+	if len(other.Environments) > 0 {
+		o.Environments = other.Environments
+	}
+	if other.DefaultTimeout > 0 {
+		o.DefaultTimeout = other.DefaultTimeout
+	}
+	if len(other.Profiles) > 0 {
+		o.Profiles = other.Profiles
+	}
+	if len(other.TerraformVariables) > 0 {
+		o.TerraformVariables = other.TerraformVariables
+	}
+	o.Limits = o.Limits.merge(other.Limits)
+	if other.Style {
+		o.Style = true
+	}
+	if other.Concurrency > 0 {
+		o.Concurrency = other.Concurrency
+	}
+	o.Governance = o.Governance.merge(other.Governance)
+	if len(other.TemplateVariables) > 0 {
+		o.TemplateVariables = other.TemplateVariables
+	}
+	if len(other.ConsumedKeys) > 0 {
+		o.ConsumedKeys = other.ConsumedKeys
+	}
+	if other.StrictKeys {
+		o.StrictKeys = true
+	}
+	if other.Redact {
+		o.Redact = true
+	}
+	if other.OverlayMerge != "" {
+		o.OverlayMerge = other.OverlayMerge
+	}
+	if other.MaxWarnings != nil {
+		o.MaxWarnings = other.MaxWarnings
+	}
+	if other.ReviewWindowDays > 0 {
+		o.ReviewWindowDays = other.ReviewWindowDays
+	}
+	if other.MaxProdRollout != nil {
+		o.MaxProdRollout = other.MaxProdRollout
+	}
+	o.Generated = o.Generated.merge(other.Generated)
+	if len(other.Messages) > 0 {
+		merged := make(map[string]MessageTemplate, len(o.Messages)+len(other.Messages))
+		for id, m := range o.Messages {
+			merged[id] = m
+		}
+		for id, m := range other.Messages {
+			merged[id] = m
+		}
+		o.Messages = merged
+	}
+	if len(other.DisabledRules) > 0 {
+		o.DisabledRules = other.DisabledRules
+	}
+	if len(other.EnabledRules) > 0 {
+		o.EnabledRules = other.EnabledRules
+		// Enabling a rule overrides the layers below that disabled it.
+		if len(other.DisabledRules) == 0 {
+			o.DisabledRules = withoutRules(o.DisabledRules, other.EnabledRules)
+		}
+	}
+	if len(other.Severities) > 0 {
+		merged := make(map[string]Severity, len(o.Severities)+len(other.Severities))
+		for id, severity := range o.Severities {
+			merged[id] = severity
+		}
+		for id, severity := range other.Severities {
+			merged[id] = severity
+		}
+		o.Severities = merged
+	}
+	if len(other.ExpressionRules) > 0 {
+		o.ExpressionRules = mergeExpressionRules(o.ExpressionRules, other.ExpressionRules)
+	}
+	if other.FailOn != "" {
+		o.FailOn = other.FailOn
+	}
+	if len(other.Schema) > 0 {
+		o.Schema = other.Schema
+	}
+	if len(other.Patterns) > 0 {
+		merged := make(map[string]string, len(o.Patterns)+len(other.Patterns))
+		for path, pattern := range o.Patterns {
+			merged[path] = pattern
+		}
+		for path, pattern := range other.Patterns {
+			merged[path] = pattern
+		}
+		o.Patterns = merged
+	}
+	if len(other.Enums) > 0 {
+		merged := make(map[string][]string, len(o.Enums)+len(other.Enums))
+		for path, values := range o.Enums {
+			merged[path] = values
+		}
+		for path, values := range other.Enums {
+			merged[path] = values
+		}
+		o.Enums = merged
+	}
+	if len(other.Sections) > 0 {
+		merged := make(map[string]SectionSchema, len(o.Sections)+len(other.Sections))
+		for name, s := range o.Sections {
+			merged[name] = s
+		}
+		for name, s := range other.Sections {
+			merged[name] = s
+		}
+		o.Sections = merged
+	}
+	if other.CUESchema != "" {
+		o.CUESchema = other.CUESchema
+		o.CUEDefinition = other.CUEDefinition
+	} else if other.CUEDefinition != "" {
+		o.CUEDefinition = other.CUEDefinition
+	}
+	if other.ReportSuppressed {
+		o.ReportSuppressed = true
+	}
+	if other.Stats != nil {
+		o.Stats = other.Stats
+	}
+	if len(other.Defaults) > 0 {
+		merged := make(map[string]any, len(o.Defaults)+len(other.Defaults))
+		for key, value := range o.Defaults {
+			merged[key] = value
+		}
+		for key, value := range other.Defaults {
+			merged[key] = value
+		}
+		o.Defaults = merged
+	}
+	return o
+}
+
+// Validate reports option values the linter does not understand.
+func (o Options) Validate() error {
+	for _, name := range o.Profiles {
+		if _, ok := profiles[name]; !ok {
+			return fmt.Errorf("unknown profile %q", name)
+		}
+	}
+	switch o.OverlayMerge {
+	case "", MergeDeep, MergeReplace:
+	default:
+		return fmt.Errorf("unknown overlay merge mode %q (use %s or %s)", o.OverlayMerge, MergeDeep, MergeReplace)
+	}
+	if o.MaxWarnings != nil && *o.MaxWarnings < 0 {
+		return fmt.Errorf("maxWarnings must not be negative")
+	}
+	if o.ReviewWindowDays < 0 {
+		return fmt.Errorf("reviewWindowDays must not be negative")
+	}
+	if o.MaxProdRollout != nil && (*o.MaxProdRollout < 0 || *o.MaxProdRollout > 100) {
+		return fmt.Errorf("maxProdRollout must be from 0 to 100")
+	}
+	for _, ids := range [][]string{o.DisabledRules, o.EnabledRules} {
+		for _, id := range ids {
+			if strings.TrimSpace(id) == "" {
+				return fmt.Errorf("disabledRules and enabledRules must not contain an empty rule ID")
+			}
+		}
+	}
+	for _, id := range o.DisabledRules {
+		if contains(o.EnabledRules, id) {
+			return fmt.Errorf("rule %s is both enabled and disabled", id)
+		}
+	}
+	for id, severity := range o.Severities {
+		switch severity {
+		case SeverityError, SeverityWarning, SeverityInfo, SeverityHint:
+		default:
+			return fmt.Errorf("severity of rule %s must be %s, %s, %s or %s, got %q", id, SeverityError, SeverityWarning, SeverityInfo, SeverityHint, severity)
+		}
+	}
+	ids := make(map[string]bool, len(o.ExpressionRules))
+	for _, r := range o.ExpressionRules {
+		if err := r.validate(); err != nil {
+			return err
+		}
+		if ids[r.ID] {
+			return fmt.Errorf("expression rule %s is defined twice", r.ID)
+		}
+		ids[r.ID] = true
+	}
+	switch o.FailOn {
+	case "", SeverityError, SeverityWarning, SeverityInfo, SeverityHint:
+	default:
+		return fmt.Errorf("failOn must be %s, %s, %s or %s, got %q", SeverityError, SeverityWarning, SeverityInfo, SeverityHint, o.FailOn)
+	}
+	if len(o.Schema) > 0 {
+		if _, err := compileSchema(o.Schema); err != nil {
+			return err
+		}
+	}
+	if _, err := compilePatterns(o.Patterns); err != nil {
+		return err
+	}
+	if err := validateEnums(o.Enums); err != nil {
+		return err
+	}
+	for name, s := range o.Sections {
+		if err := s.validate(name); err != nil {
+			return err
+		}
+	}
+	if o.CUEDefinition != "" && o.CUESchema == "" {
+		return fmt.Errorf("cueDefinition is set without a cueSchema")
+	}
+	if o.CUESchema != "" {
+		if _, err := compileCUE(o); err != nil {
+			return err
+		}
+	}
+	if err := validateDefaultsCatalog(o.Defaults); err != nil {
+		return err
+	}
+	if _, err := parseMessageTemplates(o.Messages); err != nil {
+		return err
+	}
+	if err := o.Generated.validate(); err != nil {
+		return err
+	}
+	return o.Governance.validate()
+}
+
+// FailThreshold is the least severe issue that fails a run: FailOn, or
+// SeverityError when unset, lowered to SeverityWarning in strict mode.
+func (o Options) FailThreshold(strict bool) Severity {
+	threshold := o.FailOn
+	if threshold == "" {
+		threshold = SeverityError
+	}
+	if strict && !SeverityWarning.AtLeast(threshold) {
+		threshold = SeverityWarning
+	}
+	return threshold
+}
+
+// OverWarningBudget reports whether a run with this many warnings (see
+// CountWarnings) exceeds MaxWarnings.
+func (o Options) OverWarningBudget(warnings int) bool {
+	return o.MaxWarnings != nil && warnings > *o.MaxWarnings
+}
+
+// CountWarnings returns the number of SeverityWarning issues.
+func CountWarnings(issues []Issue) int {
+	n := 0
+	for _, issue := range issues {
+		if issue.Severity == SeverityWarning {
+			n++
+		}
+	}
+	return n
+}
+
+// reportsRule reports whether the issues of rule id are reported under
+// DisabledRules and EnabledRules.
+// withoutRules returns ids without those in drop, following renamed IDs.
+func withoutRules(ids, drop []string) []string {
+	if len(ids) == 0 {
+		return ids
+	}
+	dropped := make(map[string]bool, len(drop))
+	for _, id := range drop {
+		dropped[CurrentRuleID(strings.TrimSpace(id))] = true
+	}
+	var kept []string
+	for _, id := range ids {
+		if !dropped[CurrentRuleID(strings.TrimSpace(id))] {
+			kept = append(kept, id)
+		}
+	}
+	return kept
+}
+
+func (o Options) reportsRule(id string) bool {
+	listed := func(ids []string) bool {
+		for _, listed := range ids {
+			if CurrentRuleID(strings.TrimSpace(listed)) == id {
+				return true
+			}
+		}
+		return false
+	}
+	return !listed(o.DisabledRules) && (len(o.EnabledRules) == 0 || listed(o.EnabledRules))
+}
+
+// applyRuleSettings drops the issues of rules turned off in opts and gives
+// the rest their overridden severity. Applying it twice changes nothing.
+func applyRuleSettings(issues []Issue, opts Options) []Issue {
+	if len(opts.DisabledRules) == 0 && len(opts.EnabledRules) == 0 && len(opts.Severities) == 0 {
+		return issues
+	}
+	severities := make(map[string]Severity, len(opts.Severities))
+	for id, severity := range opts.Severities {
+		severities[CurrentRuleID(id)] = severity
+	}
+	kept := issues[:0]
+	for _, issue := range issues {
+		if !opts.reportsRule(issue.RuleID) {
+			continue
+		}
+		if severity, ok := severities[issue.RuleID]; ok {
+			issue.Severity = severity
+		}
+		kept = append(kept, issue)
+	}
+	return kept
+}
+
+func (o Options) environments() []string {
+	if len(o.Environments) > 0 {
+		return o.Environments
+	}
+	if values := o.Enums[envPath]; len(values) > 0 {
+		return values
+	}
+	return allowedEnvironments
+}
+
+// declaresVariable reports whether name is in TerraformVariables.
+func (o Options) declaresVariable(name string) bool {
+	if o.compiled != nil && o.compiled.tfVars != nil {
+		_, ok := o.compiled.tfVars[name]
+		return ok
+	}
+	return contains(o.TerraformVariables, name)
+}
+
+func (o Options) overlayMerge() string {
+	if o.OverlayMerge != "" {
+		return o.OverlayMerge
+	}
+	return MergeDeep
+}
+
+// Hash fingerprints the effective options so a report can be traced back to
+// the exact rule configuration that produced it.
+func (o Options) Hash() string {
+	effective := Options{
+		Environments:   o.environments(),
+		DefaultTimeout: o.defaultTimeout(),
+		Profiles:       o.Profiles,
+
+		TerraformVariables: o.TerraformVariables,
+		Limits:             o.Limits.Effective(),
+		Style:              o.Style,
+		Governance:         o.Governance.effective(),
+		TemplateVariables:  o.TemplateVariables,
+		ConsumedKeys:       o.ConsumedKeys,
+		StrictKeys:         o.StrictKeys,
+		Redact:             o.Redact,
+		OverlayMerge:       o.overlayMerge(),
+		Defaults:           make(map[string]any),
+		MaxWarnings:        o.MaxWarnings,
+		ReviewWindowDays:   o.ReviewWindowDays,
+		MaxProdRollout:     o.MaxProdRollout,
+		Generated:          o.Generated,
+		Messages:           o.Messages,
+		DisabledRules:      o.DisabledRules,
+		EnabledRules:       o.EnabledRules,
+		Severities:         o.Severities,
+		FailOn:             o.FailOn,
+		ExpressionRules:    o.ExpressionRules,
+		Schema:             o.Schema,
+		CUESchema:          o.CUESchema,
+		CUEDefinition:      o.CUEDefinition,
+		Patterns:           o.Patterns,
+		Enums:              o.Enums,
+		Sections:           o.Sections,
+	}
+	for key, value := range o.defaults() {
+		effective.Defaults[key] = value
+	}
+	data, _ := json.Marshal(effective)
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,246 @@
+package linter
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// Overlay merge modes for Options.OverlayMerge.
+const (
+	// MergeDeep merges overlay keys into the base key by key; features are
+	// matched by name and merged field by field.
+	MergeDeep = "deep"
+	// MergeReplace replaces every section an overlay sets as a whole.
+	MergeReplace = "replace"
+)
+
+// Source is a named document.
+type Source struct {
+	Name string
+	Data []byte
+}
+
+// LintOverlays lints base with overlays applied in order (base.yaml plus
+// prod.yaml, say) as one merged document, and warns about overlay keys the
+// base does not have (OVL001), which are usually typos. Every issue has File
+// set to the document it was found in.
+func LintOverlays(base Source, overlays []Source, opts Options) ([]Issue, error) {
+	docs := make([]namedConfig, 0, 1+len(overlays))
+	for _, doc := range append([]Source{base}, overlays...) {
+		cfg, err := parseStream(bytes.NewReader(doc.Data), opts.Limits)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", doc.Name, err)
+		}
+		docs = append(docs, namedConfig{name: doc.Name, cfg: cfg})
+	}
+	return lintMerged(docs, opts, true), nil
+}
+
+type namedConfig struct {
+	name string
+	cfg  parsedConfig
+}
+
+// lintMerged lints docs merged in order and sets File on every issue. Each
+// document is shifted to its own line range first, so an issue's line tells
+// which document it came from. With checkKeys, keys that the first document
+// lacks are reported as OVL001.
+func lintMerged(docs []namedConfig, opts Options, checkKeys bool) []Issue {
+	offsets := make([]int, len(docs))
+	var merged, base parsedConfig
+	var unknown []Issue
+
+	offset := 0
+	for i, doc := range docs {
+		offsets[i] = offset
+		shifted := newParsedConfig()
+		mergeShifted(&shifted, doc.cfg, offset)
+		for _, s := range doc.cfg.Suppressions {
+			s.Line += offset
+			s.Comment += offset
+			shifted.Suppressions = append(shifted.Suppressions, s)
+		}
+
+		if i == 0 {
+			base, merged = shifted, shifted
+		} else {
+			if checkKeys {
+				unknown = append(unknown, overlayUnknownKeys(base, shifted, docs[0].name, opts.overlayMerge())...)
+			}
+			merged = mergeOverlay(merged, shifted, opts.overlayMerge())
+		}
+		offset += doc.cfg.Lines + 1
+	}
+
+	issues := append(lintParsed(merged, opts), unknown...)
+	for i := range issues {
+		doc := sort.Search(len(offsets), func(j int) bool { return offsets[j] >= issues[i].Line }) - 1
+		if doc < 0 {
+			doc = 0
+		}
+		issues[i].File = docs[doc].name
+		if issues[i].Line > 0 {
+			issues[i].Line -= offsets[doc]
+		}
+	}
+	return issues
+}
+
+// mergeOverlay applies overlay on top of base. Both are already shifted to
+// their own line ranges, so the merged fields keep pointing at the document
+// they came from.
+func mergeOverlay(base, overlay parsedConfig, mode string) parsedConfig {
+	out := newParsedConfig()
+	out.Suppressions = append(append(out.Suppressions, base.Suppressions...), overlay.Suppressions...)
+
+	replace := mode == MergeReplace
+	section := func(dst, b, o map[string]fieldInfo, bLine, oLine int) int {
+		if !replace || oLine == 0 {
+			for k, v := range b {
+				dst[k] = v
+			}
+		}
+		for k, v := range o {
+			dst[k] = v
+		}
+		if oLine > 0 && (replace || bLine == 0) {
+			return oLine
+		}
+		return bLine
+	}
+	out.MetadataLine = section(out.Metadata, base.Metadata, overlay.Metadata, base.MetadataLine, overlay.MetadataLine)
+	out.SettingsLine = section(out.Settings, base.Settings, overlay.Settings, base.SettingsLine, overlay.SettingsLine)
+	for k, v := range base.TopLevel {
+		out.TopLevel[k] = v
+	}
+	for k, v := range overlay.TopLevel {
+		out.TopLevel[k] = v
+	}
+
+	if replace && overlay.MetadataLine > 0 {
+		out.MetadataMaps = overlay.MetadataMaps
+	} else {
+		for name, fields := range base.MetadataMaps {
+			if out.MetadataMaps == nil {
+				out.MetadataMaps = make(map[string]map[string]fieldInfo)
+			}
+			out.MetadataMaps[name] = make(map[string]fieldInfo)
+			section(out.MetadataMaps[name], fields, overlay.MetadataMaps[name], 0, 0)
+		}
+		for name, fields := range overlay.MetadataMaps {
+			if _, ok := base.MetadataMaps[name]; !ok {
+				if out.MetadataMaps == nil {
+					out.MetadataMaps = make(map[string]map[string]fieldInfo)
+				}
+				out.MetadataMaps[name] = fields
+			}
+		}
+	}
+
+	for name, s := range base.Sections {
+		out.Sections[name] = s
+	}
+	for name, o := range overlay.Sections {
+		b, ok := base.Sections[name]
+		if !ok || replace {
+			out.Sections[name] = o
+			continue
+		}
+		fields := make(map[string]fieldInfo, len(b.Fields)+len(o.Fields))
+		section(fields, b.Fields, o.Fields, 0, 0)
+		out.Sections[name] = sectionInfo{Fields: fields, Line: b.Line}
+	}
+
+	out.FeaturesLine = base.FeaturesLine
+	out.Features = base.Features
+	if overlay.FeaturesLine > 0 {
+		if replace || base.FeaturesLine == 0 {
+			out.FeaturesLine = overlay.FeaturesLine
+			out.Features = overlay.Features
+		} else {
+			out.Features = mergeFeatures(base.Features, overlay.Features)
+		}
+	}
+	return out
+}
+
+// mergeFeatures merges overlay entries into the base entry with the same
+// name and appends the rest.
+func mergeFeatures(base, overlay []featureEntry) []featureEntry {
+	out := make([]featureEntry, len(base))
+	copy(out, base)
+	for _, o := range overlay {
+		i := featureIndex(out, o.Fields["name"].Value)
+		if i < 0 {
+			out = append(out, o)
+			continue
+		}
+		fields := make(map[string]fieldInfo, len(out[i].Fields)+len(o.Fields))
+		for k, v := range out[i].Fields {
+			fields[k] = v
+		}
+		for k, v := range o.Fields {
+			fields[k] = v
+		}
+		out[i].Fields = fields
+	}
+	return out
+}
+
+func featureIndex(features []featureEntry, name string) int {
+	if name == "" {
+		return -1
+	}
+	for i, f := range features {
+		if f.Fields["name"].Value == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// overlayUnknownKeys reports overlay keys with no counterpart in base. New
+// features are only reported in deep mode; a replacing features list is
+// expected to differ.
+func overlayUnknownKeys(base, overlay parsedConfig, baseName, mode string) []Issue {
+	var issues []Issue
+	report := func(path string, info fieldInfo) {
+		issues = append(issues, Issue{
+			Line:         info.Line,
+			Column:       info.Col,
+			Severity:     SeverityWarning,
+			RuleID:       "OVL001",
+			Message:      fmt.Sprintf("%s is not set in %s", path, baseName),
+			SuggestedFix: fmt.Sprintf("Fix the key name or add %s to %s", path, baseName),
+		})
+	}
+	check := func(prefix string, b, o map[string]fieldInfo) {
+		for k, v := range o {
+			if _, ok := b[k]; !ok {
+				report(prefix+k, v)
+			}
+		}
+	}
+
+	check("metadata.", base.Metadata, overlay.Metadata)
+	check("settings.", base.Settings, overlay.Settings)
+	check("", base.TopLevel, overlay.TopLevel)
+	for name, o := range overlay.Sections {
+		b, ok := base.Sections[name]
+		if !ok {
+			report(name, fieldInfo{Line: o.Line, Col: 1})
+			continue
+		}
+		check(name+".", b.Fields, o.Fields)
+	}
+	if mode != MergeReplace {
+		for _, o := range overlay.Features {
+			if name := o.Fields["name"].Value; name != "" && featureIndex(base.Features, name) < 0 {
+				report(fmt.Sprintf("feature %q", name), fieldInfo{Line: o.Line, Col: o.Col})
+			}
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+	return issues
+}
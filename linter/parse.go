@@ -0,0 +1,181 @@
+package linter
+
+import "bytes"
+
+// Config is a parsed config as the rules see it, for tools that build their
+// own checks. Values are the scalar text ("30", "true"); lists of scalars
+// read as "[a, b]" and nested mappings have an empty value.
+type Config struct {
+	// Format is "yaml", "json" or "xml".
+	Format string
+	// Metadata, Settings and Features are nil when the config has none.
+	Metadata *Section
+	Settings *Section
+	Features []Feature
+	// Sections holds every other top-level mapping, with the keys of all
+	// its nested mappings flattened into Fields.
+	Sections map[string]*Section
+	// TopLevel holds scalar keys at the document root (apiVersion, kind).
+	TopLevel map[string]Field
+	// Includes are the include:/extends: references, in order.
+	Includes []Field
+	// Lines is the number of lines read.
+	Lines int
+	// ParseIssues are problems found while reading, such as malformed XML;
+	// what was read before them is still in the tree.
+	ParseIssues []Issue
+	// Comments are the comments before and after the document's content,
+	// and FeaturesComments those on the features key.
+	Comments         Comments
+	FeaturesComments Comments
+	Layout           Layout
+}
+
+// Comments are the comments around a key or document, as written, "#"
+// included; lines of a multi-line comment are joined with "\n". Head is
+// above the key, Line after it on the same line and Foot below its value,
+// separated from the next key by a blank line. They are only kept for YAML
+// documents up to 16 MiB; other formats and larger files have none.
+type Comments struct {
+	Head string `json:"head,omitempty"`
+	Line string `json:"line,omitempty"`
+	Foot string `json:"foot,omitempty"`
+}
+
+// Layout is how the document's text is laid out, for tools that write it
+// back in the same shape.
+type Layout struct {
+	// Indent is the indentation step of nested keys, 0 if there are none.
+	Indent int
+	// CRLF is set when lines end in "\r\n".
+	CRLF bool
+	// FinalNewline is set when the last line is terminated.
+	FinalNewline bool
+}
+
+// Section is a top-level mapping.
+type Section struct {
+	Name     string
+	Line     int
+	Comments Comments
+	// Fields are the section's keys. For metadata they include the keys of
+	// nested mappings such as labels, which Maps also groups by mapping.
+	Fields map[string]Field
+	Maps   map[string]map[string]Field
+}
+
+// Field is one key and its value.
+type Field struct {
+	Key    string
+	Value  string
+	Line   int
+	Column int
+	// Quoted is set when a YAML value was written in quotes.
+	Quoted bool
+	// Style is how a YAML value was written: "plain", "single", "double",
+	// "literal" (|), "folded" (>), "flow" ([a, b] or {a: b}), "block" (a
+	// nested list or mapping) or "alias" (*name). It is empty for other
+	// formats.
+	Style    string
+	Comments Comments
+}
+
+// Feature is one entry of the features list.
+type Feature struct {
+	Line     int
+	Column   int
+	Fields   map[string]Field
+	Comments Comments
+}
+
+// Name is the feature's name field, or "" if it has none.
+func (f Feature) Name() string {
+	return f.Fields["name"].Value
+}
+
+// Parse reads a YAML, JSON or XML config into a Config, with the default
+// limits. It fails on the same input Lint does: a *LimitError, or
+// ErrNotText for binary content.
+func Parse(data []byte) (*Config, error) {
+	cfg, err := parseStream(bytes.NewReader(data), Limits{})
+	if err != nil {
+		return nil, err
+	}
+	out := exportConfig(cfg)
+	out.Layout = layoutOf(data)
+	return out, nil
+}
+
+// layoutOf reads the layout of data. The indent is the smallest step
+// between the indentation of a line and a deeper following line.
+func layoutOf(data []byte) Layout {
+	l := Layout{
+		CRLF:         bytes.Contains(data, []byte("\r\n")),
+		FinalNewline: bytes.HasSuffix(data, []byte("\n")),
+	}
+	prev := -1
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		trimmed := bytes.TrimLeft(line, " ")
+		if len(bytes.TrimSpace(trimmed)) == 0 || trimmed[0] == '#' {
+			continue
+		}
+		indent := len(line) - len(trimmed)
+		if step := indent - prev; prev >= 0 && step > 0 && (l.Indent == 0 || step < l.Indent) {
+			l.Indent = step
+		}
+		prev = indent
+		// The keys of a list item line up with the first one, after "- ".
+		if bytes.HasPrefix(trimmed, []byte("- ")) {
+			prev += 2
+		}
+	}
+	return l
+}
+
+func exportConfig(cfg parsedConfig) *Config {
+	out := &Config{
+		Format:           "yaml",
+		Sections:         make(map[string]*Section, len(cfg.Sections)),
+		TopLevel:         exportFields(cfg.TopLevel),
+		Lines:            cfg.Lines,
+		ParseIssues:      cfg.ParseIssues,
+		Comments:         cfg.Comments,
+		FeaturesComments: cfg.KeyComments["features"],
+	}
+	switch {
+	case cfg.JSON:
+		out.Format = "json"
+	case cfg.Frontend != "":
+		out.Format = cfg.Frontend
+	}
+	if cfg.MetadataLine > 0 || len(cfg.Metadata) > 0 {
+		out.Metadata = &Section{Name: "metadata", Line: cfg.MetadataLine, Comments: cfg.KeyComments["metadata"], Fields: exportFields(cfg.Metadata)}
+		for name, fields := range cfg.MetadataMaps {
+			if out.Metadata.Maps == nil {
+				out.Metadata.Maps = make(map[string]map[string]Field, len(cfg.MetadataMaps))
+			}
+			out.Metadata.Maps[name] = exportFields(fields)
+		}
+	}
+	if cfg.SettingsLine > 0 || len(cfg.Settings) > 0 {
+		out.Settings = &Section{Name: "settings", Line: cfg.SettingsLine, Comments: cfg.KeyComments["settings"], Fields: exportFields(cfg.Settings)}
+	}
+	for _, f := range cfg.Features {
+		out.Features = append(out.Features, Feature{Line: f.Line, Column: f.Col, Fields: exportFields(f.Fields), Comments: f.Comments})
+	}
+	for name, s := range cfg.Sections {
+		out.Sections[name] = &Section{Name: name, Line: s.Line, Comments: s.Comments, Fields: exportFields(s.Fields)}
+	}
+	for _, ref := range cfg.Includes {
+		out.Includes = append(out.Includes, Field{Value: ref.Value, Line: ref.Line, Column: ref.Col})
+	}
+	return out
+}
+
+func exportFields(fields map[string]fieldInfo) map[string]Field {
+	out := make(map[string]Field, len(fields))
+	for key, info := range fields {
+		out[key] = Field{Key: key, Value: info.Value, Line: info.Line, Column: info.Col, Quoted: info.Quoted, Style: info.Style, Comments: info.Comments}
+	}
+	return out
+}
@@ -0,0 +1,175 @@
+package linter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recursiveAlias is a mapping holding an alias of itself.
+const recursiveAlias = "a: &a\n  b: *a\n"
+
+// aliasBomb nests lists of nine aliases levels deep, each level expanding
+// to nine copies of the one before.
+func aliasBomb(levels int) string {
+	var b strings.Builder
+	b.WriteString("extra:\n  a0: &a0 {k: v}\n")
+	for i := 1; i < levels; i++ {
+		alias := fmt.Sprintf("*a%d", i-1)
+		fmt.Fprintf(&b, "  a%d: &a%d [%s]\n", i, i, strings.TrimSuffix(strings.Repeat(alias+", ", 9), ", "))
+	}
+	return b.String()
+}
+
+func TestParse(t *testing.T) {
+	content := []byte(`apiVersion: v1
+metadata:
+  name: billing
+  labels:
+    team: payments
+settings:
+  timeout: "30"
+features:
+  - name: beta
+    enabled: true
+database:
+  pool:
+    size: 4
+`)
+
+	cfg, err := Parse(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Format != "yaml" || cfg.Lines != 13 || cfg.TopLevel["apiVersion"].Value != "v1" {
+		t.Errorf("expected a 13-line YAML config with apiVersion v1, got %+v", cfg)
+	}
+	if cfg.Metadata == nil || cfg.Metadata.Line != 2 || cfg.Metadata.Maps["labels"]["team"].Value != "payments" {
+		t.Errorf("expected metadata with its labels, got %+v", cfg.Metadata)
+	}
+	if got := cfg.Settings.Fields["timeout"]; got != (Field{Key: "timeout", Value: "30", Line: 7, Column: 3, Quoted: true, Style: "double"}) {
+		t.Errorf("expected the quoted timeout, got %+v", got)
+	}
+	if len(cfg.Features) != 1 || cfg.Features[0].Name() != "beta" || cfg.Features[0].Line != 9 {
+		t.Errorf("expected the beta feature on line 9, got %+v", cfg.Features)
+	}
+	if got := cfg.Sections["database"]; got == nil || got.Fields["size"].Value != "4" {
+		t.Errorf("expected the database section flattened, got %+v", got)
+	}
+
+	cfg, err = Parse([]byte(`{"settings": {"replicas": 2}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Format != "json" || cfg.Metadata != nil || cfg.Settings.Fields["replicas"].Value != "2" {
+		t.Errorf("expected a JSON config with settings only, got %+v", cfg)
+	}
+}
+
+func TestParseRejectsAliasExpansion(t *testing.T) {
+	for _, tc := range []struct {
+		name, data string
+		line       int
+		message    string
+	}{
+		{"cycle", recursiveAlias, 2, "malformed YAML: alias *a refers to a node that contains it"},
+		{"bomb", aliasBomb(9), 8, "malformed YAML: document contains excessive aliasing"},
+	} {
+		start := time.Now()
+		issues, err := LintBytes([]byte(tc.data))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if len(issues) == 0 || issues[0].RuleID != "SYN001" || issues[0].Line != tc.line || issues[0].Message != tc.message {
+			t.Errorf("%s: expected SYN001 on line %d, got %+v", tc.name, tc.line, issues)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("%s: took %s", tc.name, elapsed)
+		}
+	}
+}
+
+func TestLoneCarriageReturnKeepsLines(t *testing.T) {
+	// The decoder breaks lines at a lone \r; the scanner, and editors, do
+	// not.
+	for _, data := range []string{"0: \r0", "metadata:\r  name: a\r  env: qa\n"} {
+		issues, err := LintBytes([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Count(data, "\n") + 1
+		for _, issue := range issues {
+			if issue.Line > lines {
+				t.Errorf("%q: issue past the last line %d: %+v", data, lines, issue)
+			}
+		}
+	}
+	issues, _ := LintBytes([]byte("0: \r0"))
+	if len(issues) == 0 || issues[0].RuleID != "SYN001" || issues[0].Line != 1 {
+		t.Errorf("expected SYN001 on line 1, got %+v", issues)
+	}
+}
+
+func TestParseKeepsComments(t *testing.T) {
+	content := []byte("# payments service\r\n\r\n# who owns it\r\nmetadata: # required\r\n    # the service name\r\n    name: billing # do not rename\r\n    env: prod\r\nsettings:\r\n    motd: |\r\n        hello\r\nfeatures:\r\n    # beta only\r\n    - name: beta\r\n      enabled: true\r\n")
+
+	cfg, err := Parse(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Comments.Head != "# payments service" {
+		t.Errorf("expected the document comment, got %+v", cfg.Comments)
+	}
+	if want := (Comments{Head: "# who owns it", Line: "# required"}); cfg.Metadata.Comments != want {
+		t.Errorf("expected %+v on metadata, got %+v", want, cfg.Metadata.Comments)
+	}
+	if want := (Comments{Head: "# the service name", Line: "# do not rename"}); cfg.Metadata.Fields["name"].Comments != want {
+		t.Errorf("expected %+v on name, got %+v", want, cfg.Metadata.Fields["name"].Comments)
+	}
+	if got := cfg.Settings.Fields["motd"].Style; got != "literal" {
+		t.Errorf("expected a literal block scalar, got %q", got)
+	}
+	if got := cfg.Features[0].Comments.Head; got != "# beta only" {
+		t.Errorf("expected the feature's comment, got %q", got)
+	}
+	if cfg.Layout != (Layout{Indent: 4, CRLF: true, FinalNewline: true}) {
+		t.Errorf("unexpected layout %+v", cfg.Layout)
+	}
+}
+
+func TestHash(t *testing.T) {
+	base := "metadata:\n  name: payments\n  env: prod\nsettings:\n  replicas: 3\n  tags: [a, b]\n"
+	hash := func(doc string) string {
+		t.Helper()
+		sum, err := Hash([]byte(doc))
+		if err != nil {
+			t.Fatalf("Hash(%q): %v", doc, err)
+		}
+		return sum
+	}
+	want := hash(base)
+
+	for _, same := range []string{
+		"# payments\nsettings:\n  tags:\n    - a\n    - \"b\"\n  replicas: 3   # three\nmetadata: {env: prod, name: 'payments'}\n",
+		"metadata: &m\r\n    name: payments\r\n    env: prod\r\nsettings:\r\n    replicas: 3\r\n    tags: [a, b]\r\n",
+		`{"settings": {"tags": ["a", "b"], "replicas": 3}, "metadata": {"name": "payments", "env": "prod"}}`,
+	} {
+		if got := hash(same); got != want {
+			t.Errorf("expected %q to hash like the base config", same)
+		}
+	}
+	for _, changed := range []string{
+		strings.Replace(base, "replicas: 3", "replicas: \"3\"", 1),
+		strings.Replace(base, "[a, b]", "[b, a]", 1),
+		strings.Replace(base, "env: prod", "env: dev", 1),
+	} {
+		if hash(changed) == want {
+			t.Errorf("expected %q to hash differently", changed)
+		}
+	}
+
+	if _, err := Hash([]byte("metadata: [unclosed\n")); err == nil {
+		t.Error("expected a syntax error")
+	}
+}
@@ -0,0 +1,113 @@
+package linter
+
+import "strconv"
+
+// pathIndex maps a position to the dotted key path written there. Flow
+// mappings put several keys on one line, so keys are matched by line and
+// column; an issue without a matching column gets the section or feature
+// entry starting on its line, or else the leftmost key. A feature entry's
+// dash shares its line with the entry's first key, so entries are kept
+// apart and matched by column too. Keys merged in with <<: share the
+// position of the merge key; the first in name order wins. Keys of nested
+// metadata mappings are also metadata keys, and their full path wins.
+type pathIndex struct {
+	keys     map[position]string
+	lines    map[int]string
+	features map[int]featurePath
+}
+
+type position struct {
+	line, col int
+}
+
+type featurePath struct {
+	col  int
+	path string
+}
+
+func newPathIndex(cfg parsedConfig) pathIndex {
+	idx := pathIndex{keys: make(map[position]string), lines: make(map[int]string), features: make(map[int]featurePath)}
+	// leftmost is the column of the key idx.lines holds for a line; heads
+	// are lines a section or feature entry starts on.
+	leftmost := make(map[int]int)
+	heads := make(map[int]bool)
+	// added holds the positions of earlier add calls, which take priority.
+	added := make(map[position]bool)
+	add := func(prefix string, fields map[string]fieldInfo) {
+		taken := make(map[position]bool)
+		for key, info := range fields {
+			path := prefix + key
+			at := position{info.Line, info.Col}
+			if added[at] {
+				continue
+			}
+			if old, ok := idx.keys[at]; !ok || path < old {
+				idx.keys[at] = path
+			}
+			taken[at] = true
+			if heads[info.Line] {
+				continue
+			}
+			if col, ok := leftmost[info.Line]; !ok || info.Col < col || info.Col == col && path < idx.lines[info.Line] {
+				leftmost[info.Line] = info.Col
+				idx.lines[info.Line] = path
+			}
+		}
+		for at := range taken {
+			added[at] = true
+		}
+	}
+	section := func(name string, line int) {
+		if line > 0 {
+			idx.lines[line] = name
+			heads[line] = true
+		}
+	}
+
+	section("metadata", cfg.MetadataLine)
+	section("settings", cfg.SettingsLine)
+	for name, s := range cfg.Sections {
+		section(name, s.Line)
+	}
+	section("features", cfg.FeaturesLine)
+	for i, feature := range cfg.Features {
+		prefix := "features[" + strconv.Itoa(i) + "]"
+		section(prefix, feature.Line)
+		idx.features[feature.Line] = featurePath{col: feature.Col, path: prefix}
+	}
+
+	add("", cfg.TopLevel)
+	for name, fields := range cfg.MetadataMaps {
+		add("metadata."+name+".", fields)
+	}
+	add("metadata.", cfg.Metadata)
+	add("settings.", cfg.Settings)
+	for name, s := range cfg.Sections {
+		add(name+".", s.Fields)
+	}
+	for i, feature := range cfg.Features {
+		add("features["+strconv.Itoa(i)+"].", feature.Fields)
+	}
+	return idx
+}
+
+func (idx pathIndex) lookup(line, col int) string {
+	if path, ok := idx.keys[position{line, col}]; ok && col > 0 {
+		return path
+	}
+	if f, ok := idx.features[line]; ok && (col == 0 || col == f.col) {
+		return f.path
+	}
+	return idx.lines[line]
+}
+
+// assignPaths sets Issue.Path from each issue's line. Issues on lines
+// without a key, and those already carrying a path, are left alone.
+func assignPaths(issues []Issue, cfg parsedConfig) {
+	idx := newPathIndex(cfg)
+	for i := range issues {
+		if issues[i].Path == "" {
+			issues[i].Path = idx.lookup(issues[i].Line, issues[i].Column)
+		}
+	}
+}
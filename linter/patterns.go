@@ -0,0 +1,121 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// compilePatterns compiles Options.Patterns, naming the key path of the
+// first one that does not compile.
+func compilePatterns(patterns map[string]string) (map[string]*regexp.Regexp, error) {
+	compiled := make(map[string]*regexp.Regexp, len(patterns))
+	for _, path := range sortedKeys(patterns) {
+		if strings.TrimSpace(path) == "" {
+			return nil, fmt.Errorf("patterns must not contain an empty key path")
+		}
+		re, err := regexp.Compile(patterns[path])
+		if err != nil {
+			return nil, fmt.Errorf("pattern for %s: %w", path, err)
+		}
+		compiled[path] = re
+	}
+	return compiled, nil
+}
+
+// valuePatterns returns the compiled Patterns; ones that do not compile
+// are left out, as Validate reports them.
+func (o Options) valuePatterns() map[string]*regexp.Regexp {
+	if o.compiled != nil && o.compiled.patterns != nil {
+		return o.compiled.patterns
+	}
+	compiled, _ := compilePatterns(o.Patterns)
+	return compiled
+}
+
+// patternRules is the rule checking Options.Patterns, if any are set.
+func patternRules(opts Options) []namedRule {
+	if len(opts.Patterns) == 0 {
+		return nil
+	}
+	return []namedRule{{"patterns", validatePatterns}}
+}
+
+// validatePatterns reports values that do not match the pattern declared
+// for their key path (PAT001), quoting the value. Each item of a list is
+// matched on its own; keys without a value, such as nested mappings, are
+// not checked.
+func validatePatterns(cfg parsedConfig, opts Options, issues *[]Issue) {
+	patterns := opts.valuePatterns()
+	for _, v := range patternValues(cfg) {
+		for _, declared := range sortedKeys(patterns) {
+			re := patterns[declared]
+			if !consumes(declared, v.field) {
+				continue
+			}
+			values := []fieldInfo{v.value}
+			if !v.value.Quoted {
+				values = flowList(v.value.Value, v.value.Line, v.value.Col)
+			}
+			for _, item := range values {
+				if re.MatchString(item.Value) {
+					continue
+				}
+				*issues = append(*issues, Issue{
+					Line:     item.Line,
+					Column:   item.Col,
+					Severity: SeverityError,
+					RuleID:   "PAT001",
+					Message:  fmt.Sprintf("%s value %q does not match %s", v.field, item.Value, re),
+				})
+			}
+		}
+	}
+}
+
+// patternValues lists the values of the config by key path, as
+// configValues does, with keys of mappings nested in metadata under
+// metadata.<mapping>.<key> and keys without a value left out.
+func patternValues(cfg parsedConfig) []fieldValue {
+	nested := make(map[int]bool)
+	var values []fieldValue
+	for name, fields := range cfg.MetadataMaps {
+		for key, info := range fields {
+			values = append(values, fieldValue{field: "metadata." + name + "." + key, value: info})
+			nested[info.Line] = true
+		}
+	}
+	for _, v := range configValues(cfg) {
+		if strings.HasPrefix(v.field, "metadata.") && nested[v.value.Line] {
+			continue
+		}
+		values = append(values, v)
+	}
+	kept := values[:0]
+	for _, v := range values {
+		if v.value.Value != "" || v.value.Quoted {
+			kept = append(kept, v)
+		}
+	}
+	sortFieldValues(kept)
+	return kept
+}
+
+// addPatterns adds the patterns of section.key paths to a schema built by
+// JSONSchema, so editors flag values that do not match. A key that already
+// has a pattern, such as a governed metadata.owner, must match both.
+func addPatterns(schema map[string]any, patterns map[string]string) {
+	for _, path := range sortedKeys(patterns) {
+		name, key, ok := strings.Cut(path, ".")
+		if !ok || strings.Contains(key, ".") || key == "*" {
+			continue
+		}
+		prop := schemaProperty(schemaSection(schema, name), key)
+		if _, taken := prop["pattern"]; taken {
+			allOf, _ := prop["allOf"].([]any)
+			prop["allOf"] = append(allOf, map[string]any{"pattern": patterns[path]})
+			continue
+		}
+		prop["pattern"] = patterns[path]
+	}
+}
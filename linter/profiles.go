@@ -0,0 +1,181 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// profile is an optional built-in rule pack for a widely used document
+// format. When enabled, documents it matches (by apiVersion/kind) are
+// validated by the profile instead of the default metadata/settings/features
+// checks.
+type profile struct {
+	name     string
+	match    func(cfg parsedConfig) bool
+	validate func(cfg parsedConfig, opts Options, issues *[]Issue)
+	// additive profiles tighten the default checks rather than replace them;
+	// validate runs after the default rules.
+	additive bool
+}
+
+var profiles = map[string]profile{
+	"backstage": {
+		name: "backstage",
+		match: func(cfg parsedConfig) bool {
+			return strings.HasPrefix(cfg.TopLevel["apiVersion"].Value, "backstage.io/")
+		},
+		validate: validateBackstage,
+	},
+	"prod": {
+		name: "prod",
+		match: func(cfg parsedConfig) bool {
+			return cfg.Metadata["env"].Value == "prod"
+		},
+		validate: validateProd,
+		additive: true,
+	},
+}
+
+// Profiles lists the names of the built-in profiles.
+func Profiles() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// routeProfile returns the first enabled profile matching the document.
+func routeProfile(cfg parsedConfig, opts Options) (profile, bool) {
+	for _, name := range opts.Profiles {
+		if p, ok := profiles[name]; ok && p.match(cfg) {
+			return p, true
+		}
+	}
+	return profile{}, false
+}
+
+// validateProd requires prod configs to spell out every feature's enabled
+// flag (PROD001) instead of leaving it to whatever the consumer defaults to.
+func validateProd(cfg parsedConfig, _ Options, issues *[]Issue) {
+	for i, feature := range cfg.Features {
+		if len(feature.Fields) == 0 {
+			continue
+		}
+		if _, ok := feature.Fields["enabled"]; ok {
+			continue
+		}
+		*issues = append(*issues, Issue{
+			Line:         feature.Line,
+			Column:       feature.Col,
+			Severity:     SeverityError,
+			RuleID:       "PROD001",
+			Message:      fmt.Sprintf("features[%d] must set enabled explicitly in prod", i),
+			SuggestedFix: "Add enabled: false (or true)",
+		})
+	}
+}
+
+var (
+	backstageAPIVersions = []string{"backstage.io/v1alpha1", "backstage.io/v1beta1"}
+	backstageKinds       = []string{"Component", "API", "Resource", "System", "Domain", "Group", "User", "Location", "Template"}
+	backstageNamePattern = regexp.MustCompile(`^[a-zA-Z0-9]([-_.a-zA-Z0-9]*[a-zA-Z0-9])?$`)
+
+	// backstageRequiredSpec lists the spec fields each kind must set.
+	backstageRequiredSpec = map[string][]string{
+		"Component": {"type", "lifecycle", "owner"},
+		"API":       {"type", "lifecycle", "owner", "definition"},
+		"Resource":  {"type", "owner"},
+		"System":    {"owner"},
+		"Domain":    {"owner"},
+		"Group":     {"type"},
+	}
+)
+
+// validateBackstage checks catalog-info.yaml entity descriptors.
+func validateBackstage(cfg parsedConfig, opts Options, issues *[]Issue) {
+	apiVersion := cfg.TopLevel["apiVersion"]
+	if !contains(backstageAPIVersions, apiVersion.Value) {
+		*issues = append(*issues, Issue{
+			Line:         apiVersion.Line,
+			Column:       apiVersion.Col,
+			Severity:     SeverityError,
+			RuleID:       "BS001",
+			Message:      fmt.Sprintf("apiVersion %q is not a supported Backstage version", apiVersion.Value),
+			SuggestedFix: fmt.Sprintf("Use one of: %s", strings.Join(backstageAPIVersions, ", ")),
+		})
+	}
+
+	kind, hasKind := cfg.TopLevel["kind"]
+	if !hasKind {
+		*issues = append(*issues, Issue{
+			Line:         1,
+			Severity:     SeverityError,
+			RuleID:       "BS002",
+			Message:      "kind is required",
+			SuggestedFix: fmt.Sprintf("Set kind to one of: %s", strings.Join(backstageKinds, ", ")),
+		})
+	} else if !contains(backstageKinds, kind.Value) {
+		*issues = append(*issues, Issue{
+			Line:         kind.Line,
+			Column:       kind.Col,
+			Severity:     SeverityError,
+			RuleID:       "BS002",
+			Message:      fmt.Sprintf("kind %q is not a Backstage entity kind", kind.Value),
+			SuggestedFix: fmt.Sprintf("Use one of: %s", strings.Join(backstageKinds, ", ")),
+		})
+	}
+
+	baseLine := cfg.MetadataLine
+	if baseLine == 0 {
+		baseLine = 1
+	}
+	name, hasName := cfg.Metadata["name"]
+	if !hasName || name.Value == "" {
+		*issues = append(*issues, Issue{
+			Line:         baseLine,
+			Severity:     SeverityError,
+			RuleID:       "BS003",
+			Message:      "metadata.name is required",
+			SuggestedFix: "Set metadata.name to the entity name, e.g. metadata.name: payments-api",
+		})
+	} else if len(name.Value) > 63 || !backstageNamePattern.MatchString(name.Value) {
+		*issues = append(*issues, Issue{
+			Line:         name.Line,
+			Column:       name.Col,
+			Severity:     SeverityError,
+			RuleID:       "BS003",
+			Message:      fmt.Sprintf("metadata.name %q is not a valid entity name", name.Value),
+			SuggestedFix: "Use at most 63 characters of [a-zA-Z0-9-_.], starting and ending with an alphanumeric",
+		})
+	}
+
+	required := backstageRequiredSpec[kind.Value]
+	if len(required) == 0 {
+		return
+	}
+	spec, hasSpec := cfg.Sections["spec"]
+	if !hasSpec {
+		*issues = append(*issues, Issue{
+			Line:         1,
+			Severity:     SeverityError,
+			RuleID:       "BS004",
+			Message:      fmt.Sprintf("spec is required for kind %s", kind.Value),
+			SuggestedFix: fmt.Sprintf("Add a spec mapping with: %s", strings.Join(required, ", ")),
+		})
+		return
+	}
+	for _, field := range required {
+		if spec.Fields[field].Value == "" {
+			*issues = append(*issues, Issue{
+				Line:     spec.Line,
+				Severity: SeverityError,
+				RuleID:   "BS004",
+				Message:  fmt.Sprintf("spec.%s is required for kind %s", field, kind.Value),
+			})
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package linter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LintProperties lints a Java .properties file with the same rules as YAML
+// and JSON configs. Dotted keys map onto sections: metadata.name and
+// settings.replicas are those fields, metadata.labels.team is a label,
+// features.<name>.<field> is a field of the feature <name> (features.<name>
+// alone is its enabled flag), and keys without a dot are top-level keys.
+//
+// Invalid escapes (PROP001) and keys with an empty path segment (PROP002)
+// are reported where they are.
+func LintProperties(data []byte, opts Options) ([]Issue, error) {
+	if err := checkText(data[:min(len(data), textSniff)]); err != nil {
+		return nil, err
+	}
+	limits := opts.Limits.Effective()
+	if int64(len(data)) > limits.MaxBytes {
+		return nil, &LimitError{Limit: "input size", Max: limits.MaxBytes}
+	}
+	cfg, issues, err := parseProperties(data, limits)
+	if err != nil {
+		return nil, err
+	}
+	return lintParsed(cfg, opts, issues...), nil
+}
+
+func parseProperties(data []byte, limits Limits) (parsedConfig, []Issue, error) {
+	cfg := newParsedConfig()
+	cfg.Frontend = "properties"
+	var issues []Issue
+	features := make(map[string]int) // feature key to index in cfg.Features
+
+	lines := splitLines(string(data))
+	if len(lines) > limits.MaxLines {
+		return cfg, nil, &LimitError{Limit: "line count", Max: int64(limits.MaxLines), Line: limits.MaxLines + 1}
+	}
+	cfg.Lines = len(lines)
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		lineNo := i + 1
+		if len(line) > limits.MaxLineBytes {
+			return cfg, nil, &LimitError{Limit: "line length", Max: int64(limits.MaxLineBytes), Line: lineNo}
+		}
+		if rest, directive, ok := cutSuppression(line); ok {
+			if s, ok := parseSuppression(directive, lineNo); ok {
+				cfg.Suppressions = append(cfg.Suppressions, s)
+			}
+			line = rest
+		}
+		trimmed := strings.TrimLeft(line, " \t\f")
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '!' {
+			continue
+		}
+		col := len(line) - len(trimmed) + 1
+		// A line ending in an odd number of backslashes continues on the
+		// next one, without the next line's leading whitespace.
+		for continues(trimmed) && i+1 < len(lines) {
+			i++
+			trimmed = trimmed[:len(trimmed)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+
+		rawKey, rawValue := splitProperty(trimmed)
+		key, keyErr := unescapeProperty(rawKey)
+		value, valueErr := unescapeProperty(rawValue)
+		if err := errors.Join(keyErr, valueErr); err != nil {
+			issues = append(issues, Issue{
+				Line:         lineNo,
+				Column:       col,
+				Severity:     SeverityError,
+				RuleID:       "PROP001",
+				Message:      fmt.Sprintf("property %q has an invalid escape: %v", rawKey, err),
+				SuggestedFix: `Write unicode escapes as \uXXXX`,
+			})
+			continue
+		}
+		segments := strings.Split(key, ".")
+		if contains(segments, "") {
+			issues = append(issues, Issue{
+				Line:         lineNo,
+				Column:       col,
+				Severity:     SeverityWarning,
+				RuleID:       "PROP002",
+				Message:      fmt.Sprintf("property %q has an empty path segment and is ignored", key),
+				SuggestedFix: "Remove the leading, trailing or doubled dot",
+			})
+			continue
+		}
+
+		info := fieldInfo{Value: value, Line: lineNo, Col: col}
+		switch segments[0] {
+		case "metadata":
+			if cfg.MetadataLine == 0 {
+				cfg.MetadataLine = lineNo
+			}
+			if len(segments) == 1 {
+				continue
+			}
+			if len(segments) == 2 {
+				cfg.Metadata[segments[1]] = info
+				continue
+			}
+			// metadata.labels.team: a nested map, read like YAML's.
+			name, field := segments[1], strings.Join(segments[2:], ".")
+			if _, ok := cfg.Metadata[name]; !ok {
+				cfg.Metadata[name] = fieldInfo{Line: lineNo, Col: col}
+			}
+			if cfg.MetadataMaps == nil {
+				cfg.MetadataMaps = make(map[string]map[string]fieldInfo)
+			}
+			if cfg.MetadataMaps[name] == nil {
+				cfg.MetadataMaps[name] = make(map[string]fieldInfo)
+			}
+			cfg.MetadataMaps[name][field] = info
+			cfg.Metadata[field] = info
+		case "settings":
+			if cfg.SettingsLine == 0 {
+				cfg.SettingsLine = lineNo
+			}
+			switch {
+			case len(segments) == 2:
+				cfg.Settings[segments[1]] = info
+			case len(segments) > 2:
+				// settings.db.timeout is a nested mapping, not a setting.
+				if _, ok := cfg.Settings[segments[1]]; !ok {
+					cfg.Settings[segments[1]] = fieldInfo{Line: lineNo, Col: col}
+				}
+			}
+		case "features":
+			if cfg.FeaturesLine == 0 {
+				cfg.FeaturesLine = lineNo
+			}
+			if len(segments) == 1 {
+				continue
+			}
+			id := segments[1]
+			idx, ok := features[id]
+			if !ok {
+				if len(cfg.Features) >= limits.MaxFeatures {
+					return cfg, nil, &LimitError{Limit: "feature count", Max: int64(limits.MaxFeatures), Line: lineNo}
+				}
+				entry := featureEntry{Fields: make(map[string]fieldInfo), Line: lineNo, Col: col}
+				// features.0.name indexes a list; any other id is the name.
+				if _, err := strconv.Atoi(id); err != nil {
+					entry.Fields["name"] = fieldInfo{Value: id, Line: lineNo, Col: col}
+				}
+				idx = len(cfg.Features)
+				features[id] = idx
+				cfg.Features = append(cfg.Features, entry)
+			}
+			field := "enabled"
+			if len(segments) > 2 {
+				field = strings.Join(segments[2:], ".")
+			}
+			cfg.Features[idx].Fields[field] = info
+		default:
+			if len(segments) == 1 {
+				cfg.TopLevel[key] = info
+				continue
+			}
+			s, ok := cfg.Sections[segments[0]]
+			if !ok {
+				s = sectionInfo{Fields: make(map[string]fieldInfo), Line: lineNo}
+				cfg.Sections[segments[0]] = s
+			}
+			s.Fields[segments[len(segments)-1]] = info
+		}
+	}
+	return cfg, issues, nil
+}
+
+func continues(line string) bool {
+	n := len(line) - len(strings.TrimRight(line, `\`))
+	return n%2 == 1
+}
+
+// splitProperty splits a logical line at the first unescaped =, : or
+// whitespace; whitespace around the separator belongs to neither side.
+func splitProperty(line string) (key, value string) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':', ' ', '\t', '\f':
+			key, rest := line[:i], strings.TrimLeft(line[i:], " \t\f")
+			if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+				rest = strings.TrimLeft(rest[1:], " \t\f")
+			}
+			return key, rest
+		}
+	}
+	return line, ""
+}
+
+// unescapeProperty resolves \t, \n, \r, \f, \uXXXX and escaped characters.
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", errors.New(`truncated \u escape`)
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf(`\u%s is not a hex code`, s[i+1:i+5])
+			}
+			b.WriteRune(rune(r))
+			i += 4
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
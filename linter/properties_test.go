@@ -0,0 +1,39 @@
+package linter
+
+import "testing"
+
+func TestLintProperties(t *testing.T) {
+	content := []byte(`# exported by the billing service
+metadata.name=billing
+metadata.env = qa
+metadata.labels.team: payments
+settings.replicas 0
+settings.timeout=30
+settings.db.timeout=-5
+features.beta=yes
+features.search.enabled=true
+features.search.requires=[beta, \
+    ghost]
+settings..broken=1
+settings.motd=café \u00zz
+`)
+
+	issues, err := LintNamed("billing.properties", content, Options{})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	want := map[string]int{"PROP002": 12, "PROP001": 13, "META004": 3, "SET003": 5, "FEAT003": 8, "FEAT004": 10, "FEAT005": 10}
+	got := make(map[string]int)
+	for _, issue := range issues {
+		got[issue.RuleID] = issue.Line
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %+v", want, issues)
+	}
+	for id, line := range want {
+		if got[id] != line {
+			t.Errorf("expected %s on line %d, got %+v", id, line, issues)
+		}
+	}
+}
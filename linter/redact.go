@@ -0,0 +1,349 @@
+package linter
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces config values in issue text when Options.Redact is set.
+const redacted = `"<redacted>"`
+
+// redactIssues replaces every config value, and every item of a list value,
+// quoted in an issue's message or suggested fix, leaving key paths, rule IDs
+// and positions. Rules quote the values they mention (%q), which is what is
+// matched.
+func redactIssues(issues []Issue, cfgs ...parsedConfig) {
+	seen := make(map[string]bool)
+	var quoted []string
+	add := func(value string) {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			quoted = append(quoted, strconv.Quote(value))
+		}
+	}
+	for _, cfg := range cfgs {
+		for _, v := range configValues(cfg) {
+			add(v.value.Value)
+			// Rules quote list items on their own, such as a feature's
+			// requires.
+			if !v.value.Quoted {
+				for _, item := range flowList(v.value.Value, 0, 0) {
+					add(item.Value)
+				}
+			}
+		}
+	}
+	if len(quoted) == 0 {
+		return
+	}
+	// Longest first, so a value that contains another is replaced whole.
+	sort.Slice(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+	pairs := make([]string, 0, 2*len(quoted))
+	for _, q := range quoted {
+		pairs = append(pairs, q, redacted)
+	}
+	r := strings.NewReplacer(pairs...)
+	for i := range issues {
+		issues[i].Message = r.Replace(issues[i].Message)
+		issues[i].SuggestedFix = r.Replace(issues[i].SuggestedFix)
+	}
+}
+
+// RedactConfig returns a copy of a config with every value replaced by
+// <redacted>, and issues (from linting it) with their values redacted as
+// Options.Redact would. Keys, layout and line numbers are kept, so the copy
+// still shows where each issue was. YAML and JSON documents are rebuilt
+// from their keys alone, dropping comments and the lines of multi-line
+// values; other formats have each value replaced where it was read.
+func RedactConfig(data []byte, issues []Issue) ([]byte, []Issue, error) {
+	cfg, err := parseConfig(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	out := append([]Issue(nil), issues...)
+	redactIssues(out, cfg)
+	if cfg.Frontend == "" {
+		if skeleton, ok := redactYAML(cfg.Source); ok {
+			return skeleton, out, nil
+		}
+		return redactKeyLines(data), out, nil
+	}
+
+	lines := strings.Split(string(data), "\n")
+	byLine := make(map[int][]string)
+	for _, v := range configValues(cfg) {
+		if v.value.Value != "" && v.value.Line > 0 && v.value.Line <= len(lines) {
+			byLine[v.value.Line] = append(byLine[v.value.Line], v.value.Value)
+		}
+	}
+	for n, values := range byLine {
+		lines[n-1] = redactLine(lines[n-1], values)
+	}
+	return []byte(strings.Join(lines, "\n")), out, nil
+}
+
+// redactYAML rebuilds the keys of each document in text on the lines they
+// were on, with every scalar value <redacted>. Anchors and aliases are
+// kept, tags and comments dropped. It reports false when text is empty or
+// not well-formed.
+func redactYAML(text []byte) ([]byte, bool) {
+	if len(text) == 0 {
+		return nil, false
+	}
+	text = bytes.ReplaceAll(text, []byte("\r\n"), []byte("\n"))
+	s := skeleton{src: strings.Split(string(text), "\n")}
+	s.out = make([][]fragment, len(s.src))
+	for i, line := range s.src {
+		if line == "---" || strings.HasPrefix(line, "--- ") {
+			s.add(i+1, 1, "---")
+		}
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(text))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, false
+		}
+		if len(doc.Content) == 1 {
+			s.node(doc.Content[0], false)
+		}
+	}
+	return s.render(), true
+}
+
+// fragment is text placed at a column of a skeleton line. Glued fragments
+// follow the one before them directly.
+type fragment struct {
+	col, seq int
+	text     string
+	glue     bool
+}
+
+type skeleton struct {
+	src  []string
+	out  [][]fragment
+	seq  int
+	last struct{ line, col int }
+}
+
+func (s *skeleton) add(line, col int, text string) {
+	if line < 1 || line > len(s.out) {
+		return
+	}
+	s.seq++
+	s.out[line-1] = append(s.out[line-1], fragment{col: col, seq: s.seq, text: text})
+	s.last.line, s.last.col = line, col
+}
+
+// glue appends text to the fragment added last.
+func (s *skeleton) glue(text string) {
+	if s.last.line == 0 {
+		return
+	}
+	s.seq++
+	s.out[s.last.line-1] = append(s.out[s.last.line-1], fragment{col: s.last.col, seq: s.seq, text: text, glue: true})
+}
+
+func (s *skeleton) node(n *yaml.Node, flow bool) {
+	switch {
+	case n.Kind == yaml.MappingNode && n.Style&yaml.FlowStyle != 0:
+		s.add(n.Line, n.Column, "{")
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				s.glue(",")
+			}
+			s.pair(n.Content[i], n.Content[i+1], true)
+		}
+		s.glue("}")
+	case n.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			s.pair(n.Content[i], n.Content[i+1], flow)
+		}
+	case n.Kind == yaml.SequenceNode && n.Style&yaml.FlowStyle != 0:
+		s.add(n.Line, n.Column, "[")
+		for i, item := range n.Content {
+			if i > 0 {
+				s.glue(",")
+			}
+			s.node(item, true)
+		}
+		s.glue("]")
+	case n.Kind == yaml.SequenceNode:
+		for _, item := range n.Content {
+			if line, col, ok := s.dash(item); ok {
+				s.add(line, col, "-")
+				if item.Anchor != "" && (item.Kind == yaml.MappingNode || item.Kind == yaml.SequenceNode) {
+					s.glue(" &" + item.Anchor)
+				}
+			}
+			s.node(item, flow)
+		}
+	default:
+		s.leaf(n, flow)
+	}
+}
+
+func (s *skeleton) pair(key, value *yaml.Node, flow bool) {
+	if key.Kind != yaml.ScalarNode {
+		s.leaf(key, true)
+	} else {
+		s.add(key.Line, key.Column, skeletonKey(key))
+	}
+	if value.Kind == yaml.ScalarNode && value.Value == "" && value.Style == 0 && value.Anchor == "" {
+		if !flow {
+			s.glue(":")
+		}
+		return
+	}
+	s.glue(":")
+	if value.Anchor != "" && (value.Kind == yaml.MappingNode || value.Kind == yaml.SequenceNode) {
+		s.glue(" &" + value.Anchor)
+	}
+	s.node(value, flow)
+}
+
+// leaf places a scalar's replacement, or an alias, where it starts.
+func (s *skeleton) leaf(n *yaml.Node, flow bool) {
+	text := strings.Trim(redacted, `"`)
+	if flow {
+		text = redacted
+	}
+	if n.Kind == yaml.AliasNode {
+		text = "*" + n.Value
+	}
+	if n.Anchor != "" {
+		text = "&" + n.Anchor + " " + text
+	}
+	s.add(n.Line, n.Column, text)
+}
+
+// dash finds the "-" of a block sequence item: before the item on its line,
+// or on the line before it when the item starts on the next.
+func (s *skeleton) dash(item *yaml.Node) (int, int, bool) {
+	for line := item.Line; line >= 1 && line <= len(s.src); line-- {
+		src := s.src[line-1]
+		if line == item.Line {
+			src = src[:min(item.Column-1, len(src))]
+		} else if trimmed := strings.TrimSpace(src); trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		// The last dash with only indentation and other dashes before it.
+		for j := len(src) - 1; j >= 0; j-- {
+			if src[j] == '-' && (j+1 == len(src) || src[j+1] == ' ') && strings.Trim(src[:j], " -") == "" {
+				return line, j + 1, true
+			}
+		}
+		if line < item.Line {
+			break
+		}
+	}
+	return 0, 0, false
+}
+
+// skeletonKey writes a key as it was quoted, so JSON keys stay JSON.
+func skeletonKey(key *yaml.Node) string {
+	switch key.Style {
+	case yaml.DoubleQuotedStyle:
+		return strconv.Quote(key.Value)
+	case yaml.SingleQuotedStyle:
+		return "'" + strings.ReplaceAll(key.Value, "'", "''") + "'"
+	}
+	return key.Value
+}
+
+func (s *skeleton) render() []byte {
+	lines := make([]string, len(s.out))
+	for i, frags := range s.out {
+		sort.Slice(frags, func(a, b int) bool {
+			if frags[a].col != frags[b].col {
+				return frags[a].col < frags[b].col
+			}
+			return frags[a].seq < frags[b].seq
+		})
+		var b strings.Builder
+		for _, f := range frags {
+			switch {
+			case f.glue:
+			case b.Len() < f.col-1:
+				b.WriteString(strings.Repeat(" ", f.col-1-b.Len()))
+			case b.Len() > f.col-1 && !strings.ContainsAny(b.String()[b.Len()-1:], " [{"):
+				b.WriteByte(' ')
+			}
+			b.WriteString(f.text)
+		}
+		lines[i] = b.String()
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// keyLine matches a line starting with a key, after any list dashes.
+var keyLine = regexp.MustCompile(`^(\s*(?:-\s+)*)("[^"]*"|'[^']*'|[^\s#'"{\[\]}:-][^:#]*?|-[^\s:#][^:#]*?)\s*:(?:\s|$)`)
+
+// redactKeyLines keeps the keys and list dashes of a document the YAML
+// decoder cannot read, blanking every other line.
+func redactKeyLines(data []byte) []byte {
+	lines := strings.Split(string(data), "\n")
+	marker := strings.Trim(redacted, `"`)
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		if m := keyLine.FindStringSubmatchIndex(line); m != nil {
+			key := line[:m[5]] + ":"
+			if rest := strings.TrimSpace(line[m[1]:]); rest != "" && !strings.HasPrefix(rest, "#") {
+				key += " " + marker
+			}
+			lines[i] = key
+			continue
+		}
+		trimmed := strings.TrimLeft(line, " ")
+		if strings.HasPrefix(trimmed, "- ") && strings.TrimSpace(trimmed[2:]) != "" {
+			lines[i] = line[:len(line)-len(trimmed)] + "- " + marker
+			continue
+		}
+		lines[i] = ""
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// redactLine replaces the last occurrence of each value in line, longest
+// value first, without touching text an earlier value already claimed.
+func redactLine(line string, values []string) string {
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	type span struct{ start, end int }
+	var spans []span
+	for _, v := range values {
+		limit := len(line)
+		for limit > 0 {
+			i := strings.LastIndex(line[:limit], v)
+			if i < 0 {
+				break
+			}
+			overlaps := false
+			for _, s := range spans {
+				if i < s.end && i+len(v) > s.start {
+					overlaps = true
+					break
+				}
+			}
+			if !overlaps {
+				spans = append(spans, span{i, i + len(v)})
+				break
+			}
+			limit = i + len(v) - 1
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start > spans[j].start })
+	marker := strings.Trim(redacted, `"`)
+	for _, s := range spans {
+		line = line[:s.start] + marker + line[s.end:]
+	}
+	return line
+}
@@ -0,0 +1,91 @@
+package linter
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Rule is a check supplied by the program embedding the linter, for
+// org-specific policy that does not belong in the built-in rules. Register
+// it once, typically from an init function, and every lint runs it after
+// the built-in checks:
+//
+//	type ownerRule struct{}
+//
+//	func (ownerRule) ID() string { return "ACME001" }
+//
+//	func (ownerRule) Severity() linter.Severity { return linter.SeverityError }
+//
+//	func (ownerRule) Check(cfg *linter.Config) []linter.Issue {
+//		if cfg.Metadata == nil || cfg.Metadata.Fields["owner"].Value == "" {
+//			return []linter.Issue{{Line: 1, Message: "metadata.owner is required"}}
+//		}
+//		return nil
+//	}
+//
+//	func init() { linter.Register(ownerRule{}) }
+type Rule interface {
+	// ID is the rule ID of the issues the rule reports, such as "ACME001".
+	ID() string
+	// Severity is the severity of the issues the rule reports.
+	Severity() Severity
+	// Check returns the problems in cfg. Issues without a RuleID or a
+	// Severity get ID and Severity; suppressions, baselines, redaction and
+	// message templates then apply as for built-in issues. Check may run
+	// concurrently with other rules and lints, and must not keep cfg.
+	Check(cfg *Config) []Issue
+}
+
+var registry struct {
+	mu    sync.RWMutex
+	rules []Rule
+}
+
+// Register adds r to every lint in the process, after the rules registered
+// before it. It panics if r has no ID or its ID is already registered, so
+// a clash is caught when the program starts.
+func Register(r Rule) {
+	id := r.ID()
+	if id == "" {
+		panic("linter: Register of a rule without an ID")
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for _, existing := range registry.rules {
+		if existing.ID() == id {
+			panic(fmt.Sprintf("linter: Register called twice for rule %s", id))
+		}
+	}
+	registry.rules = append(registry.rules, r)
+}
+
+// RegisteredRules lists the registered rules in registration order.
+func RegisteredRules() []Rule {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return append([]Rule(nil), registry.rules...)
+}
+
+// registeredRules wraps the registered rules opts does not turn off to run
+// with the built-in ones; each is timed under its ID.
+func registeredRules(opts Options) []namedRule {
+	var rules []namedRule
+	for _, r := range RegisteredRules() {
+		r := r
+		if !opts.reportsRule(r.ID()) {
+			continue
+		}
+		rules = append(rules, namedRule{r.ID(), func(cfg parsedConfig, _ Options, issues *[]Issue) {
+			for _, issue := range r.Check(exportConfig(cfg)) {
+				if issue.RuleID == "" {
+					issue.RuleID = r.ID()
+				}
+				if issue.Severity == "" {
+					issue.Severity = r.Severity()
+				}
+				*issues = append(*issues, issue)
+			}
+		}})
+	}
+	return rules
+}
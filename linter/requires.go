@@ -0,0 +1,99 @@
+package linter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// validateFeatureRequires checks `requires: [other-feature]` on feature
+// entries: every dependency must exist (FEAT004), be enabled whenever the
+// dependent is (FEAT005), and dependencies must not form a cycle (FEAT006).
+// It needs every feature at once, so it is not split into chunks.
+func validateFeatureRequires(cfg parsedConfig, _ Options, issues *[]Issue) {
+	byName := make(map[string]featureEntry, len(cfg.Features))
+	for _, f := range cfg.Features {
+		if name := f.Fields["name"].Value; name != "" {
+			if _, dup := byName[name]; !dup {
+				byName[name] = f
+			}
+		}
+	}
+
+	deps := make(map[string][]string)
+	var order []string
+	for _, f := range cfg.Features {
+		name := f.Fields["name"].Value
+		requires, ok := f.Fields["requires"]
+		if !ok || name == "" || byName[name].Line != f.Line {
+			continue
+		}
+		order = append(order, name)
+		for _, dep := range flowList(requires.Value, requires.Line, requires.Col) {
+			target, exists := byName[dep.Value]
+			switch {
+			case !exists:
+				*issues = append(*issues, Issue{
+					Line:         requires.Line,
+					Severity:     SeverityError,
+					RuleID:       "FEAT004",
+					Message:      fmt.Sprintf("feature %q requires unknown feature %q", name, dep.Value),
+					SuggestedFix: fmt.Sprintf("Add a feature named %q or fix the name", dep.Value),
+				})
+				continue
+			case isEnabled(f) && !isEnabled(target):
+				*issues = append(*issues, Issue{
+					Line:         requires.Line,
+					Severity:     SeverityError,
+					RuleID:       "FEAT005",
+					Message:      fmt.Sprintf("feature %q is enabled but requires %q, which is not", name, dep.Value),
+					SuggestedFix: fmt.Sprintf("Enable %q or disable %q", dep.Value, name),
+				})
+			}
+			deps[name] = append(deps[name], dep.Value)
+		}
+	}
+
+	// Depth-first search; a dependency already on the stack closes a cycle.
+	// Each cycle is reported once, on the feature it was entered from.
+	const (
+		unvisited = iota
+		onStack
+		done
+	)
+	state := make(map[string]int)
+	var stack []string
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = onStack
+		stack = append(stack, name)
+		for _, dep := range deps[name] {
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case onStack:
+				start := len(stack) - 1
+				for stack[start] != dep {
+					start--
+				}
+				cycle := append(append([]string{}, stack[start:]...), dep)
+				*issues = append(*issues, Issue{
+					Line:     byName[dep].Fields["requires"].Line,
+					Severity: SeverityError,
+					RuleID:   "FEAT006",
+					Message:  "feature dependency cycle: " + strings.Join(cycle, " -> "),
+				})
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = done
+	}
+	for _, name := range order {
+		if state[name] == unvisited {
+			visit(name)
+		}
+	}
+}
+
+func isEnabled(f featureEntry) bool {
+	return strings.EqualFold(strings.TrimSpace(f.Fields["enabled"].Value), "true")
+}
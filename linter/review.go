@@ -0,0 +1,55 @@
+package linter
+
+import (
+	"fmt"
+	"time"
+)
+
+// reviewedDate parses metadata.lastReviewed, written as a date or as an
+// RFC 3339 timestamp.
+func reviewedDate(value string) (time.Time, bool) {
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// validateReview reports configs whose metadata.lastReviewed is older than
+// Options.ReviewWindowDays (REV001) and review dates that cannot be read or
+// lie in the future (REV002). Configs without the field are not checked.
+func validateReview(cfg parsedConfig, opts Options, issues *[]Issue) {
+	reviewed, ok := cfg.Metadata["lastReviewed"]
+	if !ok || reviewed.Value == "" {
+		return
+	}
+	today, _ := time.Parse(time.DateOnly, now().Format(time.DateOnly))
+	t, ok := reviewedDate(reviewed.Value)
+	if !ok || t.After(today.AddDate(0, 0, 1)) {
+		msg := fmt.Sprintf("metadata.lastReviewed %q is not a date", reviewed.Value)
+		if ok {
+			msg = fmt.Sprintf("metadata.lastReviewed %q is in the future", reviewed.Value)
+		}
+		*issues = append(*issues, Issue{
+			Line:         reviewed.Line,
+			Column:       reviewed.Col,
+			Severity:     SeverityWarning,
+			RuleID:       "REV002",
+			Message:      msg,
+			SuggestedFix: "Write the date of the last review as YYYY-MM-DD",
+		})
+		return
+	}
+	days := int(today.Sub(t).Hours() / 24)
+	if days > opts.ReviewWindowDays {
+		*issues = append(*issues, Issue{
+			Line:         reviewed.Line,
+			Column:       reviewed.Col,
+			Severity:     SeverityWarning,
+			RuleID:       "REV001",
+			Message:      fmt.Sprintf("config was last reviewed %d days ago, more than the %d-day review window", days, opts.ReviewWindowDays),
+			SuggestedFix: "Review the config and update metadata.lastReviewed",
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// rolloutApprovalKey is the metadata annotation that approves prod rollouts
+// above Options.MaxProdRollout.
+const rolloutApprovalKey = "rollout-approved-by"
+
+var rolloutPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?%?$`)
+
+// parseRollout reads a rollout percentage, written 25 or "25%".
+func parseRollout(value string) (float64, bool) {
+	value = strings.TrimSpace(value)
+	if !rolloutPattern.MatchString(value) {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	return p, err == nil && p <= 100
+}
+
+// validateRollout checks the optional rollout percentage of features: it
+// must be 0 to 100 (FEAT007), a disabled feature should not roll out
+// (FEAT008), and in prod it may not exceed Options.MaxProdRollout unless
+// metadata.annotations approves it (FEAT009). It reads metadata, so it is
+// not split into chunks.
+func validateRollout(cfg parsedConfig, opts Options, issues *[]Issue) {
+	approved := cfg.MetadataMaps["annotations"][rolloutApprovalKey].Value != ""
+	capped := opts.MaxProdRollout != nil && cfg.Metadata["env"].Value == "prod" && !approved
+	for i, f := range cfg.Features {
+		rollout, ok := f.Fields["rollout"]
+		if !ok {
+			continue
+		}
+		name := fmt.Sprintf("features[%d]", i)
+		if n := f.Fields["name"].Value; n != "" {
+			name = strconv.Quote(n)
+		}
+		p, valid := parseRollout(rollout.Value)
+		switch {
+		case !valid:
+			*issues = append(*issues, Issue{
+				Line:         rollout.Line,
+				Column:       rollout.Col,
+				Severity:     SeverityError,
+				RuleID:       "FEAT007",
+				Message:      fmt.Sprintf("rollout of feature %s must be a percentage from 0 to 100, got %q", name, rollout.Value),
+				SuggestedFix: "Write it as a number from 0 to 100, or as \"25%\"",
+			})
+		case p > 0 && strings.EqualFold(strings.TrimSpace(f.Fields["enabled"].Value), "false"):
+			*issues = append(*issues, Issue{
+				Line:         rollout.Line,
+				Column:       rollout.Col,
+				Severity:     SeverityWarning,
+				RuleID:       "FEAT008",
+				Message:      fmt.Sprintf("feature %s is disabled but has a rollout of %g%%", name, p),
+				SuggestedFix: "Set rollout to 0 or enable the feature",
+			})
+		case capped && p > float64(*opts.MaxProdRollout):
+			*issues = append(*issues, Issue{
+				Line:         rollout.Line,
+				Column:       rollout.Col,
+				Severity:     SeverityError,
+				RuleID:       "FEAT009",
+				Message:      fmt.Sprintf("feature %s rolls out to %g%% in prod, above the cap of %d%%", name, p, *opts.MaxProdRollout),
+				SuggestedFix: fmt.Sprintf("Lower the rollout to %d%% or record the approval in metadata.annotations.%s", *opts.MaxProdRollout, rolloutApprovalKey),
+			})
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package linter
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// rule is one independent check over a parsed document. Rules only read the
+// document and append to their own issue slice, so they can run in parallel.
+type rule func(cfg parsedConfig, opts Options, issues *[]Issue)
+
+// namedRule is a rule with the name its timings are reported under.
+type namedRule struct {
+	name string
+	run  rule
+}
+
+// featureChunk is how many feature entries a single rule validates; configs
+// with more features are split so the chunks can run concurrently.
+const featureChunk = 2048
+
+func rulesFor(cfg parsedConfig, opts Options) []namedRule {
+	var rules []namedRule
+	p, routed := routeProfile(cfg, opts)
+	if routed && !p.additive {
+		rules = append(rules, namedRule{"profile:" + p.name, p.validate})
+	} else {
+		rules = append(rules, namedRule{"metadata", validateMetadata}, namedRule{"settings", validateSettings})
+		for lo := 0; lo < len(cfg.Features); lo += featureChunk {
+			hi := min(lo+featureChunk, len(cfg.Features))
+			rules = append(rules, namedRule{"features", func(cfg parsedConfig, _ Options, issues *[]Issue) {
+				cfg.Features = cfg.Features[lo:hi]
+				validateFeatures(cfg, issues)
+			}})
+		}
+		rules = append(rules, namedRule{"requires", validateFeatureRequires}, namedRule{"rollout", validateRollout}, namedRule{"locale", validateLocaleValues})
+		if len(opts.Defaults) > 0 {
+			rules = append(rules, namedRule{"defaults", validateDefaults})
+		}
+		if opts.ReviewWindowDays > 0 {
+			rules = append(rules, namedRule{"review", validateReview})
+		}
+		if routed {
+			rules = append(rules, namedRule{"profile:" + p.name, p.validate})
+		}
+		if opts.Governance.enabled() {
+			rules = append(rules, namedRule{"governance", validateGovernance})
+		}
+		if len(opts.TemplateVariables) > 0 {
+			rules = append(rules, namedRule{"templates", validateTemplates})
+		}
+		if len(opts.ConsumedKeys) > 0 {
+			rules = append(rules, namedRule{"consumedKeys", validateConsumedKeys})
+		}
+		if opts.StrictKeys {
+			rules = append(rules, namedRule{"strictKeys", validateUnknownKeys})
+		}
+		if opts.Style {
+			rules = append(rules, namedRule{"style", validateStyleSections}, namedRule{"style", validateStyleFeatures})
+		}
+	}
+	rules = append(rules, namedRule{"yamlTypes", validateYAMLTypes}, namedRule{"vault", func(cfg parsedConfig, _ Options, issues *[]Issue) {
+		validateVaultRefs(cfg, issues)
+	}})
+	if len(opts.Schema) > 0 {
+		rules = append(rules, namedRule{"schema", validateSchema})
+	}
+	if opts.CUESchema != "" {
+		rules = append(rules, namedRule{"cue", validateCUE})
+	}
+	rules = append(rules, sectionRules(opts)...)
+	rules = append(rules, patternRules(opts)...)
+	rules = append(rules, enumRules(opts)...)
+	rules = append(rules, expressionRules(opts)...)
+	return append(rules, registeredRules(opts)...)
+}
+
+// runRules runs rules on a pool of at most opts.Concurrency workers and
+// concatenates their issues in rule order, so the result is the same as a
+// sequential run. Each rule is timed into opts.Stats when it is set. Rules
+// not yet started when ctx is done are skipped.
+func runRules(ctx context.Context, cfg parsedConfig, opts Options, rules []namedRule) []Issue {
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(rules))
+
+	results := make([][]Issue, len(rules))
+	if workers <= 1 {
+		for i, r := range rules {
+			if ctx.Err() != nil {
+				break
+			}
+			r.exec(cfg, opts, &results[i])
+		}
+	} else {
+		next := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range next {
+					if ctx.Err() == nil {
+						rules[i].exec(cfg, opts, &results[i])
+					}
+				}
+			}()
+		}
+		for i := range rules {
+			next <- i
+		}
+		close(next)
+		wg.Wait()
+	}
+
+	var issues []Issue
+	for _, r := range results {
+		issues = append(issues, r...)
+	}
+	return issues
+}
+
+// exec runs r into issues. A panicking rule, such as a registered one with
+// a bug, does not take the lint down: its issues are replaced with an
+// INT001 error naming it.
+func (r namedRule) exec(cfg parsedConfig, opts Options, issues *[]Issue) {
+	defer func() {
+		if err := recover(); err != nil {
+			*issues = []Issue{{Line: 1, Severity: SeverityError, RuleID: "INT001", Message: fmt.Sprintf("check %s panicked: %v", r.name, err)}}
+		}
+	}()
+	if opts.Stats == nil {
+		r.run(cfg, opts, issues)
+		return
+	}
+	start := time.Now()
+	r.run(cfg, opts, issues)
+	opts.Stats.add(r.name, time.Since(start), *issues)
+}
+
+// RuleStats collects how long each check takes and what it reports, across
+// every lint it is passed to through Options.Stats. It is safe for
+// concurrent use; the zero value is ready to use.
+type RuleStats struct {
+	mu     sync.Mutex
+	checks map[string]*RuleStat
+}
+
+// RuleStat is the cost and yield of one check. Issues counts what the check
+// reported by rule ID, before suppressions and baselines.
+type RuleStat struct {
+	Check    string         `json:"check"`
+	Runs     int            `json:"runs"`
+	Duration time.Duration  `json:"durationNs"`
+	Issues   map[string]int `json:"issues,omitempty"`
+}
+
+func (s *RuleStats) add(check string, d time.Duration, issues []Issue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.checks == nil {
+		s.checks = make(map[string]*RuleStat)
+	}
+	st := s.checks[check]
+	if st == nil {
+		st = &RuleStat{Check: check}
+		s.checks[check] = st
+	}
+	st.Runs++
+	st.Duration += d
+	for _, issue := range issues {
+		if st.Issues == nil {
+			st.Issues = make(map[string]int)
+		}
+		st.Issues[issue.RuleID]++
+	}
+}
+
+// Snapshot returns the stats collected so far, slowest check first.
+func (s *RuleStats) Snapshot() []RuleStat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]RuleStat, 0, len(s.checks))
+	for _, st := range s.checks {
+		c := *st
+		if st.Issues != nil {
+			c.Issues = make(map[string]int, len(st.Issues))
+			for id, n := range st.Issues {
+				c.Issues[id] = n
+			}
+		}
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Duration != out[j].Duration {
+			return out[i].Duration > out[j].Duration
+		}
+		return out[i].Check < out[j].Check
+	})
+	return out
+}
@@ -0,0 +1,84 @@
+package linter
+
+// JSONSchema describes the constraints enforced by the built-in checks, the
+// declared Sections, Enums and Patterns as a JSON Schema (draft 2020-12)
+// document, so editors with YAML schema support can offer completion and
+// inline validation.
+func JSONSchema(opts Options) map[string]any {
+	schema := map[string]any{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"title":       "Sentinel config",
+		"description": "Generated from the active configlint rules.",
+		"type":        "object",
+		"required":    []string{"metadata", "settings"},
+		"properties": map[string]any{
+			"metadata": metadataSchema(opts),
+			"settings": map[string]any{
+				"type":     "object",
+				"required": []string{"replicas"},
+				"properties": map[string]any{
+					"replicas": map[string]any{"type": "integer", "minimum": 1},
+					"timeout":  map[string]any{"type": "integer", "minimum": 1, "default": opts.defaultTimeout()},
+				},
+			},
+			"features": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type":     "object",
+					"required": []string{"name", "enabled"},
+					"properties": map[string]any{
+						"name":     map[string]any{"type": "string", "minLength": 1},
+						"enabled":  map[string]any{"type": "boolean"},
+						"requires": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+						"rollout": map[string]any{"oneOf": []any{
+							map[string]any{"type": "number", "minimum": 0, "maximum": 100},
+							map[string]any{"type": "string", "pattern": `^[0-9]+(\.[0-9]+)?%$`},
+						}},
+					},
+				},
+			},
+		},
+	}
+	if len(opts.Sections) > 0 {
+		addSectionSchemas(schema, opts.Sections)
+	}
+	if len(opts.Enums) > 0 {
+		addEnums(schema, opts.Enums)
+	}
+	if len(opts.Patterns) > 0 {
+		addPatterns(schema, opts.Patterns)
+	}
+	return schema
+}
+
+func metadataSchema(opts Options) map[string]any {
+	required := []string{"name", "env"}
+	properties := map[string]any{
+		"name": map[string]any{"type": "string", "minLength": 1},
+		"env":  map[string]any{"type": "string", "enum": opts.environments()},
+	}
+
+	gov := opts.Governance.effective()
+	if gov.RequireOwner {
+		required = append(required, "owner")
+		owner := map[string]any{"type": "string", "minLength": 1}
+		if gov.OwnerPattern != "" {
+			owner["pattern"] = gov.OwnerPattern
+		}
+		properties["owner"] = owner
+	}
+	if gov.RequireRepository {
+		required = append(required, "repository")
+		properties["repository"] = map[string]any{"type": "string", "format": "uri", "pattern": "^https?://"}
+	}
+	if len(gov.RequiredLabels) > 0 {
+		required = append(required, "labels")
+		properties["labels"] = map[string]any{"type": "object", "required": gov.RequiredLabels}
+	}
+
+	return map[string]any{
+		"type":       "object",
+		"required":   required,
+		"properties": properties,
+	}
+}
@@ -0,0 +1,43 @@
+package linter
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	secretKeyPattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|api[_-]?key|private[_-]?key|access[_-]?key|credentials?)`)
+
+	// secretValuePatterns match well-known credential formats regardless of
+	// the key they are stored under.
+	secretValuePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`AKIA[0-9A-Z]{16}`),
+		regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+		regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{36,}`),
+		regexp.MustCompile(`xox[abpr]-[A-Za-z0-9-]{10,}`),
+	}
+)
+
+// looksLikeSecret reports whether a literal value stored under key is
+// probably a plaintext credential. References to secret stores and obvious
+// placeholders are not flagged.
+func looksLikeSecret(key, value string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.HasPrefix(value, vaultPrefix) || isPlaceholder(value) {
+		return false
+	}
+	for _, p := range secretValuePatterns {
+		if p.MatchString(value) {
+			return true
+		}
+	}
+	return secretKeyPattern.MatchString(key)
+}
+
+func isPlaceholder(value string) bool {
+	switch strings.ToLower(value) {
+	case "changeme", "change-me", "todo", "xxx", "<redacted>", "redacted", "null", "none":
+		return true
+	}
+	return strings.HasPrefix(value, "${") || strings.HasPrefix(value, "{{") || strings.HasPrefix(value, "var.")
+}
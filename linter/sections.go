@@ -0,0 +1,277 @@
+package linter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SectionSchema declares the keys of one top-level section, for configs
+// whose sections the built-in rules do not know. A file of them, by section
+// name, reads:
+//
+//	database:
+//	  required: true
+//	  fields:
+//	    host: {type: string, required: true}
+//	    port: {type: integer, min: 1, max: 65535}
+//	    sslmode: {enum: [disable, require, verify-full]}
+//
+// The metadata and settings sections can be declared too, adding to the
+// built-in checks, and a features schema applies to each feature entry.
+type SectionSchema struct {
+	// Required reports the section missing (SEC001).
+	Required bool                   `json:"required,omitempty"`
+	Fields   map[string]FieldSchema `json:"fields,omitempty"`
+}
+
+// FieldSchema constrains one key of a section.
+type FieldSchema struct {
+	// Required reports the key missing (SEC002).
+	Required bool `json:"required,omitempty"`
+	// Type is string, integer, number, boolean or list, read as YAML reads
+	// unquoted values (SEC003).
+	Type string `json:"type,omitempty"`
+	// Min and Max bound numbers (SEC004).
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+	// Enum lists the values the key may have (SEC005).
+	Enum []string `json:"enum,omitempty"`
+}
+
+var fieldTypes = []string{"string", "integer", "number", "boolean", "list"}
+
+// SectionSchemasFrom reads section schemas written as YAML or JSON.
+// Unknown keys are rejected, so a misspelled constraint is not ignored.
+func SectionSchemasFrom(data []byte) (map[string]SectionSchema, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(normalizeValue(doc))
+	if err != nil {
+		return nil, err
+	}
+	var sections map[string]SectionSchema
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&sections); err != nil {
+		return nil, err
+	}
+	return sections, nil
+}
+
+func (s SectionSchema) validate(name string) error {
+	for key, f := range s.Fields {
+		if f.Type != "" && !contains(fieldTypes, f.Type) {
+			return fmt.Errorf("section %s: field %s: unknown type %q (use %s)", name, key, f.Type, strings.Join(fieldTypes, ", "))
+		}
+		if (f.Min != nil || f.Max != nil) && f.Type != "" && f.Type != "integer" && f.Type != "number" {
+			return fmt.Errorf("section %s: field %s: min and max need type integer or number", name, key)
+		}
+		if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+			return fmt.Errorf("section %s: field %s: min is greater than max", name, key)
+		}
+	}
+	return nil
+}
+
+// sectionRules turns the section schemas into rules, one per section, in
+// name order.
+func sectionRules(opts Options) []namedRule {
+	names := sortedKeys(opts.Sections)
+	rules := make([]namedRule, 0, len(names))
+	for _, name := range names {
+		name, schema := name, opts.Sections[name]
+		rules = append(rules, namedRule{"section:" + name, func(cfg parsedConfig, _ Options, issues *[]Issue) {
+			schema.check(name, cfg, issues)
+		}})
+	}
+	return rules
+}
+
+func (s SectionSchema) check(name string, cfg parsedConfig, issues *[]Issue) {
+	var fields map[string]fieldInfo
+	line := 0
+	switch name {
+	case "metadata":
+		fields, line = cfg.Metadata, cfg.MetadataLine
+	case "settings":
+		fields, line = cfg.Settings, cfg.SettingsLine
+	case "features":
+		if cfg.FeaturesLine == 0 && len(cfg.Features) == 0 {
+			s.missing(name, issues)
+			return
+		}
+		for i, f := range cfg.Features {
+			s.checkFields("features["+strconv.Itoa(i)+"]", f.Fields, f.Line, issues)
+		}
+		return
+	default:
+		section, ok := cfg.Sections[name]
+		fields, line = section.Fields, section.Line
+		if !ok {
+			line = 0
+		}
+	}
+	if line == 0 && len(fields) == 0 {
+		s.missing(name, issues)
+		return
+	}
+	s.checkFields(name, fields, line, issues)
+}
+
+func (s SectionSchema) missing(name string, issues *[]Issue) {
+	if s.Required {
+		*issues = append(*issues, Issue{Line: 1, Severity: SeverityError, RuleID: "SEC001", Message: fmt.Sprintf("missing %s section", name)})
+	}
+}
+
+// checkFields checks the keys of one section, or feature entry, at path
+// against s. line is where missing keys are reported.
+func (s SectionSchema) checkFields(path string, fields map[string]fieldInfo, line int, issues *[]Issue) {
+	for _, key := range sortedKeys(s.Fields) {
+		f, keyPath := s.Fields[key], path+"."+key
+		info, ok := fields[key]
+		if !ok {
+			if f.Required {
+				*issues = append(*issues, Issue{Line: max(line, 1), Severity: SeverityError, RuleID: "SEC002", Message: keyPath + " is required"})
+			}
+			continue
+		}
+		report := func(id, msg string) {
+			*issues = append(*issues, Issue{Line: info.Line, Column: info.Col, Severity: SeverityError, RuleID: id, Message: msg})
+		}
+		value := scalarValue(info)
+		if f.Type != "" && fieldType(info, value) != f.Type && !(f.Type == "number" && fieldType(info, value) == "integer") {
+			report("SEC003", fmt.Sprintf("%s must be %s %s", keyPath, article(f.Type), f.Type))
+			continue
+		}
+		if n, ok := value.(float64); ok {
+			switch {
+			case f.Min != nil && f.Max != nil && (n < *f.Min || n > *f.Max):
+				report("SEC004", fmt.Sprintf("%s must be from %v to %v", keyPath, *f.Min, *f.Max))
+			case f.Min != nil && n < *f.Min:
+				report("SEC004", fmt.Sprintf("%s must be at least %v", keyPath, *f.Min))
+			case f.Max != nil && n > *f.Max:
+				report("SEC004", fmt.Sprintf("%s must be at most %v", keyPath, *f.Max))
+			}
+		}
+		if len(f.Enum) > 0 && !contains(f.Enum, info.Value) {
+			report("SEC005", fmt.Sprintf("%s value %q is not one of %s", keyPath, info.Value, strings.Join(f.Enum, ", ")))
+		}
+	}
+}
+
+// fieldType is the FieldSchema type of a value typed by scalarValue.
+func fieldType(info fieldInfo, value any) string {
+	switch v := value.(type) {
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) && !strings.ContainsAny(info.Value, ".eE") {
+			return "integer"
+		}
+		return "number"
+	case string:
+		if !info.Quoted && strings.HasPrefix(v, "[") && strings.HasSuffix(v, "]") {
+			return "list"
+		}
+	case nil:
+		return "null"
+	}
+	return "string"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func article(word string) string {
+	if strings.ContainsRune("aeiou", rune(word[0])) {
+		return "an"
+	}
+	return "a"
+}
+
+// addSectionSchemas adds the declared sections to a schema built by
+// JSONSchema, so editors, completion and StrictKeys know their keys.
+func addSectionSchemas(schema map[string]any, sections map[string]SectionSchema) {
+	for _, name := range sortedKeys(sections) {
+		s := sections[name]
+		section := schemaSection(schema, name)
+		required, _ := section["required"].([]string)
+		for _, key := range sortedKeys(s.Fields) {
+			f := s.Fields[key]
+			prop := schemaProperty(section, key)
+			switch f.Type {
+			case "":
+			case "list":
+				prop["type"] = "array"
+			default:
+				prop["type"] = f.Type
+			}
+			if f.Min != nil {
+				prop["minimum"] = *f.Min
+			}
+			if f.Max != nil {
+				prop["maximum"] = *f.Max
+			}
+			if len(f.Enum) > 0 {
+				prop["enum"] = f.Enum
+			}
+			if f.Required && !contains(required, key) {
+				required = append(required, key)
+			}
+		}
+		if len(required) > 0 {
+			section["required"] = required
+		}
+		if s.Required {
+			if root, _ := schema["required"].([]string); !contains(root, name) {
+				schema["required"] = append(root, name)
+			}
+		}
+	}
+}
+
+// schemaSection returns the object schema of the named top-level section,
+// adding one if the schema has none; for features it is a feature entry's.
+func schemaSection(schema map[string]any, name string) map[string]any {
+	properties := schema["properties"].(map[string]any)
+	section, _ := properties[name].(map[string]any)
+	if section == nil {
+		section = map[string]any{"type": "object"}
+		properties[name] = section
+	}
+	if name == "features" {
+		section = schemaItems(section)
+	}
+	return section
+}
+
+// schemaProperty returns the schema of key in an object schema, adding an
+// empty one if it has none.
+func schemaProperty(object map[string]any, key string) map[string]any {
+	props, _ := object["properties"].(map[string]any)
+	if props == nil {
+		props = make(map[string]any)
+		object["properties"] = props
+	}
+	prop, _ := props[key].(map[string]any)
+	if prop == nil {
+		prop = make(map[string]any)
+		props[key] = prop
+	}
+	return prop
+}
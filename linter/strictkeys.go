@@ -0,0 +1,171 @@
+package linter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// extraKeys are keys other rules read that the JSON Schema does not list.
+var extraKeys = []string{
+	"apiVersion", "kind", "include", "extends",
+	"metadata.owner", "metadata.repository", "metadata.labels", "metadata.annotations", "metadata.lastReviewed",
+}
+
+// knownKeys returns the dotted paths StrictKeys accepts: the keys of the
+// JSON Schema for opts, extraKeys, and the keys of the defaults catalog and
+// the consumed-keys manifest. Manifest entries ending in ".*" are returned
+// as prefixes instead.
+func knownKeys(opts Options) (keys map[string]bool, prefixes []string) {
+	keys = make(map[string]bool)
+	var walk func(prefix string, node map[string]any)
+	walk = func(prefix string, node map[string]any) {
+		node = schemaItems(node)
+		properties, _ := node["properties"].(map[string]any)
+		for key, child := range properties {
+			keys[prefix+key] = true
+			if child, ok := child.(map[string]any); ok {
+				walk(prefix+key+".", child)
+			}
+		}
+	}
+	walk("", JSONSchema(opts))
+	for _, key := range extraKeys {
+		keys[key] = true
+	}
+	for key := range opts.Defaults {
+		keys[key] = true
+	}
+	for _, pattern := range opts.ConsumedKeys {
+		if prefix, ok := strings.CutSuffix(pattern, ".*"); ok {
+			prefixes = append(prefixes, prefix+".")
+			continue
+		}
+		keys[pattern] = true
+	}
+	return keys, prefixes
+}
+
+// validateUnknownKeys reports keys outside the known schema (KEY003), with
+// the nearest known key as the suggested fix when one is close enough to be
+// a typo. Keys inside nested metadata mappings (labels) are free-form and
+// not checked; unknown top-level sections are reported once, by name.
+func validateUnknownKeys(cfg parsedConfig, opts Options, issues *[]Issue) {
+	keys, prefixes := knownKeys(opts)
+	type found struct {
+		path string
+		// at is the issue path, which numbers feature entries.
+		at   string
+		info fieldInfo
+	}
+	var unknown []found
+	checkAt := func(path, at string, info fieldInfo) {
+		if keys[path] {
+			return
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(path+".", prefix) {
+				return
+			}
+		}
+		unknown = append(unknown, found{path, at, info})
+	}
+	check := func(path string, info fieldInfo) { checkAt(path, path, info) }
+
+	nested := make(map[int]bool)
+	for _, fields := range cfg.MetadataMaps {
+		for _, info := range fields {
+			nested[info.Line] = true
+		}
+	}
+	for key, info := range cfg.Metadata {
+		if _, isMap := cfg.MetadataMaps[key]; !isMap && !nested[info.Line] {
+			check("metadata."+key, info)
+		}
+	}
+	for name := range cfg.MetadataMaps {
+		check("metadata."+name, cfg.Metadata[name])
+	}
+	for key, info := range cfg.Settings {
+		check("settings."+key, info)
+	}
+	for key, info := range cfg.TopLevel {
+		check(key, info)
+	}
+	for name, section := range cfg.Sections {
+		check(name, fieldInfo{Line: section.Line})
+	}
+	for i, feature := range cfg.Features {
+		for key, info := range feature.Fields {
+			checkAt("features."+key, fmt.Sprintf("features[%d].%s", i, key), info)
+		}
+	}
+
+	sort.Slice(unknown, func(i, j int) bool {
+		a, b := unknown[i], unknown[j]
+		if a.info.Line != b.info.Line {
+			return a.info.Line < b.info.Line
+		}
+		return a.at < b.at
+	})
+	for _, u := range unknown {
+		issue := Issue{
+			Line:         u.info.Line,
+			Column:       u.info.Col,
+			Severity:     SeverityWarning,
+			RuleID:       "KEY003",
+			Message:      fmt.Sprintf("%s is not a known key", u.path),
+			Path:         u.at,
+			SuggestedFix: "Remove the key, or add it to the defaults catalog or the consumed-keys manifest",
+		}
+		if near := nearestKey(u.path, keys); near != "" {
+			issue.Message += fmt.Sprintf("; did you mean %s?", near)
+			issue.SuggestedFix = "Rename it to " + near[strings.LastIndexByte(near, '.')+1:]
+		}
+		*issues = append(*issues, issue)
+	}
+}
+
+// nearestKey returns the known key with the same parent as path whose name
+// is closest to path's, if it is within a typo's distance: at most 2 edits,
+// and fewer than half the name.
+func nearestKey(path string, keys map[string]bool) string {
+	dot := strings.LastIndexByte(path, '.')
+	parent, name := path[:dot+1], path[dot+1:]
+	best, bestDist := "", 0
+	for key := range keys {
+		rest, ok := strings.CutPrefix(key, parent)
+		if !ok || strings.Contains(rest, ".") {
+			continue
+		}
+		d := editDistance(strings.ToLower(name), strings.ToLower(rest))
+		if d > 2 || 2*d >= len(name) {
+			continue
+		}
+		if best == "" || d < bestDist || d == bestDist && key < best {
+			best, bestDist = key, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b, in bytes.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
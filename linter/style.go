@@ -0,0 +1,104 @@
+package linter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sectionOrder is the canonical order of the built-in top-level sections.
+var sectionOrder = []string{"metadata", "settings", "features"}
+
+// validateStyleSections reports top-level sections out of canonical order and
+// values quoted where YAML would read them as the same string unquoted.
+func validateStyleSections(cfg parsedConfig, _ Options, issues *[]Issue) {
+	lines := map[string]int{"metadata": cfg.MetadataLine, "settings": cfg.SettingsLine, "features": cfg.FeaturesLine}
+	last, lastName := 0, ""
+	for _, name := range sectionOrder {
+		line := lines[name]
+		if line == 0 {
+			continue
+		}
+		if line < last {
+			*issues = append(*issues, Issue{
+				Line:         line,
+				Severity:     SeverityStyle,
+				RuleID:       "STY001",
+				Message:      fmt.Sprintf("%s should come after %s", name, lastName),
+				SuggestedFix: "Order sections as metadata, settings, features",
+			})
+			continue
+		}
+		last, lastName = line, name
+	}
+
+	for _, v := range append(fieldsByLine("metadata", cfg.Metadata), fieldsByLine("settings", cfg.Settings)...) {
+		styleQuoting(v, issues)
+	}
+}
+
+// validateStyleFeatures reports feature entries that do not start with name
+// and needlessly quoted feature values.
+func validateStyleFeatures(cfg parsedConfig, _ Options, issues *[]Issue) {
+	for _, feature := range cfg.Features {
+		fields := fieldsByLine("features", feature.Fields)
+		if name, ok := feature.Fields["name"]; ok && len(fields) > 0 && fields[0].value.Line < name.Line {
+			*issues = append(*issues, Issue{
+				Line:     name.Line,
+				Severity: SeverityStyle,
+				RuleID:   "STY002",
+				Message:  fmt.Sprintf("feature name should be the first key (found %s before it)", fields[0].field),
+			})
+		}
+		for _, v := range fields {
+			styleQuoting(v, issues)
+		}
+	}
+}
+
+func styleQuoting(v fieldValue, issues *[]Issue) {
+	if !v.value.Quoted || !plainScalarSafe(v.value.Value) {
+		return
+	}
+	*issues = append(*issues, Issue{
+		Line:         v.value.Line,
+		Column:       v.value.Col,
+		Severity:     SeverityStyle,
+		RuleID:       "STY003",
+		Message:      fmt.Sprintf("%s does not need quotes", v.field),
+		SuggestedFix: fmt.Sprintf("Write it as %s", v.value.Value),
+	})
+}
+
+// plainScalarSafe reports whether value reads back as the same string when
+// written without quotes.
+func plainScalarSafe(value string) bool {
+	if value == "" || value != strings.TrimSpace(value) || strings.ContainsAny(value, ":#\"'{}[],&*!|>%@`") {
+		return false
+	}
+	if strings.ContainsRune("-?", rune(value[0])) {
+		return false
+	}
+	switch strings.ToLower(value) {
+	case "true", "false", "null", "~":
+		return false
+	}
+	if ambiguousYAMLType(value) != "" {
+		return false
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return false
+	}
+	return true
+}
+
+// fieldsByLine returns the fields of one section in document order.
+func fieldsByLine(section string, fields map[string]fieldInfo) []fieldValue {
+	values := make([]fieldValue, 0, len(fields))
+	for key, info := range fields {
+		values = append(values, fieldValue{field: section + "." + key, value: info})
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].value.Line < values[j].value.Line })
+	return values
+}
@@ -0,0 +1,335 @@
+package linter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// suppressionMarkers start an inline suppression comment, written with a
+// hyphen or a colon after configlint:
+//
+//	replicas: 0 # configlint-disable-line SET002 until=2025-06-01
+//	# configlint:disable-next-line META002,META003
+//	# configlint:disable-file FEAT001
+//
+// disable-file covers every line of the file. Without rule IDs every rule
+// is suppressed. A suppression with an until date stops applying the day
+// after it and is reported as SUP001 instead, so exceptions cannot stay
+// silent forever.
+var suppressionMarkers = []string{"configlint-disable-", "configlint:disable-"}
+
+// now is replaced in tests.
+var now = time.Now
+
+type suppression struct {
+	Line    int // line whose issues are suppressed
+	Comment int // line the comment is on
+	Rules   []string
+	Until   string
+	File    bool // every line is suppressed
+}
+
+// cutSuppression splits a suppression comment off line, returning the text
+// before the comment and the directive after "configlint-" or "configlint:".
+func cutSuppression(line string) (rest, directive string, ok bool) {
+	m := -1
+	for _, marker := range suppressionMarkers {
+		if i := strings.Index(line, marker); i >= 0 && (m < 0 || i < m) {
+			m = i
+		}
+	}
+	if m < 0 {
+		return line, "", false
+	}
+	hash := strings.LastIndexByte(line[:m], '#')
+	if hash < 0 || strings.TrimSpace(line[hash+1:m]) != "" {
+		return line, "", false
+	}
+	return strings.TrimRight(line[:hash], " \t"), line[m+len("configlint-"):], true
+}
+
+func parseSuppression(directive string, lineNo int) (suppression, bool) {
+	fields := strings.Fields(directive)
+	s := suppression{Comment: lineNo}
+	switch fields[0] {
+	case "disable-line":
+		s.Line = lineNo
+	case "disable-next-line":
+		s.Line = lineNo + 1
+	case "disable-file":
+		s.Line, s.File = lineNo, true
+	default:
+		return s, false
+	}
+	for _, f := range fields[1:] {
+		if until, ok := strings.CutPrefix(f, "until="); ok {
+			s.Until = until
+			continue
+		}
+		for _, id := range strings.Split(f, ",") {
+			if id != "" {
+				s.Rules = append(s.Rules, id)
+			}
+		}
+	}
+	return s, true
+}
+
+// scanSuppressions collects the suppressions in data, for frontends that do
+// not go through parseStream.
+func scanSuppressions(data []byte) []suppression {
+	var sups []suppression
+	for i, line := range strings.Split(string(data), "\n") {
+		if _, directive, ok := cutSuppression(line); ok {
+			if s, ok := parseSuppression(directive, i+1); ok {
+				sups = append(sups, s)
+			}
+		}
+	}
+	return sups
+}
+
+// covers matches rules by their current ID, so suppressions written before
+// a rule was renamed keep working.
+func (s suppression) covers(issue Issue) bool {
+	if issue.Line != s.Line && !s.File {
+		return false
+	}
+	if len(s.Rules) == 0 {
+		return true
+	}
+	for _, id := range s.Rules {
+		if CurrentRuleID(id) == issue.RuleID {
+			return true
+		}
+	}
+	return false
+}
+
+// applySuppressions drops the issues covered by an active suppression, or
+// marks them Suppressed when keep is set (Options.ReportSuppressed), and
+// reports expired or malformed ones, and those naming renamed or removed
+// rules (SUP003).
+func applySuppressions(issues []Issue, sups []suppression, keep bool) []Issue {
+	if len(sups) == 0 {
+		return issues
+	}
+	today := now().Format(time.DateOnly)
+	var active []suppression
+	var stale []Issue
+	for _, s := range sups {
+		for _, id := range s.Rules {
+			if c, ok := RuleDeprecation(id); ok {
+				fix := "Remove " + id + " from the suppression"
+				if c.Replacement != "" {
+					fix = "Replace " + id + " with " + c.Replacement
+				}
+				stale = append(stale, Issue{
+					Line:         s.Comment,
+					Severity:     SeverityWarning,
+					RuleID:       "SUP003",
+					Message:      "suppression names a deprecated rule: " + c.Describe(),
+					SuggestedFix: fix,
+				})
+			}
+		}
+		if s.Until == "" {
+			active = append(active, s)
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, s.Until); err != nil {
+			stale = append(stale, Issue{
+				Line:         s.Comment,
+				Severity:     SeverityWarning,
+				RuleID:       "SUP002",
+				Message:      fmt.Sprintf("suppression has an invalid until date %q and is ignored", s.Until),
+				SuggestedFix: "Write the date as YYYY-MM-DD",
+			})
+			continue
+		}
+		if s.Until < today {
+			stale = append(stale, Issue{
+				Line:         s.Comment,
+				Severity:     SeverityWarning,
+				RuleID:       "SUP001",
+				Message:      fmt.Sprintf("suppression of %s expired on %s", suppressedRules(s), s.Until),
+				SuggestedFix: "Fix the suppressed issue or extend the until date",
+			})
+			continue
+		}
+		active = append(active, s)
+	}
+
+	kept := issues[:0:0]
+	for _, issue := range issues {
+		covered := false
+		for _, s := range active {
+			if s.covers(issue) {
+				covered = true
+				break
+			}
+		}
+		if covered && keep {
+			issue.Suppressed = true
+		}
+		if !covered || keep {
+			kept = append(kept, issue)
+		}
+	}
+	return append(kept, stale...)
+}
+
+// SplitSuppressed separates the issues a lint with Options.ReportSuppressed
+// kept for suppressions from those it reports.
+func SplitSuppressed(issues []Issue) (active, suppressed []Issue) {
+	active = issues[:0:0]
+	for _, issue := range issues {
+		if issue.Suppressed {
+			suppressed = append(suppressed, issue)
+		} else {
+			active = append(active, issue)
+		}
+	}
+	return active, suppressed
+}
+
+func suppressedRules(s suppression) string {
+	if len(s.Rules) == 0 {
+		return "all rules"
+	}
+	return strings.Join(s.Rules, ", ")
+}
+
+// Suppression is an inline suppression comment, for tools that manage them.
+type Suppression struct {
+	// Line is the line whose issues are suppressed; Comment the line the
+	// comment is on.
+	Line    int      `json:"line"`
+	Comment int      `json:"comment"`
+	Rules   []string `json:"rules,omitempty"`
+	Until   string   `json:"until,omitempty"`
+	// File is set for disable-file comments, which cover every line.
+	File bool `json:"file,omitempty"`
+}
+
+// Suppressions lists the inline suppression comments in data.
+func Suppressions(data []byte) []Suppression {
+	var out []Suppression
+	for _, s := range scanSuppressions(data) {
+		out = append(out, Suppression(s))
+	}
+	return out
+}
+
+// AddSuppressions suppresses issues with configlint-disable-line comments,
+// adding their rule IDs to a comment already on the line. It returns the
+// new text, the issues it suppressed and those it could not: issues
+// without a line, and every issue in JSON or XML, which have no # comments.
+func AddSuppressions(data []byte, issues []Issue, until string) (out []byte, added, skipped []Issue) {
+	lines := strings.Split(string(data), "\n")
+	byLine := make(map[int][]string)
+	for _, issue := range issues {
+		if issue.Line < 1 || issue.Line > len(lines) || issue.RuleID == "" || looksLikeJSON(data) || looksLikeXML(data) {
+			skipped = append(skipped, issue)
+			continue
+		}
+		if !contains(byLine[issue.Line], issue.RuleID) {
+			byLine[issue.Line] = append(byLine[issue.Line], issue.RuleID)
+		}
+		added = append(added, issue)
+	}
+	for n, ids := range byLine {
+		line := lines[n-1]
+		if s, ok := lineSuppression(line, n); ok && s.Line == n && !s.File {
+			if len(s.Rules) == 0 {
+				continue // already suppresses every rule
+			}
+			for _, id := range ids {
+				if !contains(s.Rules, id) {
+					s.Rules = append(s.Rules, id)
+				}
+			}
+			if until != "" {
+				s.Until = until
+			}
+			lines[n-1] = withComment(line, s)
+			continue
+		}
+		lines[n-1] = strings.TrimRight(line, " \t") + " " + suppressionComment(suppression{Line: n, Comment: n, Rules: ids, Until: until})
+	}
+	return []byte(strings.Join(lines, "\n")), added, skipped
+}
+
+// RemoveSuppressions takes rules out of the inline suppressions in data, or
+// drops the comments altogether when rules is empty. A comment left without
+// rule IDs is removed, along with its line if nothing else is on it; one
+// that never named rules is only removed when rules is empty. It returns
+// the new text and the suppressions changed, each with the rules removed.
+func RemoveSuppressions(data []byte, rules []string) (out []byte, removed []Suppression) {
+	lines := strings.Split(string(data), "\n")
+	kept := lines[:0]
+	for i, line := range lines {
+		s, ok := lineSuppression(line, i+1)
+		if !ok {
+			kept = append(kept, line)
+			continue
+		}
+		var drop, left []string
+		for _, id := range s.Rules {
+			if len(rules) == 0 || contains(rules, id) || contains(rules, CurrentRuleID(id)) {
+				drop = append(drop, id)
+			} else {
+				left = append(left, id)
+			}
+		}
+		if len(rules) > 0 && len(drop) == 0 {
+			kept = append(kept, line)
+			continue
+		}
+		removed = append(removed, Suppression{Line: s.Line, Comment: s.Comment, Rules: drop, Until: s.Until, File: s.File})
+		switch rest, _, _ := cutSuppression(line); {
+		case len(left) > 0:
+			s.Rules = left
+			kept = append(kept, withComment(line, s))
+		case strings.TrimSpace(rest) != "":
+			kept = append(kept, rest)
+		}
+	}
+	return []byte(strings.Join(kept, "\n")), removed
+}
+
+func lineSuppression(line string, lineNo int) (suppression, bool) {
+	_, directive, ok := cutSuppression(line)
+	if !ok {
+		return suppression{}, false
+	}
+	return parseSuppression(directive, lineNo)
+}
+
+// withComment replaces the suppression comment on line with s.
+func withComment(line string, s suppression) string {
+	rest, _, _ := cutSuppression(line)
+	if strings.TrimSpace(rest) == "" {
+		return line[:len(line)-len(strings.TrimLeft(line, " \t"))] + suppressionComment(s)
+	}
+	return rest + " " + suppressionComment(s)
+}
+
+// suppressionComment writes s back as a comment.
+func suppressionComment(s suppression) string {
+	comment := "# configlint-disable-line"
+	switch {
+	case s.File:
+		comment = "# configlint-disable-file"
+	case s.Line != s.Comment:
+		comment = "# configlint-disable-next-line"
+	}
+	if len(s.Rules) > 0 {
+		comment += " " + strings.Join(s.Rules, ",")
+	}
+	if s.Until != "" {
+		comment += " until=" + s.Until
+	}
+	return comment
+}
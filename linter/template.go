@@ -0,0 +1,247 @@
+package linter
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxInterpolationDepth bounds placeholder nesting, both written out
+// (${a_${b}}) and through variable values that reference other variables.
+const maxInterpolationDepth = 8
+
+// maxTemplateExpansion bounds the length a placeholder may expand to
+// through variable values, which nesting makes exponential in their number.
+const maxTemplateExpansion = 1 << 20
+
+// placeholder is one ${name} or {{name}} found in a value.
+type placeholder struct {
+	Name  string
+	Depth int
+	// Dynamic is set when the name itself contains a placeholder and so
+	// cannot be checked.
+	Dynamic bool
+}
+
+// scanPlaceholders returns the placeholders in s, innermost first, and
+// whether every one of them is closed.
+func scanPlaceholders(s string) ([]placeholder, bool) {
+	type open struct {
+		start  int
+		curly  bool // {{ }} rather than ${ }
+		nested bool
+	}
+	var stack []open
+	var found []placeholder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "${"):
+			stack = append(stack, open{start: i + 2})
+			i++
+		case strings.HasPrefix(s[i:], "{{"):
+			stack = append(stack, open{start: i + 2, curly: true})
+			i++
+		case len(stack) > 0 && s[i] == '}':
+			top := stack[len(stack)-1]
+			if top.curly && !strings.HasPrefix(s[i:], "}}") {
+				continue
+			}
+			name := strings.TrimSpace(s[top.start:i])
+			if top.curly {
+				name = strings.TrimPrefix(name, ".")
+				i++
+			}
+			stack = stack[:len(stack)-1]
+			found = append(found, placeholder{Name: name, Depth: len(stack) + 1, Dynamic: top.nested})
+			if len(stack) > 0 {
+				stack[len(stack)-1].nested = true
+			}
+		}
+	}
+	return found, len(stack) == 0
+}
+
+// TemplateVariablesFrom reads declared template variables from a JSON
+// object, KEY=VALUE lines (.env style) or `key: value` lines.
+func TemplateVariablesFrom(data []byte) (map[string]string, error) {
+	if looksLikeJSON(data) {
+		var raw map[string]any
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+		vars := make(map[string]string, len(raw))
+		for k, v := range raw {
+			if s, ok := v.(string); ok {
+				vars[k] = s
+			} else {
+				vars[k] = fmt.Sprint(v)
+			}
+		}
+		return vars, nil
+	}
+
+	vars := make(map[string]string)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.Contains(key, ":") {
+			key, value, ok = strings.Cut(line, ":")
+		}
+		key = strings.TrimSpace(strings.TrimPrefix(key, "export "))
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE or key: value", i+1)
+		}
+		vars[key] = trimQuotes(strings.TrimSpace(value))
+	}
+	return vars, nil
+}
+
+// configValues returns every scalar value in the config, ordered by line.
+func configValues(cfg parsedConfig) []fieldValue {
+	var values []fieldValue
+	add := func(prefix string, fields map[string]fieldInfo) {
+		for key, info := range fields {
+			values = append(values, fieldValue{field: prefix + key, value: info})
+		}
+	}
+	add("", cfg.TopLevel)
+	add("metadata.", cfg.Metadata)
+	add("settings.", cfg.Settings)
+	for name, section := range cfg.Sections {
+		add(name+".", section.Fields)
+	}
+	for _, feature := range cfg.Features {
+		add("features.", feature.Fields)
+	}
+	sortFieldValues(values)
+	return values
+}
+
+// sortFieldValues orders values by line, then key path.
+func sortFieldValues(values []fieldValue) {
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].value.Line != values[j].value.Line {
+			return values[i].value.Line < values[j].value.Line
+		}
+		return values[i].field < values[j].field
+	})
+}
+
+// validateTemplates checks placeholders against Options.TemplateVariables:
+// undefined variables (TPL001), nesting deeper than maxInterpolationDepth or
+// looping through variable values (TPL003), unclosed placeholders (TPL004),
+// and declared variables nothing uses (TPL002).
+func validateTemplates(cfg parsedConfig, opts Options, issues *[]Issue) {
+	vars := opts.TemplateVariables
+	used := make(map[string]bool)
+	e := templateExpander{vars: vars, used: used, checked: make(map[string]int), sizes: make(map[string]int)}
+
+	for _, v := range configValues(cfg) {
+		if !strings.Contains(v.value.Value, "${") && !strings.Contains(v.value.Value, "{{") {
+			continue
+		}
+		report := func(severity Severity, rule, message string) {
+			*issues = append(*issues, Issue{
+				Line:     v.value.Line,
+				Column:   v.value.Col,
+				Severity: severity,
+				RuleID:   rule,
+				Message:  message,
+			})
+		}
+
+		found, closed := scanPlaceholders(v.value.Value)
+		if !closed {
+			report(SeverityWarning, "TPL004", fmt.Sprintf("%s has an unclosed placeholder", v.field))
+		}
+		for _, p := range found {
+			switch {
+			case p.Depth > maxInterpolationDepth:
+				report(SeverityError, "TPL003", fmt.Sprintf("%s nests placeholders deeper than %d", v.field, maxInterpolationDepth))
+			case p.Dynamic:
+			default:
+				if _, msg := e.expand(p.Name, p.Depth, []string{p.Name}); msg != "" {
+					rule := "TPL003"
+					if strings.HasPrefix(msg, "undefined") {
+						rule = "TPL001"
+					}
+					report(SeverityError, rule, fmt.Sprintf("%s: %s", v.field, msg))
+				}
+			}
+		}
+	}
+
+	var unused []string
+	for name := range vars {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+	for _, name := range unused {
+		*issues = append(*issues, Issue{
+			Line:         1,
+			Severity:     SeverityWarning,
+			RuleID:       "TPL002",
+			Message:      fmt.Sprintf("template variable %q is declared but never used", name),
+			SuggestedFix: "Remove it from the variables file",
+		})
+	}
+}
+
+// templateExpander follows variable references for one check. Each
+// variable is expanded once for every depth it is reached at, at most, so
+// variables referenced from many places cost no more than one reference.
+type templateExpander struct {
+	vars map[string]string
+	used map[string]bool
+	// checked is the deepest level each variable expanded from without a
+	// problem, and sizes the length it expands to.
+	checked map[string]int
+	sizes   map[string]int
+}
+
+// expand marks name and every variable its value references as used,
+// returning the length name expands to or a description of the first
+// problem found. A variable that expanded cleanly from some depth does so
+// from any shallower one, whatever the chain: it reaches no loop.
+func (e *templateExpander) expand(name string, depth int, chain []string) (int, string) {
+	value, ok := e.vars[name]
+	if !ok {
+		if len(chain) > 1 {
+			return 0, fmt.Sprintf("undefined template variable %q (via %s)", name, strings.Join(chain[:len(chain)-1], " -> "))
+		}
+		return 0, fmt.Sprintf("undefined template variable %q", name)
+	}
+	e.used[name] = true
+	if checked, ok := e.checked[name]; ok && depth <= checked {
+		return e.sizes[name], ""
+	}
+
+	size := len(value)
+	found, _ := scanPlaceholders(value)
+	for _, p := range found {
+		if p.Dynamic {
+			continue
+		}
+		if contains(chain, p.Name) {
+			return 0, fmt.Sprintf("template variables loop: %s -> %s", strings.Join(chain, " -> "), p.Name)
+		}
+		if depth+p.Depth > maxInterpolationDepth {
+			return 0, fmt.Sprintf("%s expands deeper than %d levels", chain[0], maxInterpolationDepth)
+		}
+		n, msg := e.expand(p.Name, depth+p.Depth, append(chain[:len(chain):len(chain)], p.Name))
+		if msg != "" {
+			return 0, msg
+		}
+		if size += n; size > maxTemplateExpansion {
+			return 0, fmt.Sprintf("%s expands to more than %d bytes", chain[0], maxTemplateExpansion)
+		}
+	}
+	e.checked[name], e.sizes[name] = depth, size
+	return size, ""
+}
@@ -0,0 +1,282 @@
+package linter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	tfAssignPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_-]*)\s*=\s*(.*)$`)
+	tfNamePattern   = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	tfDeclPattern   = regexp.MustCompile(`(?m)^\s*variable\s+"([^"]+)"`)
+)
+
+type tfVar struct {
+	Name  string
+	Value string
+	// Literal is true when Value is a plain string, number or bool rather
+	// than a list, map or heredoc.
+	Literal bool
+	Line    int
+	Col     int
+}
+
+// LintTFVars lints Terraform variable files (.tfvars, or .tfvars.json when
+// isJSON is set): variable naming, values for undeclared variables (when
+// Options.TerraformVariables is set) and plaintext secrets.
+func LintTFVars(data []byte, isJSON bool, opts Options) ([]Issue, error) {
+	if err := checkText(data[:min(len(data), textSniff)]); err != nil {
+		return nil, err
+	}
+	var vars []tfVar
+	var err error
+	if isJSON {
+		vars, err = parseTFVarsJSON(data)
+	} else {
+		vars, err = parseTFVars(data, opts.Limits.Effective())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	seen := make(map[string]int)
+	for _, v := range vars {
+		if first, dup := seen[v.Name]; dup {
+			issues = append(issues, Issue{
+				Line:     v.Line,
+				Column:   v.Col,
+				Severity: SeverityError,
+				RuleID:   "TF004",
+				Message:  fmt.Sprintf("variable %q is already assigned on line %d", v.Name, first),
+			})
+			continue
+		}
+		seen[v.Name] = v.Line
+
+		if !tfNamePattern.MatchString(v.Name) {
+			issues = append(issues, Issue{
+				Line:         v.Line,
+				Column:       v.Col,
+				Severity:     SeverityWarning,
+				RuleID:       "TF001",
+				Message:      fmt.Sprintf("variable %q should be snake_case", v.Name),
+				SuggestedFix: fmt.Sprintf("Rename to %s", toSnakeCase(v.Name)),
+			})
+		}
+
+		if len(opts.TerraformVariables) > 0 && !opts.declaresVariable(v.Name) {
+			issues = append(issues, Issue{
+				Line:         v.Line,
+				Column:       v.Col,
+				Severity:     SeverityWarning,
+				RuleID:       "TF002",
+				Message:      fmt.Sprintf("variable %q is not declared and will be ignored", v.Name),
+				SuggestedFix: "Remove the value or declare the variable",
+			})
+		}
+
+		if v.Literal && looksLikeSecret(v.Name, v.Value) {
+			issues = append(issues, Issue{
+				Line:         v.Line,
+				Column:       v.Col,
+				Severity:     SeverityError,
+				RuleID:       "TF003",
+				Message:      fmt.Sprintf("variable %q appears to contain a plaintext secret", v.Name),
+				SuggestedFix: "Pass secrets via TF_VAR_ environment variables or a secret store instead",
+			})
+		}
+	}
+	issues = applyRuleSettings(applySuppressions(issues, scanSuppressions(data), opts.ReportSuppressed), opts)
+	applyMessageTemplates(issues, newParsedConfig(), opts)
+	return issues, nil
+}
+
+// TerraformVariableNames extracts declared names from a variables.tf file,
+// or reads one name per line from a plain list.
+func TerraformVariableNames(data []byte) []string {
+	var names []string
+	if matches := tfDeclPattern.FindAllSubmatch(data, -1); len(matches) > 0 {
+		for _, m := range matches {
+			names = append(names, string(m[1]))
+		}
+		return names
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if name := strings.TrimSpace(line); name != "" && !strings.HasPrefix(name, "#") {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// parseTFVars reads the top-level assignments of an HCL tfvars file,
+// skipping over comments, multi-line collections and heredocs. Lines may be
+// as long as limits allow, so generated one-line lists and maps still parse.
+func parseTFVars(data []byte, limits Limits) ([]tfVar, error) {
+	var vars []tfVar
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, min(64*1024, limits.MaxLineBytes)), limits.MaxLineBytes)
+	lineNo := 0
+	depth := 0
+	heredoc := ""
+	inComment := false
+
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case heredoc != "":
+			if trimmed == heredoc {
+				heredoc = ""
+			}
+			continue
+		case inComment:
+			if strings.Contains(trimmed, "*/") {
+				inComment = false
+			}
+			continue
+		case trimmed == "", strings.HasPrefix(trimmed, "#"), strings.HasPrefix(trimmed, "//"):
+			continue
+		case strings.HasPrefix(trimmed, "/*"):
+			inComment = !strings.Contains(trimmed, "*/")
+			continue
+		}
+
+		if depth == 0 {
+			if m := tfAssignPattern.FindStringSubmatch(trimmed); m != nil {
+				value := stripTFComment(m[2])
+				v := tfVar{
+					Name: m[1],
+					Line: lineNo,
+					Col:  strings.Index(line, m[1]) + 1,
+				}
+				if strings.HasPrefix(value, "<<") {
+					heredoc = strings.TrimPrefix(strings.TrimPrefix(value, "<<"), "-")
+				} else if !strings.HasPrefix(value, "[") && !strings.HasPrefix(value, "{") {
+					v.Value = strings.Trim(value, `"`)
+					v.Literal = true
+				}
+				vars = append(vars, v)
+			}
+		}
+		depth += bracketDelta(trimmed)
+		if depth < 0 {
+			depth = 0
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, &LimitError{Limit: "line length", Max: int64(limits.MaxLineBytes), Line: lineNo + 1}
+		}
+		return nil, err
+	}
+	return vars, nil
+}
+
+// bracketDelta counts opening minus closing brackets outside string literals.
+func bracketDelta(line string) int {
+	delta := 0
+	inString := false
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && inString:
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '#':
+			return delta
+		case c == '{' || c == '[' || c == '(':
+			delta++
+		case c == '}' || c == ']' || c == ')':
+			delta--
+		}
+	}
+	return delta
+}
+
+func stripTFComment(value string) string {
+	inString := false
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '"':
+			inString = !inString
+		case '#':
+			if !inString {
+				return strings.TrimSpace(value[:i])
+			}
+		}
+	}
+	return strings.TrimSpace(value)
+}
+
+func parseTFVarsJSON(data []byte) ([]tfVar, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("tfvars JSON must be an object")
+	}
+
+	var vars []tfVar
+	for dec.More() {
+		keyTok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, _ := keyTok.(string)
+		line, col := offsetPosition(data, int(dec.InputOffset()))
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		v := tfVar{Name: name, Line: line, Col: max(col-len(name)-1, 1)}
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			v.Value, v.Literal = s, true
+		}
+		vars = append(vars, v)
+	}
+	return vars, nil
+}
+
+// offsetPosition converts a byte offset into a 1-based line and column.
+func offsetPosition(data []byte, offset int) (line, col int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	line = bytes.Count(data[:offset], []byte{'\n'}) + 1
+	col = offset - bytes.LastIndexByte(data[:offset], '\n')
+	return line, col
+}
+
+func toSnakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '-':
+			b.WriteByte('_')
+		case r >= 'A' && r <= 'Z':
+			if i > 0 && name[i-1] != '_' && name[i-1] != '-' {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r + ('a' - 'A'))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,126 @@
+package linter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const vaultPrefix = "vault:"
+
+// VaultRef is a `vault:<path>#<key>` reference found in a config value.
+type VaultRef struct {
+	Field string
+	Path  string
+	Key   string
+	Line  int
+	Col   int
+}
+
+// SecretChecker reports whether a referenced secret exists.
+type SecretChecker interface {
+	SecretExists(ctx context.Context, path, key string) (bool, error)
+}
+
+// FindVaultRefs returns every well-formed vault reference in the config,
+// ordered by line.
+func FindVaultRefs(data []byte) ([]VaultRef, error) {
+	cfg, err := parseConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	var refs []VaultRef
+	for _, v := range vaultValues(cfg) {
+		if path, key, ok := parseVaultRef(v.value.Value); ok {
+			refs = append(refs, VaultRef{Field: v.field, Path: path, Key: key, Line: v.value.Line, Col: v.value.Col})
+		}
+	}
+	return refs, nil
+}
+
+// CheckVaultRefs verifies every vault reference against checker and returns
+// an error issue for each reference that does not resolve.
+func CheckVaultRefs(ctx context.Context, data []byte, checker SecretChecker) ([]Issue, error) {
+	refs, err := FindVaultRefs(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	for _, ref := range refs {
+		ok, err := checker.SecretExists(ctx, ref.Path, ref.Key)
+		if err != nil {
+			return nil, fmt.Errorf("checking %s: %w", ref.Field, err)
+		}
+		if !ok {
+			issues = append(issues, Issue{
+				Line:         ref.Line,
+				Column:       ref.Col,
+				Severity:     SeverityError,
+				RuleID:       "VAULT002",
+				Message:      fmt.Sprintf("%s references missing secret %s#%s", ref.Field, ref.Path, ref.Key),
+				SuggestedFix: "Create the secret in Vault or fix the reference path",
+			})
+		}
+	}
+	return issues, nil
+}
+
+func validateVaultRefs(cfg parsedConfig, issues *[]Issue) {
+	for _, v := range vaultValues(cfg) {
+		if _, _, ok := parseVaultRef(v.value.Value); !ok {
+			*issues = append(*issues, Issue{
+				Line:         v.value.Line,
+				Column:       v.value.Col,
+				Severity:     SeverityWarning,
+				RuleID:       "VAULT001",
+				Message:      fmt.Sprintf("%s has a malformed vault reference", v.field),
+				SuggestedFix: "Use the form vault:<path>#<key>",
+			})
+		}
+	}
+}
+
+type fieldValue struct {
+	field string
+	value fieldInfo
+}
+
+// vaultValues returns the fields whose value is a vault reference (well
+// formed or not), ordered by line.
+func vaultValues(cfg parsedConfig) []fieldValue {
+	var values []fieldValue
+	add := func(section, key string, info fieldInfo) {
+		if strings.HasPrefix(info.Value, vaultPrefix) {
+			values = append(values, fieldValue{field: section + "." + key, value: info})
+		}
+	}
+	for key, info := range cfg.Metadata {
+		add("metadata", key, info)
+	}
+	for key, info := range cfg.Settings {
+		add("settings", key, info)
+	}
+	for _, feature := range cfg.Features {
+		for key, info := range feature.Fields {
+			add("features", key, info)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].value.Line < values[j].value.Line
+	})
+	return values
+}
+
+func parseVaultRef(value string) (path, key string, ok bool) {
+	if !strings.HasPrefix(value, vaultPrefix) {
+		return "", "", false
+	}
+	path, key, found := strings.Cut(strings.TrimPrefix(value, vaultPrefix), "#")
+	path = strings.Trim(path, "/")
+	if !found || path == "" || key == "" || strings.ContainsAny(path+key, " #") {
+		return "", "", false
+	}
+	return path, key, true
+}
@@ -0,0 +1,213 @@
+package linter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// LintXML lints an XML config with the same rules as YAML and JSON configs.
+// LintBytes and LintReader recognize XML on their own; LintXML is for
+// callers that know the format. See parseXML for how elements map onto
+// sections.
+func LintXML(data []byte, opts Options) ([]Issue, error) {
+	if err := checkText(data[:min(len(data), textSniff)]); err != nil {
+		return nil, err
+	}
+	limits := opts.Limits.Effective()
+	cfg, err := parseXML(&limitedReader{r: bytes.NewReader(data), max: limits.MaxBytes}, limits)
+	if err != nil {
+		return nil, err
+	}
+	return lintParsed(cfg, opts), nil
+}
+
+// looksLikeXML reports whether data starts, after whitespace, with a tag.
+func looksLikeXML(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '<'
+}
+
+type xmlNode struct {
+	name     string
+	attrs    []xml.Attr
+	text     string
+	line     int
+	col      int
+	children []*xmlNode
+}
+
+// parseXML reads the root element's children as sections: <metadata> and
+// <settings> hold one element (or attribute) per field, with nested
+// elements in metadata read as a map like labels. Each child of <features>
+// is a feature entry whose attributes and child elements are its fields; a
+// <requires> with child elements is a list. Attributes of the root and its
+// childless elements are top-level keys, and any other element is a
+// section. <!-- configlint-disable-... --> comments are suppressions.
+//
+// A syntax error is reported as XML001 on its line, and what was read
+// before it is still linted.
+func parseXML(r io.Reader, limits Limits) (parsedConfig, error) {
+	cfg := newParsedConfig()
+	cfg.Frontend = "xml"
+
+	dec := xml.NewDecoder(r)
+	dec.Strict = true
+	var stack []*xmlNode
+	var root *xmlNode
+	for {
+		line, col := dec.InputPos()
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var limitErr *LimitError
+			if errors.As(err, &limitErr) {
+				return cfg, err
+			}
+			var syntaxErr *xml.SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				return cfg, err
+			}
+			cfg.ParseIssues = append(cfg.ParseIssues, Issue{
+				Line:     syntaxErr.Line,
+				Severity: SeverityError,
+				RuleID:   "XML001",
+				Message:  "malformed XML: " + syntaxErr.Msg,
+			})
+			break
+		}
+		if line > limits.MaxLines {
+			return cfg, &LimitError{Limit: "line count", Max: int64(limits.MaxLines), Line: line}
+		}
+		if col > limits.MaxLineBytes {
+			return cfg, &LimitError{Limit: "line length", Max: int64(limits.MaxLineBytes), Line: line}
+		}
+		cfg.Lines = line
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if len(stack) >= limits.MaxDepth {
+				return cfg, &LimitError{Limit: "nesting depth", Max: int64(limits.MaxDepth), Line: line}
+			}
+			n := &xmlNode{name: tok.Name.Local, attrs: tok.Attr, line: line, col: col}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(tok)
+			}
+		case xml.Comment:
+			text := strings.TrimSpace(string(tok))
+			if directive, ok := strings.CutPrefix(text, "configlint-"); ok && strings.HasPrefix(directive, "disable-") {
+				if s, ok := parseSuppression(directive, line); ok {
+					cfg.Suppressions = append(cfg.Suppressions, s)
+				}
+			}
+		}
+	}
+	if line, _ := dec.InputPos(); line > cfg.Lines {
+		cfg.Lines = line
+	}
+	if root == nil {
+		return cfg, nil
+	}
+
+	for _, a := range root.attrs {
+		cfg.TopLevel[a.Name.Local] = fieldInfo{Value: a.Value, Line: root.line, Col: root.col}
+	}
+	for _, section := range root.children {
+		switch section.name {
+		case "metadata":
+			cfg.MetadataLine = section.line
+			for key, info := range xmlFields(section) {
+				cfg.Metadata[key] = info
+			}
+			for _, m := range section.children {
+				if len(m.children) == 0 {
+					continue
+				}
+				if cfg.MetadataMaps == nil {
+					cfg.MetadataMaps = make(map[string]map[string]fieldInfo)
+				}
+				nested := xmlFields(m)
+				cfg.MetadataMaps[m.name] = nested
+				for key, info := range nested {
+					cfg.Metadata[key] = info
+				}
+			}
+		case "settings":
+			cfg.SettingsLine = section.line
+			for key, info := range xmlFields(section) {
+				cfg.Settings[key] = info
+			}
+		case "features":
+			cfg.FeaturesLine = section.line
+			for _, f := range section.children {
+				if len(cfg.Features) >= limits.MaxFeatures {
+					return cfg, &LimitError{Limit: "feature count", Max: int64(limits.MaxFeatures), Line: f.line}
+				}
+				cfg.Features = append(cfg.Features, featureEntry{Fields: xmlFields(f), Line: f.line, Col: f.col})
+			}
+		default:
+			if len(section.children) == 0 && len(section.attrs) == 0 {
+				cfg.TopLevel[section.name] = xmlField(section)
+				continue
+			}
+			fields := make(map[string]fieldInfo)
+			xmlFlatten(section, fields)
+			cfg.Sections[section.name] = sectionInfo{Fields: fields, Line: section.line}
+		}
+	}
+	return cfg, nil
+}
+
+// xmlFields returns the attributes and child elements of n as fields.
+func xmlFields(n *xmlNode) map[string]fieldInfo {
+	fields := make(map[string]fieldInfo, len(n.attrs)+len(n.children))
+	for _, a := range n.attrs {
+		fields[a.Name.Local] = fieldInfo{Value: a.Value, Line: n.line, Col: n.col}
+	}
+	for _, c := range n.children {
+		fields[c.name] = xmlField(c)
+	}
+	return fields
+}
+
+// xmlField is an element's text, or "[a, b]" for an element whose children
+// are the items of a list. Other nested elements have an empty value, like
+// nested YAML mappings.
+func xmlField(n *xmlNode) fieldInfo {
+	info := fieldInfo{Value: strings.TrimSpace(n.text), Line: n.line, Col: n.col}
+	if len(n.children) == 0 {
+		return info
+	}
+	items := make([]string, 0, len(n.children))
+	for _, c := range n.children {
+		if len(c.children) > 0 || len(c.attrs) > 0 {
+			return fieldInfo{Line: n.line, Col: n.col}
+		}
+		items = append(items, strings.TrimSpace(c.text))
+	}
+	info.Value = "[" + strings.Join(items, ", ") + "]"
+	return info
+}
+
+func xmlFlatten(n *xmlNode, fields map[string]fieldInfo) {
+	for key, info := range xmlFields(n) {
+		fields[key] = info
+	}
+	for _, c := range n.children {
+		xmlFlatten(c, fields)
+	}
+}
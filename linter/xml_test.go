@@ -0,0 +1,79 @@
+package linter
+
+import "testing"
+
+func TestLintXML(t *testing.T) {
+	content := []byte(`<?xml version="1.0"?>
+<config apiVersion="v1">
+  <metadata>
+    <name>billing</name>
+    <env>qa</env>
+    <labels>
+      <team>payments</team>
+    </labels>
+  </metadata>
+  <settings replicas="0">
+    <timeout>30</timeout>
+  </settings>
+  <features>
+    <feature name="beta" enabled="yes"/>
+    <feature>
+      <name>search</name>
+      <enabled>true</enabled>
+      <requires><feature>beta</feature><feature>ghost</feature></requires>
+    </feature>
+  </features>
+</config>
+`)
+
+	for _, lint := range []func([]byte, Options) ([]Issue, error){
+		LintXML,
+		func(data []byte, opts Options) ([]Issue, error) { return LintNamed("billing.xml", data, opts) },
+		LintWithOptions,
+	} {
+		issues, err := lint(content, Options{})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+
+		want := map[string]int{"META004": 5, "SET003": 10, "FEAT003": 14, "FEAT004": 18, "FEAT005": 18}
+		got := make(map[string]int)
+		for _, issue := range issues {
+			got[issue.RuleID] = issue.Line
+		}
+		if len(got) != len(want) {
+			t.Fatalf("expected %v, got %+v", want, issues)
+		}
+		for id, line := range want {
+			if got[id] != line {
+				t.Errorf("expected %s on line %d, got %+v", id, line, issues)
+			}
+		}
+	}
+}
+
+func TestLintXMLReportsSyntaxErrors(t *testing.T) {
+	content := []byte(`<config>
+  <metadata>
+    <name>billing</name>
+    <env>prod</env>
+  </metadata>
+  <settings>
+    <replicas>2</settings>
+</config>
+`)
+
+	issues, err := LintXML(content, Options{})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	for _, issue := range issues {
+		if issue.RuleID == "XML001" {
+			if issue.Line != 7 {
+				t.Errorf("expected XML001 on line 7, got %+v", issue)
+			}
+			return
+		}
+	}
+	t.Fatalf("expected XML001, got %+v", issues)
+}
@@ -0,0 +1,483 @@
+package linter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlDecodeMax bounds the documents handed to the YAML decoder. It needs
+// the whole document in memory, so larger inputs are left to the streaming
+// line scanner.
+const yamlDecodeMax = 16 << 20
+
+// decodeYAML rebuilds the structure of cfg from a real YAML parse of text:
+// nested mappings, block scalars, flow collections, anchors and comments
+// after values all read the way YAML readers see them. Suppressions, line
+// count and JSON detection stay as the line scanner recorded them.
+//
+// It reports false and leaves cfg alone when text is not a single
+// well-formed document; the scanner is lenient, so configs with syntax
+// errors are still linted. A syntax error is added to cfg.ParseIssues.
+func decodeYAML(text []byte, cfg *parsedConfig) (ok bool) {
+	// The decoder is fed untrusted input; treat a panic like a syntax error.
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	// The decoder splits comments on CRLF input as if every line were
+	// separated by a blank one; lines and columns are the same without \r.
+	if bytes.Contains(text, []byte("\r\n")) {
+		text = bytes.ReplaceAll(text, []byte("\r\n"), []byte("\n"))
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(text))
+	var doc yaml.Node
+	if err := dec.Decode(&doc); err != nil {
+		if !errors.Is(err, io.EOF) {
+			cfg.ParseIssues = append(cfg.ParseIssues, yamlSyntaxIssue(err, text))
+		}
+		return false
+	}
+	var extra yaml.Node
+	if err := dec.Decode(&extra); !errors.Is(err, io.EOF) {
+		if err != nil {
+			cfg.ParseIssues = append(cfg.ParseIssues, yamlSyntaxIssue(err, text))
+		}
+		return false
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return false
+	}
+	if err := checkAliases(doc.Content[0]); err != nil {
+		cfg.ParseIssues = append(cfg.ParseIssues, yamlSyntaxIssue(err, text))
+		return false
+	}
+	if bytes.ContainsRune(text, '\r') {
+		// A lone \r breaks lines for the decoder but not the scanner; keep
+		// the scanner's positions, which are the ones editors show.
+		return false
+	}
+	buildConfig(doc.Content[0], text, cfg)
+	cfg.Comments = Comments{Head: doc.HeadComment, Line: doc.LineComment, Foot: doc.FootComment}
+	return true
+}
+
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// yamlSyntaxIssue reports a decoder error as SYN001 on the line of text it
+// names.
+func yamlSyntaxIssue(err error, text []byte) Issue {
+	issue := Issue{Line: 1, Severity: SeverityError, RuleID: "SYN001", Message: "malformed YAML: " + strings.TrimPrefix(err.Error(), "yaml: ")}
+	if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		issue.Line = sourceLine(text, n)
+		issue.Message = "malformed YAML: " + m[2]
+	}
+	return issue
+}
+
+// buildConfig replaces the structure of cfg with the one under root, a
+// decoded document's top-level mapping.
+func buildConfig(root *yaml.Node, text []byte, cfg *parsedConfig) {
+	b := yamlBuilder{lines: strings.Split(string(text), "\n"), json: cfg.JSON}
+	next := newParsedConfig()
+	next.Suppressions = cfg.Suppressions
+	next.Lines = cfg.Lines
+	next.JSON = cfg.JSON
+	next.ParseIssues = cfg.ParseIssues
+	b.root(root, &next)
+	*cfg = next
+}
+
+// sourceLine converts a line number from the decoder, which also breaks
+// lines at a lone \r, to one counted as the line scanner counts them, by
+// \n alone.
+func sourceLine(text []byte, n int) int {
+	decoder, line := 1, 1
+	for i, b := range text {
+		if decoder >= n {
+			break
+		}
+		switch {
+		case b == '\n':
+			decoder++
+			line++
+		case b == '\r' && (i+1 == len(text) || text[i+1] != '\n'):
+			decoder++
+		}
+	}
+	return line
+}
+
+type yamlBuilder struct {
+	lines []string
+	json  bool
+}
+
+func (b yamlBuilder) root(root *yaml.Node, cfg *parsedConfig) {
+	for _, p := range mappingPairs(root, nil, 0) {
+		key, value := p.key, resolveAlias(p.value)
+		switch key.Value {
+		case "metadata", "settings", "features":
+			if c := pairComments(p); c != (Comments{}) {
+				if cfg.KeyComments == nil {
+					cfg.KeyComments = make(map[string]Comments)
+				}
+				cfg.KeyComments[key.Value] = c
+			}
+		}
+		switch key.Value {
+		case "metadata":
+			cfg.MetadataLine = p.at.Line
+			b.metadata(value, cfg, p.inner())
+			continue
+		case "settings":
+			cfg.SettingsLine = p.at.Line
+			b.mapping(value, cfg.Settings, p.inner())
+			continue
+		case "features":
+			cfg.FeaturesLine = p.at.Line
+			b.features(value, cfg, p.inner())
+			continue
+		}
+
+		if isIncludeKey(key.Value) {
+			cfg.Includes = append(cfg.Includes, b.includes(value, p.inner())...)
+		}
+		if value.Kind == yaml.ScalarNode && value.Value != "" || value.Kind == yaml.SequenceNode && value.Style&yaml.FlowStyle != 0 {
+			cfg.TopLevel[key.Value] = b.pairField(p, value)
+			continue
+		}
+		section := sectionInfo{Fields: make(map[string]fieldInfo), Line: p.at.Line, Comments: pairComments(p)}
+		b.flatten(value, section.Fields, p.inner())
+		cfg.Sections[key.Value] = section
+	}
+}
+
+// metadata records metadata's own keys and, for nested mappings such as
+// labels, their keys both under MetadataMaps and in Metadata.
+func (b yamlBuilder) metadata(node *yaml.Node, cfg *parsedConfig, at *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for _, p := range mappingPairs(node, at, 0) {
+		value := resolveAlias(p.value)
+		cfg.Metadata[p.key.Value] = b.pairField(p, value)
+		if value.Kind != yaml.MappingNode {
+			continue
+		}
+		if cfg.MetadataMaps == nil {
+			cfg.MetadataMaps = make(map[string]map[string]fieldInfo)
+		}
+		nested := make(map[string]fieldInfo)
+		b.mapping(value, nested, p.inner())
+		cfg.MetadataMaps[p.key.Value] = nested
+		for k, info := range nested {
+			cfg.Metadata[k] = info
+		}
+	}
+}
+
+func (b yamlBuilder) features(node *yaml.Node, cfg *parsedConfig, at *yaml.Node) {
+	if node.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, raw := range node.Content {
+		item, site := resolveAlias(raw), aliasSite(raw, at)
+		entry := featureEntry{Fields: make(map[string]fieldInfo), Line: item.Line, Col: item.Column, Comments: nodeComments(item)}
+		if item.Kind == yaml.MappingNode && len(item.Content) > 0 {
+			first := item.Content[0]
+			entry.Line, entry.Col = first.Line, first.Column
+		}
+		pos := item
+		if site != nil {
+			// An aliased entry is reported where the alias is, not where
+			// its anchor is.
+			pos = site
+			entry.Line, entry.Col = site.Line, site.Column
+		}
+		if dash := b.dashBefore(pos.Line, pos.Column); dash > 0 {
+			entry.Line, entry.Col = pos.Line, dash
+		}
+		b.mapping(item, entry.Fields, site)
+		cfg.Features = append(cfg.Features, entry)
+	}
+}
+
+// dashBefore returns the column of the sequence dash introducing the node
+// at line:col, or 0 when the node does not share a line with its dash.
+func (b yamlBuilder) dashBefore(line, col int) int {
+	if line < 1 || line > len(b.lines) {
+		return 0
+	}
+	text := b.lines[line-1]
+	for i := min(col-2, len(text)-1); i >= 0; i-- {
+		switch text[i] {
+		case ' ', '\t':
+			continue
+		case '-':
+			return i + 1
+		}
+		return 0
+	}
+	return 0
+}
+
+// mapping records the direct children of a mapping node.
+func (b yamlBuilder) mapping(node *yaml.Node, fields map[string]fieldInfo, at *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for _, p := range mappingPairs(node, at, 0) {
+		fields[p.key.Value] = b.pairField(p, resolveAlias(p.value))
+	}
+}
+
+// flatten records every key/value pair below node, the way the line scanner
+// reads sections it has no schema for.
+func (b yamlBuilder) flatten(node *yaml.Node, fields map[string]fieldInfo, at *yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for _, p := range mappingPairs(node, at, 0) {
+			value := resolveAlias(p.value)
+			fields[p.key.Value] = b.pairField(p, value)
+			b.flatten(value, fields, p.inner())
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			b.flatten(resolveAlias(item), fields, aliasSite(item, at))
+		}
+	}
+}
+
+func (b yamlBuilder) includes(node *yaml.Node, at *yaml.Node) []fieldInfo {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Value != "" {
+			pos := node
+			if at != nil {
+				pos = at
+			}
+			return []fieldInfo{{Value: node.Value, Line: pos.Line, Col: pos.Column}}
+		}
+	case yaml.SequenceNode:
+		var refs []fieldInfo
+		for _, raw := range node.Content {
+			item, pos := resolveAlias(raw), raw
+			if at != nil {
+				pos = at
+			}
+			if item.Kind == yaml.ScalarNode && item.Value != "" {
+				refs = append(refs, fieldInfo{Value: item.Value, Line: pos.Line, Col: pos.Column})
+			}
+		}
+		return refs
+	}
+	return nil
+}
+
+// field describes a value and where it is reported. Mappings and nulls have
+// an empty value and lists of scalars read as "[a, b]", as the scanner
+// renders them.
+func (b yamlBuilder) field(at, value *yaml.Node) fieldInfo {
+	info := fieldInfo{Line: at.Line, Col: at.Column}
+	if !b.json {
+		info.Style = nodeStyle(value)
+	}
+	switch value.Kind {
+	case yaml.ScalarNode:
+		info.Value = value.Value
+		info.Quoted = !b.json && value.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(value.Content))
+		for _, item := range value.Content {
+			if item = resolveAlias(item); item.Kind == yaml.ScalarNode {
+				items = append(items, item.Value)
+			}
+		}
+		if len(items) > 0 {
+			info.Value = "[" + strings.Join(items, ", ") + "]"
+		}
+	}
+	return info
+}
+
+// pairField is field for a mapping entry, with the comments on its key and
+// value.
+func (b yamlBuilder) pairField(p yamlPair, value *yaml.Node) fieldInfo {
+	info := b.field(p.at, value)
+	info.Comments = pairComments(p)
+	if p.value.Kind == yaml.AliasNode && !b.json {
+		info.Style = "alias"
+	}
+	return info
+}
+
+// pairComments joins the comments yaml.v3 attaches to an entry's key and to
+// its value: a comment after a scalar value sits on the value, one after a
+// key that opens a nested mapping on the key.
+func pairComments(p yamlPair) Comments {
+	c := nodeComments(p.key)
+	v := nodeComments(p.value)
+	if v.Line != "" {
+		c.Line = v.Line
+	}
+	if c.Foot == "" {
+		c.Foot = v.Foot
+	}
+	return c
+}
+
+func nodeComments(n *yaml.Node) Comments {
+	return Comments{Head: cleanComment(n.HeadComment), Line: cleanComment(n.LineComment), Foot: cleanComment(n.FootComment)}
+}
+
+// cleanComment drops the line break the decoder leaves after some comments.
+func cleanComment(s string) string {
+	return strings.TrimRight(s, "\n")
+}
+
+// nodeStyle names how a value was written; see Field.Style.
+func nodeStyle(n *yaml.Node) string {
+	switch {
+	case n.Style&yaml.DoubleQuotedStyle != 0:
+		return "double"
+	case n.Style&yaml.SingleQuotedStyle != 0:
+		return "single"
+	case n.Style&yaml.LiteralStyle != 0:
+		return "literal"
+	case n.Style&yaml.FoldedStyle != 0:
+		return "folded"
+	case n.Style&yaml.FlowStyle != 0:
+		return "flow"
+	case n.Kind == yaml.ScalarNode:
+		return "plain"
+	}
+	return "block"
+}
+
+// maxMergeDepth bounds how deeply << merge keys are followed, so anchors
+// that merge each other cannot recurse forever.
+const maxMergeDepth = 32
+
+// yamlPair is one entry of a mapping. at is where it is reported: its key,
+// or the alias or << merge key that brought it in.
+type yamlPair struct {
+	key, value, at *yaml.Node
+}
+
+// inner is where entries under the pair's value are reported: nil for their
+// own keys, or the alias or merge key the value was reached through.
+func (p yamlPair) inner() *yaml.Node {
+	if p.at != p.key {
+		return p.at
+	}
+	return aliasSite(p.value, nil)
+}
+
+// mappingPairs lists the entries of a mapping with << merge keys expanded:
+// keys set on the mapping itself win over merged ones, and among merged
+// mappings the first to set a key wins, as in YAML 1.1. at, when set, is
+// where every entry is reported because the mapping was reached through an
+// alias.
+func mappingPairs(node, at *yaml.Node, depth int) []yamlPair {
+	var pairs, merged []yamlPair
+	set := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Kind == yaml.ScalarNode && key.ShortTag() == "!!merge" {
+			if depth >= maxMergeDepth {
+				continue
+			}
+			site := at
+			if site == nil {
+				site = key
+			}
+			sources := []*yaml.Node{value}
+			if v := resolveAlias(value); v.Kind == yaml.SequenceNode {
+				sources = v.Content
+			}
+			for _, src := range sources {
+				if src = resolveAlias(src); src.Kind == yaml.MappingNode {
+					merged = append(merged, mappingPairs(src, site, depth+1)...)
+				}
+			}
+			continue
+		}
+		p := yamlPair{key: key, value: value, at: key}
+		if at != nil {
+			p.at = at
+		}
+		pairs = append(pairs, p)
+		set[key.Value] = true
+	}
+	for _, p := range merged {
+		if !set[p.key.Value] {
+			set[p.key.Value] = true
+			pairs = append(pairs, p)
+		}
+	}
+	return pairs
+}
+
+// aliasSite is where what node refers to is reported: at when already
+// inside an alias, else node itself if it is an alias.
+func aliasSite(node, at *yaml.Node) *yaml.Node {
+	if at == nil && node.Kind == yaml.AliasNode {
+		return node
+	}
+	return at
+}
+
+// maxAliasExpansion bounds the nodes a document expands to with its aliases
+// followed, as the decoder bounds aliasing when decoding into Go values.
+const maxAliasExpansion = 1 << 20
+
+// checkAliases rejects documents the builder cannot walk: an alias inside
+// the node it refers to would be followed forever, and nested aliases can
+// expand a small document exponentially. Sizes are counted once per node.
+func checkAliases(root *yaml.Node) error {
+	const visiting = -1
+	sizes := make(map[*yaml.Node]int)
+	var size func(node *yaml.Node) (int, error)
+	size = func(node *yaml.Node) (int, error) {
+		if node.Kind == yaml.AliasNode && node.Alias != nil {
+			if sizes[node.Alias] == visiting {
+				return 0, fmt.Errorf("yaml: line %d: alias *%s refers to a node that contains it", node.Line, node.Value)
+			}
+			return size(node.Alias)
+		}
+		if n, ok := sizes[node]; ok {
+			return n, nil
+		}
+		sizes[node] = visiting
+		total := 1
+		for _, child := range node.Content {
+			n, err := size(child)
+			if err != nil {
+				return 0, err
+			}
+			if total += n; total > maxAliasExpansion {
+				return 0, fmt.Errorf("yaml: line %d: document contains excessive aliasing", max(node.Line, 1))
+			}
+		}
+		sizes[node] = total
+		return total, nil
+	}
+	_, err := size(root)
+	return err
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
@@ -0,0 +1,58 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	yaml11Bools       = []string{"y", "yes", "n", "no", "on", "off"}
+	yaml11Octal       = regexp.MustCompile(`^[-+]?0[0-9]+$`)
+	yaml11Exponent    = regexp.MustCompile(`^[-+]?[0-9]+[eE][-+]?[0-9]+$`)
+	yaml11Sexagesimal = regexp.MustCompile(`^[-+]?[0-9]+(:[0-5]?[0-9])+$`)
+	yaml11Underscore  = regexp.MustCompile(`^[-+]?[0-9][0-9_]*_[0-9_]*$`)
+)
+
+// ambiguousYAMLType explains how YAML 1.1 and 1.2 parsers disagree on an
+// unquoted scalar, or returns "" when they agree.
+func ambiguousYAMLType(value string) string {
+	switch {
+	case contains(yaml11Bools, strings.ToLower(value)):
+		return "a boolean by YAML 1.1 parsers and a string by YAML 1.2"
+	case yaml11Octal.MatchString(value):
+		return "an octal number by YAML 1.1 parsers and a decimal one by YAML 1.2"
+	case yaml11Exponent.MatchString(value):
+		return "a string by YAML 1.1 parsers and a float by YAML 1.2"
+	case yaml11Sexagesimal.MatchString(value):
+		return "a base-60 number by YAML 1.1 parsers and a string by YAML 1.2"
+	case yaml11Underscore.MatchString(value):
+		return "a number by YAML 1.1 parsers and a string by YAML 1.2"
+	}
+	return ""
+}
+
+// validateYAMLTypes reports unquoted YAML values whose type depends on the
+// YAML version of whatever reads the config: the Norway problem (NO read
+// as false), 012 read as 10, and friends (TYPE001). JSON and the other
+// frontends have no such ambiguity and are skipped.
+func validateYAMLTypes(cfg parsedConfig, _ Options, issues *[]Issue) {
+	if cfg.JSON || cfg.Frontend != "" {
+		return
+	}
+	for _, v := range configValues(cfg) {
+		if v.value.Quoted {
+			continue
+		}
+		if reading := ambiguousYAMLType(v.value.Value); reading != "" {
+			*issues = append(*issues, Issue{
+				Line:         v.value.Line,
+				Column:       v.value.Col,
+				Severity:     SeverityWarning,
+				RuleID:       "TYPE001",
+				Message:      fmt.Sprintf("%s value %q is read as %s", v.field, v.value.Value, reading),
+				SuggestedFix: fmt.Sprintf("Quote it (%q) if it is a string, or write the intended value explicitly", v.value.Value),
+			})
+		}
+	}
+}
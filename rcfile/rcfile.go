@@ -0,0 +1,103 @@
+// Package rcfile loads the per-project .configlintrc.json shared by the CLI
+// and the server.
+package rcfile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cli-config-linter/linter"
+	"cli-config-linter/rulepack"
+)
+
+const DefaultName = ".configlintrc.json"
+
+// PackDir is where rule packs are discovered, relative to the rc file.
+const PackDir = ".configlint/rulepacks"
+
+// File is the rc file layout. Presets set directly in the file override
+// anything provided by its rule packs.
+type File struct {
+	linter.Options
+	RulePacks      []rulepack.Ref  `json:"rulePacks,omitempty"`
+	RulePackPolicy rulepack.Policy `json:"rulePackPolicy,omitempty"`
+	// Plugins pins Go plugins by file name to their sha256; see goplugin.
+	// The rule pack policy's requirePinned applies to them too.
+	Plugins map[string]string `json:"plugins,omitempty"`
+	// Dir is the directory the file was loaded from; packs under its
+	// PackDir are discovered. Clearing it turns discovery off.
+	Dir string `json:"-"`
+}
+
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	f.Dir = filepath.Dir(path)
+	return &f, nil
+}
+
+// Refs lists the packs to load: those named in the file, then the *.json
+// files under PackDir in name order. Relative file: sources are relative to
+// the rc file, and a discovered file the file already names is not loaded
+// twice.
+func (f *File) Refs() ([]rulepack.Ref, error) {
+	refs := append([]rulepack.Ref(nil), f.RulePacks...)
+	for i, ref := range refs {
+		if path, ok := strings.CutPrefix(ref.Source, "file:"); ok && !filepath.IsAbs(path) && f.Dir != "" {
+			refs[i].Source = "file:" + filepath.Join(f.Dir, path)
+		}
+	}
+	if f.Dir == "" {
+		return refs, nil
+	}
+	found, err := filepath.Glob(filepath.Join(f.Dir, PackDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range found {
+		ref := rulepack.Ref{Source: "file:" + path}
+		if !slices.ContainsFunc(refs, func(r rulepack.Ref) bool { return r.Source == ref.Source }) {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// Resolve fetches the rule packs in order, under the file's rule pack
+// policy, and layers the file's own presets on top, returning the effective
+// options and the packs that produced them.
+func (f *File) Resolve(ctx context.Context, fetcher *rulepack.Fetcher) (linter.Options, []*rulepack.Pack, error) {
+	var opts linter.Options
+	refs, err := f.Refs()
+	if err != nil {
+		return linter.Options{}, nil, err
+	}
+	packs := make([]*rulepack.Pack, 0, len(refs))
+	for _, ref := range refs {
+		if err := f.RulePackPolicy.Check(ref); err != nil {
+			return linter.Options{}, nil, err
+		}
+		pack, err := fetcher.Fetch(ctx, ref)
+		if err != nil {
+			return linter.Options{}, nil, err
+		}
+		opts = opts.Merge(pack.Options)
+		packs = append(packs, pack)
+	}
+	opts = opts.Merge(f.Options)
+	if err := opts.Validate(); err != nil {
+		return linter.Options{}, nil, err
+	}
+	return opts, packs, nil
+}
@@ -0,0 +1,132 @@
+package rulepack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// MediaType marks the manifest layer holding the pack document. Manifests
+// without it fall back to their first layer.
+const MediaType = "application/vnd.configlint.rulepack.v1+json"
+
+var manifestAccept = strings.Join([]string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}, ", ")
+
+type ociManifest struct {
+	Layers []struct {
+		MediaType string `json:"mediaType"`
+		Digest    string `json:"digest"`
+	} `json:"layers"`
+}
+
+func (f *Fetcher) fetchOCI(ctx context.Context, source string) ([]byte, error) {
+	scheme := "https"
+	if strings.HasPrefix(source, "oci+http://") {
+		scheme = "http"
+	}
+	rest := source[strings.Index(source, "://")+3:]
+
+	host, repo, found := strings.Cut(rest, "/")
+	if !found || repo == "" {
+		return nil, errors.New("oci reference must include a repository")
+	}
+	ref := "latest"
+	if i := strings.LastIndex(repo, "@"); i != -1 {
+		repo, ref = repo[:i], repo[i+1:]
+	} else if i := strings.LastIndex(repo, ":"); i != -1 {
+		repo, ref = repo[:i], repo[i+1:]
+	}
+	base := fmt.Sprintf("%s://%s/v2/%s", scheme, host, repo)
+
+	header := http.Header{"Accept": {manifestAccept}}
+	raw, err := f.getAuthorized(ctx, base+"/manifests/"+ref, header)
+	if err != nil {
+		return nil, fmt.Errorf("manifest: %w", err)
+	}
+
+	var manifest ociManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("manifest: %w", err)
+	}
+	if len(manifest.Layers) == 0 {
+		return nil, errors.New("manifest has no layers")
+	}
+	digest := manifest.Layers[0].Digest
+	for _, layer := range manifest.Layers {
+		if layer.MediaType == MediaType {
+			digest = layer.Digest
+			break
+		}
+	}
+
+	blob, err := f.getAuthorized(ctx, base+"/blobs/"+digest, header)
+	if err != nil {
+		return nil, fmt.Errorf("blob: %w", err)
+	}
+	if want := strings.TrimPrefix(digest, "sha256:"); want != digest && checksum(blob) != want {
+		return nil, fmt.Errorf("blob digest mismatch for %s", digest)
+	}
+	return blob, nil
+}
+
+// getAuthorized performs a GET and, if the registry answers with a bearer
+// challenge, retries once with an anonymous token.
+func (f *Fetcher) getAuthorized(ctx context.Context, target string, header http.Header) ([]byte, error) {
+	data, err := f.get(ctx, target, header)
+	var status *statusError
+	if !errors.As(err, &status) || status.code != http.StatusUnauthorized {
+		return data, err
+	}
+
+	token, err := f.anonymousToken(ctx, status.challenge)
+	if err != nil {
+		return nil, err
+	}
+	authed := header.Clone()
+	authed.Set("Authorization", "Bearer "+token)
+	return f.get(ctx, target, authed)
+}
+
+func (f *Fetcher) anonymousToken(ctx context.Context, challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", errors.New("registry requires unsupported authentication")
+	}
+	params := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(challenge, "Bearer "), ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		params[k] = strings.Trim(v, `"`)
+	}
+	if params["realm"] == "" {
+		return "", errors.New("bearer challenge without realm")
+	}
+
+	q := url.Values{}
+	for _, k := range []string{"service", "scope"} {
+		if params[k] != "" {
+			q.Set(k, params[k])
+		}
+	}
+	raw, err := f.get(ctx, params["realm"]+"?"+q.Encode(), nil)
+	if err != nil {
+		return "", fmt.Errorf("token: %w", err)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return "", fmt.Errorf("token: %w", err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
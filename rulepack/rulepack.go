@@ -0,0 +1,228 @@
+// Package rulepack fetches shared lint policy bundles so one platform team
+// can publish presets that every repository's CLI and the server consume.
+package rulepack
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cli-config-linter/linter"
+)
+
+const maxPackSize = 1024 * 1024
+
+// Pack is a versioned bundle of lint presets.
+type Pack struct {
+	Name    string         `json:"name"`
+	Version string         `json:"version"`
+	Options linter.Options `json:"options"`
+}
+
+// Version identifies a set of packs by name and version, so results can be
+// tied to the packs that produced them. It is empty when no packs are used.
+func Version(packs []*Pack) string {
+	names := make([]string, 0, len(packs))
+	for _, pack := range packs {
+		names = append(names, pack.Name+"@"+pack.Version)
+	}
+	return strings.Join(names, ",")
+}
+
+// Ref points at a pack. Source is an http(s) URL, an OCI reference of the
+// form oci://registry/repository:tag (oci+http:// for plain-HTTP registries)
+// or a local file:path.
+// When SHA256 is set the pack content must match it, and the pinned pack is
+// served from the local cache without touching the network.
+type Ref struct {
+	Source string `json:"source"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// Policy restricts which packs a Fetcher loads, so security teams can
+// control the rules CI runs with. The zero Policy allows every pack.
+type Policy struct {
+	// RequirePinned rejects packs without a sha256 pin.
+	RequirePinned bool `json:"requirePinned,omitempty"`
+	// AllowedSources, when set, are the source prefixes packs may come
+	// from (e.g. "oci://ghcr.io/acme/", "file:").
+	AllowedSources []string `json:"allowedSources,omitempty"`
+}
+
+// Check reports why ref is not allowed, or nil.
+func (p Policy) Check(ref Ref) error {
+	if p.RequirePinned && ref.SHA256 == "" {
+		return fmt.Errorf("rule pack %s: not pinned with sha256, which the rule pack policy requires", ref.Source)
+	}
+	if len(p.AllowedSources) == 0 {
+		return nil
+	}
+	for _, prefix := range p.AllowedSources {
+		if strings.HasPrefix(ref.Source, prefix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("rule pack %s: source is not allowed by the rule pack policy", ref.Source)
+}
+
+type Fetcher struct {
+	CacheDir string
+	Client   *http.Client
+	Policy   Policy
+}
+
+func NewFetcher(cacheDir string) *Fetcher {
+	return &Fetcher{
+		CacheDir: cacheDir,
+		Client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// DefaultCacheDir returns CONFIGLINT_CACHE_DIR, falling back to a directory
+// under the user cache dir.
+func DefaultCacheDir() string {
+	if dir := os.Getenv("CONFIGLINT_CACHE_DIR"); dir != "" {
+		return dir
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "configlint", "rulepacks")
+}
+
+func (f *Fetcher) Fetch(ctx context.Context, ref Ref) (*Pack, error) {
+	if err := f.Policy.Check(ref); err != nil {
+		return nil, err
+	}
+	want := strings.ToLower(strings.TrimPrefix(ref.SHA256, "sha256:"))
+
+	if want != "" && f.CacheDir != "" {
+		if data, err := os.ReadFile(filepath.Join(f.CacheDir, want+".json")); err == nil && checksum(data) == want {
+			return decode(ref, data)
+		}
+	}
+
+	var data []byte
+	var err error
+	switch {
+	case strings.HasPrefix(ref.Source, "oci://"), strings.HasPrefix(ref.Source, "oci+http://"):
+		data, err = f.fetchOCI(ctx, ref.Source)
+	case strings.HasPrefix(ref.Source, "https://"), strings.HasPrefix(ref.Source, "http://"):
+		data, err = f.get(ctx, ref.Source, nil)
+	case strings.HasPrefix(ref.Source, "file:"):
+		data, err = readFile(strings.TrimPrefix(ref.Source, "file:"))
+	default:
+		err = errors.New("unsupported source scheme")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rule pack %s: %w", ref.Source, err)
+	}
+
+	if want != "" {
+		if got := checksum(data); got != want {
+			return nil, fmt.Errorf("rule pack %s: checksum mismatch: got sha256:%s, want sha256:%s", ref.Source, got, want)
+		}
+		f.store(want, data)
+	}
+
+	return decode(ref, data)
+}
+
+// store caches a pinned pack. Failures are ignored so a read-only cache
+// directory only costs a re-download.
+func (f *Fetcher) store(sum string, data []byte) {
+	if f.CacheDir == "" {
+		return
+	}
+	if err := os.MkdirAll(f.CacheDir, 0o755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(f.CacheDir, sum+"-*.tmp")
+	if err != nil {
+		return
+	}
+	_, werr := tmp.Write(data)
+	cerr := tmp.Close()
+	if werr != nil || cerr != nil || os.Rename(tmp.Name(), filepath.Join(f.CacheDir, sum+".json")) != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+func (f *Fetcher) get(ctx context.Context, url string, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{code: resp.StatusCode, challenge: resp.Header.Get("Www-Authenticate")}
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPackSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxPackSize {
+		return nil, errors.New("pack exceeds 1MB")
+	}
+	return data, nil
+}
+
+func readFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxPackSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxPackSize {
+		return nil, errors.New("pack exceeds 1MB")
+	}
+	return data, nil
+}
+
+type statusError struct {
+	code      int
+	challenge string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status %d", e.code)
+}
+
+func decode(ref Ref, data []byte) (*Pack, error) {
+	var pack Pack
+	if err := json.Unmarshal(data, &pack); err != nil {
+		return nil, fmt.Errorf("rule pack %s: %w", ref.Source, err)
+	}
+	if pack.Name == "" {
+		return nil, fmt.Errorf("rule pack %s: name is required", ref.Source)
+	}
+	return &pack, nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,107 @@
+package rulepack
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const packJSON = `{"name":"platform","version":"1","options":{"environments":["dev","prod"]}}`
+
+func TestFetchURLPinnedAndCached(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte(packJSON))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	ref := Ref{Source: srv.URL + "/pack.json", SHA256: checksum([]byte(packJSON))}
+
+	for i := 0; i < 2; i++ {
+		pack, err := NewFetcher(dir).Fetch(context.Background(), ref)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if pack.Name != "platform" || len(pack.Options.Environments) != 2 {
+			t.Fatalf("unexpected pack: %+v", pack)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("expected pinned pack to be served from cache, got %d requests", hits)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ref.SHA256+".json")); err != nil {
+		t.Errorf("expected cached pack: %v", err)
+	}
+
+	ref.SHA256 = strings.Repeat("0", 64)
+	if _, err := NewFetcher(dir).Fetch(context.Background(), ref); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected checksum mismatch, got %v", err)
+	}
+}
+
+func TestFetchOCI(t *testing.T) {
+	digest := "sha256:" + checksum([]byte(packJSON))
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			w.Write([]byte(`{"token":"anon"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer anon" {
+			w.Header().Set("Www-Authenticate", `Bearer realm="`+srv.URL+`/token",service="test",scope="repository:policies/lint:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/policies/lint/manifests/v1":
+			w.Write([]byte(`{"layers":[{"mediaType":"` + MediaType + `","digest":"` + digest + `"}]}`))
+		case "/v2/policies/lint/blobs/" + digest:
+			w.Write([]byte(packJSON))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	source := "oci+http://" + strings.TrimPrefix(srv.URL, "http://") + "/policies/lint:v1"
+	pack, err := NewFetcher("").Fetch(context.Background(), Ref{Source: source})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if pack.Version != "1" {
+		t.Errorf("unexpected pack: %+v", pack)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pack.json")
+	if err := os.WriteFile(path, []byte(packJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ref := Ref{Source: "file:" + path}
+
+	if _, err := NewFetcher("").Fetch(context.Background(), ref); err != nil {
+		t.Fatalf("expected the local pack to load, got %v", err)
+	}
+
+	f := NewFetcher("")
+	f.Policy = Policy{RequirePinned: true}
+	if _, err := f.Fetch(context.Background(), ref); err == nil || !strings.Contains(err.Error(), "not pinned") {
+		t.Errorf("expected an unpinned pack to be rejected, got %v", err)
+	}
+	ref.SHA256 = checksum([]byte(packJSON))
+	if _, err := f.Fetch(context.Background(), ref); err != nil {
+		t.Errorf("expected the pinned pack to load, got %v", err)
+	}
+
+	f.Policy = Policy{AllowedSources: []string{"oci://ghcr.io/acme/"}}
+	if _, err := f.Fetch(context.Background(), ref); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("expected a pack from another source to be rejected, got %v", err)
+	}
+}
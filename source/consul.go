@@ -0,0 +1,78 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Consul reads documents from the Consul KV HTTP API.
+type Consul struct {
+	Addr   string
+	Token  string
+	Client *http.Client
+}
+
+type consulPair struct {
+	Key   string
+	Value []byte
+}
+
+func NewConsul(addr, token string) *Consul {
+	return &Consul{
+		Addr:   strings.TrimRight(addr, "/"),
+		Token:  token,
+		Client: &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+func (c *Consul) Fetch(ctx context.Context, prefix string, waitIndex uint64) ([]Document, uint64, error) {
+	q := url.Values{"recurse": {"true"}}
+	if waitIndex > 0 {
+		q.Set("index", strconv.FormatUint(waitIndex, 10))
+		q.Set("wait", "5m")
+	}
+	endpoint := fmt.Sprintf("%s/v1/kv/%s?%s", c.Addr, strings.TrimPrefix(prefix, "/"), q.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if c.Token != "" {
+		req.Header.Set("X-Consul-Token", c.Token)
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	index, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, index, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("consul: unexpected status %d", resp.StatusCode)
+	}
+
+	var pairs []consulPair
+	if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, 0, fmt.Errorf("consul: %w", err)
+	}
+
+	docs := make([]Document, 0, len(pairs))
+	for _, p := range pairs {
+		// Folder placeholders and empty keys carry no config.
+		if strings.HasSuffix(p.Key, "/") || len(p.Value) == 0 {
+			continue
+		}
+		docs = append(docs, Document{Name: p.Key, Data: p.Value})
+	}
+	return docs, index, nil
+}
@@ -0,0 +1,109 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Etcd reads documents through the etcd v3 JSON gateway. The gateway has no
+// blocking reads, so watching polls every PollInterval.
+type Etcd struct {
+	Addr         string
+	Client       *http.Client
+	PollInterval time.Duration
+}
+
+type etcdRangeResponse struct {
+	Header struct {
+		Revision string `json:"revision"`
+	} `json:"header"`
+	Kvs []struct {
+		Key   []byte `json:"key"`
+		Value []byte `json:"value"`
+	} `json:"kvs"`
+}
+
+func NewEtcd(addr string) *Etcd {
+	return &Etcd{
+		Addr:         strings.TrimRight(addr, "/"),
+		Client:       &http.Client{Timeout: 30 * time.Second},
+		PollInterval: 5 * time.Second,
+	}
+}
+
+func (e *Etcd) Fetch(ctx context.Context, prefix string, waitIndex uint64) ([]Document, uint64, error) {
+	for {
+		docs, rev, err := e.rangePrefix(ctx, prefix)
+		if err != nil || waitIndex == 0 || rev > waitIndex {
+			return docs, rev, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		case <-time.After(e.PollInterval):
+		}
+	}
+}
+
+func (e *Etcd) rangePrefix(ctx context.Context, prefix string) ([]Document, uint64, error) {
+	key := []byte(prefix)
+	end := prefixRangeEnd(key)
+	if len(key) == 0 {
+		key = []byte{0}
+	}
+	body, err := json.Marshal(map[string][]byte{"key": key, "range_end": end})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Addr+"/v3/kv/range", bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("etcd: unexpected status %d", resp.StatusCode)
+	}
+
+	var out etcdRangeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, 0, fmt.Errorf("etcd: %w", err)
+	}
+	rev, _ := strconv.ParseUint(out.Header.Revision, 10, 64)
+
+	docs := make([]Document, 0, len(out.Kvs))
+	for _, kv := range out.Kvs {
+		if len(kv.Value) == 0 {
+			continue
+		}
+		docs = append(docs, Document{Name: string(kv.Key), Data: kv.Value})
+	}
+	return docs, rev, nil
+}
+
+// prefixRangeEnd returns the smallest key greater than every key starting
+// with prefix, matching clientv3.GetPrefixRangeEnd.
+func prefixRangeEnd(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return []byte{0}
+}
@@ -0,0 +1,65 @@
+// Package source reads config documents from external key/value stores so
+// they can be linted like files on disk.
+package source
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Document is a single config value read from a store. Name is the key path
+// and is reported in place of a file name.
+type Document struct {
+	Name string
+	Data []byte
+}
+
+// KV is a key/value store that can list every document under a prefix.
+type KV interface {
+	// Fetch returns the documents under prefix along with the store's
+	// modification index. When waitIndex is non-zero the call blocks until
+	// the index moves past it or ctx is done.
+	Fetch(ctx context.Context, prefix string, waitIndex uint64) ([]Document, uint64, error)
+}
+
+// watchInterval is the least time between the starts of two fetches, so a
+// store that answers blocking queries at once cannot spin Watch.
+var watchInterval = time.Second
+
+// Watch calls fn with the current documents under prefix and again every
+// time they change, until ctx is cancelled or fn returns an error.
+func Watch(ctx context.Context, kv KV, prefix string, fn func([]Document) error) error {
+	var index uint64
+	first := true
+	for {
+		started := time.Now()
+		docs, next, err := kv.Fetch(ctx, prefix, index)
+		if err != nil {
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return nil
+			}
+			return err
+		}
+		// A missing or zero index would turn the next blocking query into
+		// a plain read; Consul asks clients to wait on 1 instead.
+		if next == 0 {
+			next = 1
+		}
+		if !first {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Until(started.Add(watchInterval))):
+			}
+		}
+		if !first && next == index {
+			continue
+		}
+		first = false
+		index = next
+		if err := fn(docs); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConsulFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/kv/apps/" || r.URL.Query().Get("recurse") != "true" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		if r.Header.Get("X-Consul-Token") != "tok" {
+			t.Errorf("missing consul token")
+		}
+		w.Header().Set("X-Consul-Index", "42")
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"Key": "apps/", "Value": nil},
+			{"Key": "apps/payments", "Value": []byte("metadata:\n  name: payments\n")},
+		})
+	}))
+	defer srv.Close()
+
+	docs, index, err := NewConsul(srv.URL, "tok").Fetch(context.Background(), "apps/", 0)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if index != 42 {
+		t.Errorf("expected index 42, got %d", index)
+	}
+	if len(docs) != 1 || docs[0].Name != "apps/payments" {
+		t.Fatalf("unexpected documents: %+v", docs)
+	}
+}
+
+func TestEtcdFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string][]byte
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("bad request body: %v", err)
+		}
+		if string(req["key"]) != "apps/" || string(req["range_end"]) != "apps0" {
+			t.Errorf("unexpected range %q..%q", req["key"], req["range_end"])
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"header": map[string]string{"revision": "7"},
+			"kvs": []map[string][]byte{
+				{"key": []byte("apps/payments"), "value": []byte("metadata:\n  name: payments\n")},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	docs, rev, err := NewEtcd(srv.URL).Fetch(context.Background(), "apps/", 0)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if rev != 7 {
+		t.Errorf("expected revision 7, got %d", rev)
+	}
+	if len(docs) != 1 || docs[0].Name != "apps/payments" {
+		t.Fatalf("unexpected documents: %+v", docs)
+	}
+}
+
+// indexlessKV answers every fetch at once without an index, like a Consul
+// server behind a proxy that strips X-Consul-Index.
+type indexlessKV struct {
+	waits  []uint64
+	cancel context.CancelFunc
+}
+
+func (kv *indexlessKV) Fetch(ctx context.Context, prefix string, waitIndex uint64) ([]Document, uint64, error) {
+	kv.waits = append(kv.waits, waitIndex)
+	if len(kv.waits) == 3 {
+		kv.cancel()
+	}
+	return nil, 0, nil
+}
+
+func TestWatchWithoutIndex(t *testing.T) {
+	saved := watchInterval
+	defer func() { watchInterval = saved }()
+	watchInterval = 20 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	kv := &indexlessKV{cancel: cancel}
+	calls := 0
+	start := time.Now()
+	err := Watch(ctx, kv, "apps/", func([]Document) error {
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := []uint64{0, 1, 1}; !reflect.DeepEqual(kv.waits, want) {
+		t.Errorf("expected wait indexes %v, got %v", want, kv.waits)
+	}
+	if calls != 1 {
+		t.Errorf("expected fn called once for unchanged documents, got %d", calls)
+	}
+	if elapsed := time.Since(start); elapsed < watchInterval {
+		t.Errorf("expected fetches spaced by %s, finished in %s", watchInterval, elapsed)
+	}
+}
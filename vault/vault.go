@@ -0,0 +1,69 @@
+// Package vault performs read-only existence checks against a HashiCorp
+// Vault server.
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type Client struct {
+	Addr   string
+	Token  string
+	Client *http.Client
+}
+
+func NewClient(addr, token string) *Client {
+	return &Client{
+		Addr:   strings.TrimRight(addr, "/"),
+		Token:  token,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SecretExists reads path and reports whether it holds key. Both KV v1 and
+// KV v2 (data nested under "data") response shapes are understood.
+func (c *Client) SecretExists(ctx context.Context, path, key string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.Addr+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("X-Vault-Token", c.Token)
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("vault: unexpected status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return false, fmt.Errorf("vault: %w", err)
+	}
+
+	data := body.Data
+	if nested, ok := data["data"]; ok {
+		var v2 map[string]json.RawMessage
+		if err := json.Unmarshal(nested, &v2); err == nil {
+			if _, ok := data["metadata"]; ok {
+				data = v2
+			}
+		}
+	}
+	_, ok := data[key]
+	return ok, nil
+}
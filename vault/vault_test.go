@@ -0,0 +1,46 @@
+package vault
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSecretExists(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "tok" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/app":
+			w.Write([]byte(`{"data":{"data":{"password":"x"},"metadata":{"version":1}}}`))
+		case "/v1/kv/app":
+			w.Write([]byte(`{"data":{"password":"x"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "tok")
+	cases := []struct {
+		path, key string
+		want      bool
+	}{
+		{"secret/data/app", "password", true},
+		{"secret/data/app", "missing", false},
+		{"kv/app", "password", true},
+		{"secret/data/other", "password", false},
+	}
+	for _, c := range cases {
+		got, err := client.SecretExists(context.Background(), c.path, c.key)
+		if err != nil {
+			t.Fatalf("%s#%s: unexpected error %v", c.path, c.key, err)
+		}
+		if got != c.want {
+			t.Errorf("%s#%s: expected %v, got %v", c.path, c.key, c.want, got)
+		}
+	}
+}
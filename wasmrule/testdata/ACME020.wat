@@ -0,0 +1,14 @@
+;; ACME020.wasm is built from this module: it reports configs whose
+;; settings lack an owner, on the line of the settings section.
+(module
+  (import "configlint" "field" (func $field (param i32 i32 i32 i32 i32 i32) (result i32)))
+  (import "configlint" "line" (func $line (param i32 i32 i32 i32) (result i32)))
+  (import "configlint" "emit" (func $emit (param i32 i32 i32 i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 0) "settings")
+  (data (i32.const 8) "owner")
+  (data (i32.const 16) "settings.owner is required")
+  (func (export "check")
+    (if (i32.lt_s (call $field (i32.const 0) (i32.const 8) (i32.const 8) (i32.const 5) (i32.const 64) (i32.const 64)) (i32.const 0))
+      (then
+        (call $emit (i32.const 0) (call $line (i32.const 0) (i32.const 8) (i32.const 0) (i32.const 0)) (i32.const 16) (i32.const 26))))))
@@ -0,0 +1,390 @@
+// Package wasmrule runs rules compiled to WebAssembly. Unlike Go plugins
+// they load on every platform and run sandboxed: a rule sees only the
+// config, through the host functions below, with no files, network,
+// environment or clock beyond what WASI stubs out, a memory cap and a time
+// limit per check. That makes them safe to load in the hosted server.
+//
+// A rule is a .wasm file named after its rule ID (ACME020.wasm). It
+// exports its memory and a check function taking no arguments, and may
+// import these functions from the "configlint" module; strings are passed
+// as pointer and length into the rule's memory:
+//
+//	field(section, sectionLen, key, keyLen, buf, bufCap i32) i32
+//	    Copies the value of section.key into buf, up to bufCap bytes, and
+//	    returns its length, or -1 when the key is not set.
+//	line(section, sectionLen, key, keyLen i32) i32
+//	    Returns the line of section.key, or of the section when keyLen is
+//	    0; 0 when it is not set.
+//	count(section, sectionLen i32) i32
+//	    Returns the number of keys in the section, or of entries for
+//	    "features"; -1 when it is not set.
+//	emit(severity, line, message, messageLen i32)
+//	    Reports an issue. Severity 1 is error, 2 warn, 3 info, 4 hint and
+//	    0 the rule's default, warn. A check that reports more than 1000
+//	    issues is stopped.
+//
+// Sections are "metadata", "settings", "features.N" for the Nth feature
+// entry (from 0), any other top-level mapping by name, and "" for scalar
+// keys at the document root. Rules built for WASI, such as Go programs
+// built with GOOS=wasip1 and -buildmode=c-shared, may also import
+// wasi_snapshot_preview1.
+package wasmrule
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+
+	"cli-config-linter/linter"
+)
+
+const (
+	// checkTimeout bounds one rule's check of one config.
+	checkTimeout = 2 * time.Second
+	// memoryLimitPages caps a rule's memory at 64 MiB.
+	memoryLimitPages = 1024
+	// maxIssues bounds what one rule reports for one config, so a rule
+	// emitting in a loop cannot exhaust the host's memory.
+	maxIssues = 1000
+)
+
+// errTooManyIssues stops a check that emits more than maxIssues issues.
+var errTooManyIssues = fmt.Errorf("reported more than %d issues", maxIssues)
+
+var host struct {
+	once    sync.Once
+	runtime wazero.Runtime
+	err     error
+	mu      sync.Mutex
+	// loaded maps rule IDs to the file each was loaded from.
+	loaded map[string]loadedFile
+}
+
+// loadedFile is the path and content sha256 of a loaded rule.
+type loadedFile struct {
+	path, sum string
+}
+
+// hostRuntime is the process's WebAssembly runtime, with the configlint
+// host functions and WASI instantiated.
+func hostRuntime() (wazero.Runtime, error) {
+	host.once.Do(func() {
+		ctx := context.Background()
+		rt := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+			WithMemoryLimitPages(memoryLimitPages).
+			WithCloseOnContextDone(true))
+		if _, err := wasi_snapshot_preview1.Instantiate(ctx, rt); err != nil {
+			host.err = err
+			return
+		}
+		_, host.err = rt.NewHostModuleBuilder("configlint").
+			NewFunctionBuilder().WithFunc(hostField).Export("field").
+			NewFunctionBuilder().WithFunc(hostLine).Export("line").
+			NewFunctionBuilder().WithFunc(hostCount).Export("count").
+			NewFunctionBuilder().WithFunc(hostEmit).Export("emit").
+			Instantiate(ctx)
+		host.runtime = rt
+		host.loaded = make(map[string]loadedFile)
+	})
+	return host.runtime, host.err
+}
+
+// Load compiles the *.wasm files in dir in name order and registers each
+// as a rule with linter.Register, returning their paths. pins maps file
+// names to the sha256 the file must have ("sha256:" is optional); with
+// requirePinned a file without a pin is refused. A rule loaded before from
+// the same, unchanged file is kept, so reloading settings is harmless; a
+// registered rule cannot be replaced, so a reload that finds one changed or
+// removed fails until the process restarts.
+func Load(dir string, pins map[string]string, requirePinned bool) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.wasm"))
+	if err != nil {
+		return nil, err
+	}
+	rt, err := hostRuntime()
+	if err != nil {
+		return nil, err
+	}
+	host.mu.Lock()
+	defer host.mu.Unlock()
+	for _, prev := range host.loaded {
+		if filepath.Dir(prev.path) == filepath.Clean(dir) && !slices.Contains(paths, prev.path) {
+			return nil, fmt.Errorf("rule plugin %s: removed since it was loaded; restart to unload it", prev.path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	var rules []*rule
+	var sums []string
+	for _, path := range paths {
+		id := strings.TrimSuffix(filepath.Base(path), ".wasm")
+		data, err := readPinned(path, pins[filepath.Base(path)], requirePinned)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+		if prev, ok := host.loaded[id]; ok {
+			if prev.path != path {
+				return nil, fmt.Errorf("rule plugin %s: rule %s is already loaded from %s", path, id, prev.path)
+			}
+			if prev.sum != hex.EncodeToString(sum[:]) {
+				return nil, fmt.Errorf("rule plugin %s: changed since it was loaded; restart to load the new version", path)
+			}
+			continue
+		}
+		for _, r := range linter.RegisteredRules() {
+			if r.ID() == id {
+				return nil, fmt.Errorf("rule plugin %s: rule %s is already registered", path, id)
+			}
+		}
+		compiled, err := rt.CompileModule(context.Background(), data)
+		if err != nil {
+			return nil, fmt.Errorf("rule plugin %s: %w", path, err)
+		}
+		if _, ok := compiled.ExportedFunctions()["check"]; !ok {
+			return nil, fmt.Errorf("rule plugin %s: does not export a check function", path)
+		}
+		rules = append(rules, &rule{id: id, path: path, compiled: compiled, idle: make(chan api.Module, runtime.GOMAXPROCS(0))})
+		sums = append(sums, hex.EncodeToString(sum[:]))
+	}
+	for i, r := range rules {
+		linter.Register(r)
+		host.loaded[r.id] = loadedFile{r.path, sums[i]}
+	}
+	return paths, nil
+}
+
+func readPinned(path, pin string, requirePinned bool) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("rule plugin %s: %w", path, err)
+	}
+	want := strings.ToLower(strings.TrimPrefix(pin, "sha256:"))
+	if want == "" {
+		if requirePinned {
+			return nil, fmt.Errorf("rule plugin %s: not pinned with sha256, which the rule pack policy requires", path)
+		}
+		return data, nil
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return nil, fmt.Errorf("rule plugin %s: checksum mismatch: got sha256:%s, want sha256:%s", path, got, want)
+	}
+	return data, nil
+}
+
+// rule is one loaded .wasm rule. Instances are reused between checks, at
+// most one check at a time each.
+type rule struct {
+	id       string
+	path     string
+	compiled wazero.CompiledModule
+	idle     chan api.Module
+}
+
+func (r *rule) ID() string { return r.id }
+
+func (r *rule) Severity() linter.Severity { return linter.SeverityWarning }
+
+type callKey struct{}
+
+// call is the state of one check, reached by the host functions through
+// the context.
+type call struct {
+	cfg    *linter.Config
+	issues []linter.Issue
+	// stopped is set when the check was stopped for emitting too much.
+	stopped bool
+}
+
+func (r *rule) Check(cfg *linter.Config) []linter.Issue {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+	c := &call{cfg: cfg}
+	ctx = context.WithValue(ctx, callKey{}, c)
+
+	mod, err := r.instance(ctx)
+	if err == nil {
+		if _, err = mod.ExportedFunction("check").Call(ctx); err == nil {
+			r.release(mod)
+		} else {
+			// A trapped or timed-out instance is not reused.
+			mod.Close(context.Background())
+		}
+	}
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("did not finish within %s", checkTimeout)
+		}
+		if c.stopped {
+			err = errTooManyIssues
+		}
+		failed := linter.Issue{Line: 1, Severity: linter.SeverityError, Message: fmt.Sprintf("rule plugin %s failed: %v", filepath.Base(r.path), err)}
+		if c.stopped {
+			// Keep what the rule reported before it was stopped.
+			return append(c.issues, failed)
+		}
+		return []linter.Issue{failed}
+	}
+	return c.issues
+}
+
+func (r *rule) instance(ctx context.Context) (api.Module, error) {
+	select {
+	case mod := <-r.idle:
+		return mod, nil
+	default:
+	}
+	rt, err := hostRuntime()
+	if err != nil {
+		return nil, err
+	}
+	// Anonymous instances can coexist; WASI reactors are initialized once.
+	cfg := wazero.NewModuleConfig().WithName("").WithStartFunctions()
+	mod, err := rt.InstantiateModule(ctx, r.compiled, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if init := mod.ExportedFunction("_initialize"); init != nil {
+		if _, err := init.Call(ctx); err != nil {
+			mod.Close(context.Background())
+			return nil, err
+		}
+	}
+	return mod, nil
+}
+
+func (r *rule) release(mod api.Module) {
+	select {
+	case r.idle <- mod:
+	default:
+		mod.Close(context.Background())
+	}
+}
+
+func readString(m api.Module, ptr, n uint32) (string, bool) {
+	b, ok := m.Memory().Read(ptr, n)
+	return string(b), ok
+}
+
+// section returns the keys of the named section and its line.
+func section(cfg *linter.Config, name string) (map[string]linter.Field, int, bool) {
+	switch name {
+	case "":
+		return cfg.TopLevel, 1, cfg.TopLevel != nil
+	case "metadata", "settings":
+		s := cfg.Metadata
+		if name == "settings" {
+			s = cfg.Settings
+		}
+		if s == nil {
+			return nil, 0, false
+		}
+		return s.Fields, s.Line, true
+	}
+	if rest, ok := strings.CutPrefix(name, "features."); ok {
+		i, err := strconv.Atoi(rest)
+		if err != nil || i < 0 || i >= len(cfg.Features) {
+			return nil, 0, false
+		}
+		return cfg.Features[i].Fields, cfg.Features[i].Line, true
+	}
+	if s, ok := cfg.Sections[name]; ok {
+		return s.Fields, s.Line, true
+	}
+	return nil, 0, false
+}
+
+func currentCall(ctx context.Context) *call {
+	c, _ := ctx.Value(callKey{}).(*call)
+	return c
+}
+
+func hostField(ctx context.Context, m api.Module, sec, secLen, key, keyLen, buf, bufCap uint32) int32 {
+	c := currentCall(ctx)
+	name, ok1 := readString(m, sec, secLen)
+	k, ok2 := readString(m, key, keyLen)
+	if c == nil || !ok1 || !ok2 {
+		return -1
+	}
+	fields, _, _ := section(c.cfg, name)
+	f, ok := fields[k]
+	if !ok {
+		return -1
+	}
+	value := []byte(f.Value)
+	m.Memory().Write(buf, value[:min(len(value), int(bufCap))])
+	return int32(len(value))
+}
+
+func hostLine(ctx context.Context, m api.Module, sec, secLen, key, keyLen uint32) int32 {
+	c := currentCall(ctx)
+	name, ok1 := readString(m, sec, secLen)
+	k, ok2 := readString(m, key, keyLen)
+	if c == nil || !ok1 || !ok2 {
+		return 0
+	}
+	fields, line, ok := section(c.cfg, name)
+	if !ok {
+		return 0
+	}
+	if k == "" {
+		return int32(line)
+	}
+	return int32(fields[k].Line)
+}
+
+func hostCount(ctx context.Context, m api.Module, sec, secLen uint32) int32 {
+	c := currentCall(ctx)
+	name, ok := readString(m, sec, secLen)
+	if c == nil || !ok {
+		return -1
+	}
+	if name == "features" {
+		if c.cfg.Features == nil {
+			return -1
+		}
+		return int32(len(c.cfg.Features))
+	}
+	fields, _, found := section(c.cfg, name)
+	if !found {
+		return -1
+	}
+	return int32(len(fields))
+}
+
+var severities = []linter.Severity{"", linter.SeverityError, linter.SeverityWarning, linter.SeverityInfo, linter.SeverityHint}
+
+func hostEmit(ctx context.Context, m api.Module, severity, line, msg, msgLen uint32) {
+	c := currentCall(ctx)
+	message, ok := readString(m, msg, msgLen)
+	if c == nil || !ok {
+		return
+	}
+	if len(c.issues) == maxIssues {
+		// The panic unwinds the guest; wazero returns it from check.
+		c.stopped = true
+		panic(errTooManyIssues)
+	}
+	issue := linter.Issue{Line: max(int(int32(line)), 1), Message: message}
+	if int(severity) < len(severities) {
+		issue.Severity = severities[severity]
+	}
+	c.issues = append(c.issues, issue)
+}
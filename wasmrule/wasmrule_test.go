@@ -0,0 +1,130 @@
+package wasmrule
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cli-config-linter/linter"
+)
+
+func TestLoadRunsWasmRules(t *testing.T) {
+	paths, err := Load("testdata", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 {
+		t.Fatalf("expected ACME020.wasm loaded, got %v", paths)
+	}
+	// Loading the same files again, as a settings reload does, is harmless.
+	if _, err := Load("testdata", nil, false); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := linter.LintBytes([]byte("metadata:\n  name: a\n  env: dev\nsettings:\n  replicas: 1\n  timeout: 5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := linter.Issue{Line: 4, Severity: linter.SeverityWarning, RuleID: "ACME020", Message: "settings.owner is required", Path: "settings"}
+	if len(issues) != 1 || issues[0] != want {
+		t.Errorf("expected %+v, got %+v", want, issues)
+	}
+	issues, err = linter.LintBytes([]byte("metadata:\n  name: a\n  env: dev\nsettings:\n  replicas: 1\n  timeout: 5\n  owner: core\n"))
+	if err != nil || len(issues) != 0 {
+		t.Errorf("expected no issues once settings.owner is set, got %+v %v", issues, err)
+	}
+}
+
+func TestReloadRefusesChangedRules(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "ACME020.wasm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ACME023.wasm")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	// A custom section named "x" changes the file but not what it does.
+	if err := os.WriteFile(path, append(data, 0x00, 0x02, 0x01, 'x'), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir, nil, false); err == nil || !strings.Contains(err.Error(), "changed since it was loaded") {
+		t.Errorf("expected a changed rule to fail the reload, got %v", err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir, nil, false); err == nil || !strings.Contains(err.Error(), "removed since it was loaded") {
+		t.Errorf("expected a removed rule to fail the reload, got %v", err)
+	}
+}
+
+func TestLoadChecksPins(t *testing.T) {
+	if _, err := Load("testdata", nil, true); err == nil || !strings.Contains(err.Error(), "not pinned") {
+		t.Errorf("expected an unpinned rule to be refused, got %v", err)
+	}
+	pins := map[string]string{"ACME020.wasm": "sha256:" + strings.Repeat("0", 64)}
+	if _, err := Load("testdata", pins, false); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected a pin mismatch, got %v", err)
+	}
+}
+
+func TestFailingRuleIsReported(t *testing.T) {
+	// A module whose check function traps with unreachable.
+	trap := []byte{
+		0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+		0x01, 0x04, 0x01, 0x60, 0x00, 0x00, // type () -> ()
+		0x03, 0x02, 0x01, 0x00, // one function of that type
+		0x07, 0x09, 0x01, 0x05, 'c', 'h', 'e', 'c', 'k', 0x00, 0x00, // export "check"
+		0x0a, 0x05, 0x01, 0x03, 0x00, 0x00, 0x0b, // body: unreachable
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ACME021.wasm"), trap, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	issues, err := linter.LintWithOptions([]byte("metadata:\n  name: a\n  env: dev\nsettings:\n  replicas: 1\n  timeout: 5\n  owner: core\n"), linter.Options{EnabledRules: []string{"ACME021"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].RuleID != "ACME021" || issues[0].Severity != linter.SeverityError || !strings.Contains(issues[0].Message, "ACME021.wasm failed") {
+		t.Errorf("expected the trap reported as an error, got %+v", issues)
+	}
+}
+
+func TestFloodingRuleIsStopped(t *testing.T) {
+	// A module whose check function calls emit(0, 1, 0, 1) forever.
+	flood := []byte{
+		0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+		0x01, 0x0b, 0x02, 0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x00, 0x60, 0x00, 0x00, // types (i32 x4) -> () and () -> ()
+		0x02, 0x13, 0x01, 0x0a, 'c', 'o', 'n', 'f', 'i', 'g', 'l', 'i', 'n', 't', 0x04, 'e', 'm', 'i', 't', 0x00, 0x00, // import configlint.emit
+		0x03, 0x02, 0x01, 0x01, // one function of type () -> ()
+		0x05, 0x03, 0x01, 0x00, 0x01, // one page of memory
+		0x07, 0x12, 0x02, 0x05, 'c', 'h', 'e', 'c', 'k', 0x00, 0x01, 0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00, // export check and memory
+		0x0a, 0x13, 0x01, 0x11, 0x00, 0x03, 0x40, 0x41, 0x00, 0x41, 0x01, 0x41, 0x00, 0x41, 0x01, 0x10, 0x00, 0x0c, 0x00, 0x0b, 0x0b, // body: loop { emit; br 0 }
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ACME022.wasm"), flood, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	issues, err := linter.LintWithOptions([]byte("metadata:\n  name: a\n  env: dev\nsettings:\n  replicas: 1\n  timeout: 5\n  owner: core\n"), linter.Options{EnabledRules: []string{"ACME022"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != maxIssues+1 {
+		t.Fatalf("expected %d issues and the failure, got %d", maxIssues, len(issues))
+	}
+	if last := issues[maxIssues]; last.Severity != linter.SeverityError || !strings.Contains(last.Message, "reported more than 1000 issues") {
+		t.Errorf("expected the rule reported as stopped, got %+v", last)
+	}
+}